@@ -0,0 +1,213 @@
+package catalog
+
+import (
+	"bytes"
+	"cine-pulse/storage"
+	"strings"
+	"testing"
+)
+
+func catalogStore(t *testing.T) *storage.MemoryStorage {
+	store := storage.NewMemoryStorage()
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	sourceURL := "https://nkiri.com/"
+	_, err := store.SaveContents([]storage.Content{
+		{Title: "Dune: Part Two", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{8.6}[0], SourceURL: &sourceURL},
+		{Title: "=HYPERLINK(\"x\")", Category: "Hollywood", Type: "movie", ExtraInfo: "line one\nline | two"},
+		{Title: "The Boys", Category: "TV Series", Type: "series", ExtraInfo: "Season 4 Episode 5 Added"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	return store
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			count, err := Export(catalogStore(t), &buf, format, storage.ContentFilter{SortBy: storage.SortTitle})
+			if err != nil {
+				t.Fatalf("Failed to export: %v", err)
+			}
+			if count != 3 {
+				t.Fatalf("Expected 3 exported items, got %d", count)
+			}
+
+			target := storage.NewMemoryStorage()
+			report, err := Import(target, &buf, format, ImportOptions{})
+			if err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+			if report.Read != 3 || report.Inserted != 3 || len(report.Rejected) != 0 {
+				t.Fatalf("Unexpected report: %+v", report)
+			}
+
+			contents, err := target.SearchContent("HYPERLINK")
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(contents) != 1 || contents[0].Title != `=HYPERLINK("x")` || contents[0].ExtraInfo != "line one line | two" {
+				t.Errorf("Expected the formula-like title and normalized extra info, got %+v", contents)
+			}
+
+			dune, err := target.SearchContent("Dune")
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(dune) != 1 || *dune[0].Year != 2024 || *dune[0].Rating != 8.6 || *dune[0].SourceURL != "https://nkiri.com/" {
+				t.Errorf("Unexpected imported item: %+v", dune)
+			}
+		})
+	}
+}
+
+func TestExportCSVIsExcelFriendly(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Export(catalogStore(t), &buf, FormatCSV, storage.ContentFilter{SortBy: storage.SortTitle}); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	csv := buf.String()
+
+	if !strings.HasPrefix(csv, utf8BOM+"id,title,year,") {
+		t.Errorf("Expected a BOM and header, got %q", csv[:20])
+	}
+	if !strings.Contains(csv, "\r\n") {
+		t.Error("Expected CRLF line endings")
+	}
+	if !strings.Contains(csv, `"'=HYPERLINK(""x"")"`) {
+		t.Errorf("Expected the formula to be quoted, got %s", csv)
+	}
+}
+
+func TestCSVRoundTripKeepsQuotes(t *testing.T) {
+	titles := []string{"'-1", "'=Sum", "''Quoted''", "-1", "'Til Death", "'"}
+	store := storage.NewMemoryStorage()
+	for _, title := range titles {
+		if _, err := store.SaveContents([]storage.Content{{Title: title, Category: "Hollywood", Type: "movie"}}); err != nil {
+			t.Fatalf("Failed to save content: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := Export(store, &buf, FormatCSV, storage.ContentFilter{}); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	records, _, err := readCSV(&buf)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	imported := map[string]bool{}
+	for _, record := range records {
+		imported[record.content.Title] = true
+	}
+	for _, title := range titles {
+		if !imported[title] {
+			t.Errorf("Expected %q to survive the round trip, got %v", title, imported)
+		}
+	}
+
+	// Files not written by Export are read as they are
+	records, _, err = readCSV(strings.NewReader("title,type,category\n'-1,movie,Hollywood\n"))
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 1 || records[0].content.Title != "'-1" {
+		t.Errorf("Expected the title to be kept, got %+v", records)
+	}
+}
+
+func TestExportMarkdownWithFilter(t *testing.T) {
+	var buf bytes.Buffer
+	count, err := Export(catalogStore(t), &buf, FormatMarkdown, storage.ContentFilter{Type: "movie", SortBy: storage.SortTitle, Limit: 1})
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if count != 1 || len(lines) != 3 {
+		t.Fatalf("Expected a header, separator and one row, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "| id | title |") || !strings.Contains(lines[2], `line one line \| two`) {
+		t.Errorf("Unexpected table: %q", buf.String())
+	}
+}
+
+func TestImportConflictsAndRejections(t *testing.T) {
+	input := `title,type,category,year,extra_info
+The Boys,series,TV Series,,Season 4 Episode 6 Added
+Civil War,movie,Hollywood,2024,
+Civil War,Movie,Hollywood,2024,
+Nameless,movie,Hollywood,not a year,
+Old Film,movie,Westerns,1950,
+`
+
+	store := catalogStore(t)
+	report, err := Import(store, strings.NewReader(input), FormatCSV, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if report.Read != 5 || report.Inserted != 1 || report.Updated != 1 || len(report.Conflicts) != 1 || len(report.Rejected) != 3 {
+		t.Fatalf("Unexpected dry run report: %+v", report)
+	}
+	conflict := report.Conflicts[0]
+	if conflict.Line != 2 || conflict.Content.Title != "The Boys" || len(conflict.Changes) != 1 || conflict.Changes[0].Field != "extra_info" {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+	reasons := map[int]string{}
+	for _, rejection := range report.Rejected {
+		reasons[rejection.Line] = rejection.Reason
+	}
+	if reasons[4] != "duplicate of line 3" || reasons[5] != `invalid year "not a year"` || !strings.Contains(reasons[6], "unknown category") {
+		t.Errorf("Unexpected rejections: %v", reasons)
+	}
+	if stats, _ := store.GetStats(); stats["total"] != 3 {
+		t.Errorf("Expected a dry run to save nothing, got %v", stats)
+	}
+
+	// Skipping conflicts keeps the stored series as it is
+	report, err = Import(store, strings.NewReader(input), FormatCSV, ImportOptions{SkipConflicts: true})
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if report.Inserted != 1 || report.Updated != 0 || report.Skipped != 1 || !report.Conflicts[0].Skipped {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	boys, _ := store.SearchContent("The Boys")
+	if len(boys) != 1 || boys[0].ExtraInfo != "Season 4 Episode 5 Added" {
+		t.Errorf("Expected the stored series to be kept, got %+v", boys)
+	}
+
+	// Without skipping, the import updates it
+	report, err = Import(store, strings.NewReader(input), FormatCSV, ImportOptions{})
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if report.Updated != 1 || report.Unchanged != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestImportRequiresColumns(t *testing.T) {
+	_, err := Import(storage.NewMemoryStorage(), strings.NewReader("name,kind\nx,y\n"), FormatCSV, ImportOptions{})
+	if err == nil {
+		t.Error("Expected error for a CSV without title and type columns")
+	}
+	if _, err := Import(storage.NewMemoryStorage(), strings.NewReader(""), FormatMarkdown, ImportOptions{}); err == nil {
+		t.Error("Expected error importing Markdown")
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	for path, expected := range map[string]Format{"out.csv": FormatCSV, "out.JSONL": FormatJSONL, "x.ndjson": FormatJSONL, "t.md": FormatMarkdown} {
+		if format, err := FormatFromPath(path); err != nil || format != expected {
+			t.Errorf("Expected %s for %s, got %s, %v", expected, path, format, err)
+		}
+	}
+	if _, err := FormatFromPath("out.xlsx"); err == nil {
+		t.Error("Expected error for an unknown extension")
+	}
+}
@@ -0,0 +1,231 @@
+package catalog
+
+import (
+	"cine-pulse/storage"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a catalog file format
+type Format string
+
+const (
+	FormatCSV      Format = "csv"
+	FormatJSONL    Format = "jsonl"
+	FormatMarkdown Format = "md"
+)
+
+// exportPageSize is the number of items read from storage at a time
+const exportPageSize = 500
+
+// columns are the fields of CSV and Markdown exports, in order
+var columns = []string{"id", "title", "year", "type", "category", "rating", "extra_info", "source_url", "scraped_at", "updated_at"}
+
+// utf8BOM makes Excel read CSV files as UTF-8
+const utf8BOM = "\ufeff"
+
+// ParseFormat accepts a format name such as "csv", "jsonl" or "markdown"
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson":
+		return FormatJSONL, nil
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use csv, jsonl or md)", name)
+}
+
+// FormatFromPath returns the format matching a file's extension
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+// Export writes the content matching the filter to w in the filter's sort
+// order and returns the number of items written. The filter's Limit caps the
+// number of items; 0 exports everything.
+func Export(store storage.StorageInterface, w io.Writer, format Format, filter storage.ContentFilter) (int, error) {
+	out, err := newExportWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	max := filter.Limit
+	filter.Limit = exportPageSize
+	filter.Cursor = ""
+
+	count := 0
+	for {
+		page, err := store.QueryContent(filter)
+		if err != nil {
+			return count, err
+		}
+		for _, content := range page.Items {
+			if max > 0 && count == max {
+				return count, out.Close()
+			}
+			if err := out.Write(content); err != nil {
+				return count, fmt.Errorf("failed to write %s: %v", content.Title, err)
+			}
+			count++
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	return count, out.Close()
+}
+
+// exportWriter writes content items in one format
+type exportWriter interface {
+	Write(content storage.Content) error
+	Close() error
+}
+
+func newExportWriter(w io.Writer, format Format) (exportWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatJSONL:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return &jsonlWriter{encoder: encoder}, nil
+	case FormatMarkdown:
+		return newMarkdownWriter(w)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// csvWriter writes a header row and one row per item with CRLF line endings,
+// as Excel expects
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (c *csvWriter) Write(content storage.Content) error {
+	row := contentRow(content)
+	for i, cell := range row {
+		row[i] = guardCell(cell)
+	}
+	return c.writer.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// guardCell stops spreadsheets from running text that starts like a formula
+// by prefixing it with a quote; import removes the prefix again. Text that
+// already starts with a quote gets one too, so "'-1" survives the round trip.
+func guardCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r'", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// unguardCell reverses guardCell. Only files written by Export are guarded.
+func unguardCell(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune("=+-@\t\r'", rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
+
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonlWriter) Write(content storage.Content) error {
+	return j.encoder.Encode(content)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
+
+// markdownWriter writes a table with one row per item
+type markdownWriter struct {
+	w io.Writer
+}
+
+func newMarkdownWriter(w io.Writer) (*markdownWriter, error) {
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	if _, err := fmt.Fprintf(w, "| %s |\n|%s|\n", strings.Join(columns, " | "), strings.Join(separators, "|")); err != nil {
+		return nil, err
+	}
+	return &markdownWriter{w: w}, nil
+}
+
+func (m *markdownWriter) Write(content storage.Content) error {
+	row := contentRow(content)
+	escape := strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+	for i, cell := range row {
+		row[i] = escape.Replace(cell)
+	}
+	_, err := fmt.Fprintf(m.w, "| %s |\n", strings.Join(row, " | "))
+	return err
+}
+
+func (m *markdownWriter) Close() error {
+	return nil
+}
+
+// contentRow formats an item's fields in the order of columns
+func contentRow(content storage.Content) []string {
+	year, rating, sourceURL := "", "", ""
+	if content.Year != nil {
+		year = strconv.Itoa(*content.Year)
+	}
+	if content.Rating != nil {
+		rating = strconv.FormatFloat(*content.Rating, 'f', -1, 64)
+	}
+	if content.SourceURL != nil {
+		sourceURL = *content.SourceURL
+	}
+
+	return []string{
+		strconv.FormatInt(content.ID, 10),
+		content.Title,
+		year,
+		content.Type,
+		content.Category,
+		rating,
+		content.ExtraInfo,
+		sourceURL,
+		formatTime(content.ScrapedAt),
+		formatTime(content.UpdatedAt),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,280 @@
+package catalog
+
+import (
+	"bufio"
+	"cine-pulse/storage"
+	"cine-pulse/validation"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxJSONLine is the longest JSON Lines record Import reads
+const maxJSONLine = 1 << 20
+
+// ImportOptions control an import
+type ImportOptions struct {
+	// DryRun reports what the import would do without saving anything
+	DryRun bool
+	// SkipConflicts leaves stored items that differ from the file untouched
+	// instead of updating them
+	SkipConflicts bool
+}
+
+// Rejection is a record that could not be imported
+type Rejection struct {
+	Line   int    `json:"line"`
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Conflict is a record whose stored item has different values.
+// Skipped is set when the stored item was kept.
+type Conflict struct {
+	Line    int                     `json:"line"`
+	Content storage.Content         `json:"content"`
+	Changes []storage.ContentChange `json:"changes"`
+	Skipped bool                    `json:"skipped"`
+}
+
+// ImportReport summarizes an import. With DryRun the counts are what the
+// import would have done.
+type ImportReport struct {
+	DryRun    bool        `json:"dry_run"`
+	Read      int         `json:"read"`
+	Inserted  int         `json:"inserted"`
+	Updated   int         `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Skipped   int         `json:"skipped"`
+	Rejected  []Rejection `json:"rejected,omitempty"`
+	Conflicts []Conflict  `json:"conflicts,omitempty"`
+}
+
+// record is an item read from an import file with its line number
+type record struct {
+	line    int
+	content storage.Content
+}
+
+// Import reads CSV or JSON Lines content, validates it like scraped content
+// and merges it through the storage upsert. Ids and timestamps in the file
+// are ignored; items are matched by title and type.
+func Import(store storage.StorageInterface, r io.Reader, format Format, options ImportOptions) (ImportReport, error) {
+	report := ImportReport{DryRun: options.DryRun}
+
+	var records []record
+	var err error
+	switch format {
+	case FormatCSV:
+		records, report.Rejected, err = readCSV(r)
+	case FormatJSONL:
+		records, report.Rejected, err = readJSONL(r)
+	default:
+		err = fmt.Errorf("cannot import %s files", format)
+	}
+	if err != nil {
+		return report, err
+	}
+	report.Read = len(records) + len(report.Rejected)
+
+	// Normalize like scraped content and keep the first of any duplicates
+	validator := validation.NewValidator()
+	seen := make(map[string]int)
+	var valid []record
+	for _, rec := range records {
+		content, err := validator.Normalize(rec.content)
+		if err != nil {
+			report.Rejected = append(report.Rejected, Rejection{Line: rec.line, Title: rec.content.Title, Reason: err.Error()})
+			continue
+		}
+
+		key := strings.ToLower(content.Title) + "|" + content.Type
+		if first, ok := seen[key]; ok {
+			report.Rejected = append(report.Rejected, Rejection{Line: rec.line, Title: content.Title,
+				Reason: fmt.Sprintf("duplicate of line %d", first)})
+			continue
+		}
+		seen[key] = rec.line
+		valid = append(valid, record{line: rec.line, content: content})
+	}
+	if len(valid) == 0 {
+		return report, nil
+	}
+
+	// Find the stored items that differ from the file
+	contents := make([]storage.Content, len(valid))
+	for i, rec := range valid {
+		contents[i] = rec.content
+	}
+	preview, err := store.PreviewSaveContents(contents)
+	if err != nil {
+		return report, err
+	}
+
+	var save []storage.Content
+	for i, result := range preview {
+		conflict := result.Outcome != storage.OutcomeInserted && len(result.Changes) > 0
+		if conflict {
+			report.Conflicts = append(report.Conflicts, Conflict{Line: valid[i].line, Content: result.Content,
+				Changes: result.Changes, Skipped: options.SkipConflicts})
+		}
+		if conflict && options.SkipConflicts {
+			report.Skipped++
+			continue
+		}
+		save = append(save, contents[i])
+		if options.DryRun {
+			report.count(result.Outcome)
+		}
+	}
+	if options.DryRun || len(save) == 0 {
+		return report, nil
+	}
+
+	results, err := store.SaveContents(save)
+	if err != nil {
+		return report, err
+	}
+	for _, result := range results {
+		report.count(result.Outcome)
+	}
+	return report, nil
+}
+
+func (r *ImportReport) count(outcome storage.SaveOutcome) {
+	switch outcome {
+	case storage.OutcomeInserted:
+		r.Inserted++
+	case storage.OutcomeUpdated:
+		r.Updated++
+	default:
+		r.Unchanged++
+	}
+}
+
+// readCSV reads a file with a header row naming the columns, as written by
+// Export. Only title and type are required; unknown columns are ignored.
+// Formula guards are removed from files Export wrote.
+func readCSV(r io.Reader) ([]record, []Rejection, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	// Export starts its files with a BOM; only those have guarded cells
+	var guarded bool
+	index := make(map[string]int)
+	for i, name := range header {
+		if i == 0 && strings.HasPrefix(name, utf8BOM) {
+			name, guarded = strings.TrimPrefix(name, utf8BOM), true
+		}
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "type"} {
+		if _, ok := index[required]; !ok {
+			return nil, nil, fmt.Errorf("CSV header has no %s column", required)
+		}
+	}
+
+	var records []record
+	var rejected []Rejection
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(row) {
+				if guarded {
+					return strings.TrimSpace(unguardCell(row[i]))
+				}
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		content, err := contentFromFields(field)
+		if err != nil {
+			rejected = append(rejected, Rejection{Line: line, Title: field("title"), Reason: err.Error()})
+			continue
+		}
+		records = append(records, record{line: line, content: content})
+	}
+
+	return records, rejected, nil
+}
+
+// contentFromFields builds an item from named text fields
+func contentFromFields(field func(name string) string) (storage.Content, error) {
+	content := storage.Content{
+		Title:     field("title"),
+		Type:      field("type"),
+		Category:  field("category"),
+		ExtraInfo: field("extra_info"),
+	}
+
+	if value := field("year"); value != "" {
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return content, fmt.Errorf("invalid year %q", value)
+		}
+		content.Year = &year
+	}
+	if value := field("rating"); value != "" {
+		rating, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return content, fmt.Errorf("invalid rating %q", value)
+		}
+		content.Rating = &rating
+	}
+	if value := field("source_url"); value != "" {
+		content.SourceURL = &value
+	}
+
+	return content, nil
+}
+
+// readJSONL reads one JSON content object per line, as written by Export
+func readJSONL(r io.Reader) ([]record, []Rejection, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxJSONLine)
+
+	var records []record
+	var rejected []Rejection
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var content storage.Content
+		if err := json.Unmarshal([]byte(text), &content); err != nil {
+			rejected = append(rejected, Rejection{Line: line, Reason: fmt.Sprintf("invalid JSON: %v", err)})
+			continue
+		}
+		records = append(records, record{line: line, content: storage.Content{
+			Title:     content.Title,
+			Year:      content.Year,
+			Category:  content.Category,
+			ExtraInfo: content.ExtraInfo,
+			Type:      content.Type,
+			Rating:    content.Rating,
+			SourceURL: content.SourceURL,
+		}})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read JSON Lines: %v", err)
+	}
+
+	return records, rejected, nil
+}
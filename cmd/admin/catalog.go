@@ -0,0 +1,187 @@
+package main
+
+import (
+	"cine-pulse/catalog"
+	"cine-pulse/storage"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// exportContent writes the catalog, or the part matching the filter flags, to a file or stdout
+func exportContent(store storage.StorageInterface, args []string) error {
+	flags := flag.NewFlagSet("content export", flag.ContinueOnError)
+	output := flags.String("o", "", "Output file (default stdout)")
+	format := flags.String("format", "", "csv, jsonl or md (default from the file extension, else csv)")
+	category := flags.String("category", "", "Only this category")
+	contentType := flags.String("type", "", "Only this type (movie or series)")
+	minYear := flags.String("min-year", "", "Earliest release year")
+	maxYear := flags.String("max-year", "", "Latest release year")
+	minRating := flags.String("min-rating", "", "Lowest rating")
+	maxRating := flags.String("max-rating", "", "Highest rating")
+	source := flags.String("source", "", "Only content scraped from this URL")
+	after := flags.String("scraped-after", "", "Only content first scraped on or after this date (YYYY-MM-DD)")
+	before := flags.String("scraped-before", "", "Only content first scraped before this date (YYYY-MM-DD)")
+	sortBy := flags.String("sort", storage.SortTitle, "Sort by scraped_at, updated_at, rating, year or title")
+	desc := flags.Bool("desc", false, "Sort in descending order")
+	limit := flags.Int("limit", 0, "Maximum number of items (0 for all)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	filter := storage.ContentFilter{
+		Category:   *category,
+		Type:       *contentType,
+		SourceURL:  *source,
+		SortBy:     *sortBy,
+		Descending: *desc,
+		Limit:      *limit,
+	}
+	var err error
+	if filter.MinYear, err = parseOptionalInt("min-year", *minYear); err != nil {
+		return err
+	}
+	if filter.MaxYear, err = parseOptionalInt("max-year", *maxYear); err != nil {
+		return err
+	}
+	if filter.MinRating, err = parseOptionalFloat("min-rating", *minRating); err != nil {
+		return err
+	}
+	if filter.MaxRating, err = parseOptionalFloat("max-rating", *maxRating); err != nil {
+		return err
+	}
+	if filter.ScrapedAfter, err = parseOptionalDate("scraped-after", *after); err != nil {
+		return err
+	}
+	if filter.ScrapedBefore, err = parseOptionalDate("scraped-before", *before); err != nil {
+		return err
+	}
+
+	exportFormat, err := catalogFormat(*format, *output, catalog.FormatCSV)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" && *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %v", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	count, err := catalog.Export(store, w, exportFormat, filter)
+	if err != nil {
+		return err
+	}
+
+	// Keep stdout clean for the export itself
+	fmt.Fprintf(os.Stderr, "Exported %d items as %s\n", count, exportFormat)
+	return nil
+}
+
+// importContent merges a CSV or JSON Lines file into the catalog
+func importContent(store storage.StorageInterface, args []string) error {
+	flags := flag.NewFlagSet("content import", flag.ContinueOnError)
+	input := flags.String("i", "", "Input file")
+	format := flags.String("format", "", "csv or jsonl (default from the file extension)")
+	dryRun := flags.Bool("dry-run", false, "Report what would change without saving")
+	skipConflicts := flags.Bool("skip-conflicts", false, "Keep stored items that differ from the file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		return fmt.Errorf("-i is required")
+	}
+
+	importFormat, err := catalogFormat(*format, *input, "")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", *input, err)
+	}
+	defer file.Close()
+
+	report, err := catalog.Import(store, file, importFormat, catalog.ImportOptions{DryRun: *dryRun, SkipConflicts: *skipConflicts})
+	if err != nil {
+		return err
+	}
+
+	for _, rejection := range report.Rejected {
+		fmt.Printf("Line %d: rejected %q: %s\n", rejection.Line, rejection.Title, rejection.Reason)
+	}
+	for _, conflict := range report.Conflicts {
+		action := "updates"
+		if conflict.Skipped {
+			action = "skipped, keeps"
+		}
+		fmt.Printf("Line %d: %s %s #%d\n", conflict.Line, action, conflict.Content.Title, conflict.Content.ID)
+		for _, change := range conflict.Changes {
+			fmt.Printf("    %s\n", change)
+		}
+	}
+
+	prefix := "Imported"
+	if report.DryRun {
+		prefix = "Dry run, nothing saved:"
+	}
+	fmt.Printf("%s %d read, %d new, %d updated, %d unchanged, %d skipped, %d rejected, %d conflicts\n",
+		prefix, report.Read, report.Inserted, report.Updated, report.Unchanged, report.Skipped, len(report.Rejected), len(report.Conflicts))
+	return nil
+}
+
+// catalogFormat returns the named format, or the one matching the file's
+// extension, or the fallback
+func catalogFormat(name, path string, fallback catalog.Format) (catalog.Format, error) {
+	if name != "" {
+		return catalog.ParseFormat(name)
+	}
+	if format, err := catalog.FormatFromPath(path); err == nil {
+		return format, nil
+	}
+	if fallback != "" {
+		return fallback, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %q, use -format", path)
+}
+
+func parseOptionalInt(name, value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s %q", name, value)
+	}
+	return &n, nil
+}
+
+func parseOptionalFloat(name, value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s %q", name, value)
+	}
+	return &f, nil
+}
+
+func parseOptionalDate(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s %q, expected YYYY-MM-DD", name, value)
+	}
+	return &t, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"cine-pulse/storage"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// runContent executes a "content" action
+func runContent(store storage.StorageInterface, action string, args []string) error {
+	switch action {
+	case "export":
+		return exportContent(store, args)
+	case "import":
+		return importContent(store, args)
+	case "archived":
+		return listArchived(store, args)
+	case "duplicates":
+		return listDuplicates(store, args)
+	case "merge":
+		return mergeContent(store, args)
+	}
+
+	flags := flag.NewFlagSet("content "+action, flag.ContinueOnError)
+	id := flags.Int64("id", 0, "Content id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	switch action {
+	case "show":
+		return showContent(store, *id)
+	case "delete":
+		return deleteContent(store, *id)
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+}
+
+func showContent(store storage.StorageInterface, id int64) error {
+	content, err := store.GetContentByID(id)
+	if err != nil {
+		return err
+	}
+	if content == nil {
+		return fmt.Errorf("content %d not found", id)
+	}
+
+	year, rating, sourceURL := "-", "-", "-"
+	if content.Year != nil {
+		year = fmt.Sprint(*content.Year)
+	}
+	if content.Rating != nil {
+		rating = fmt.Sprintf("%.1f", *content.Rating)
+	}
+	if content.SourceURL != nil {
+		sourceURL = orDash(*content.SourceURL)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\t%d\n", content.ID)
+	fmt.Fprintf(w, "Title\t%s\n", content.Title)
+	fmt.Fprintf(w, "Type\t%s\n", content.Type)
+	fmt.Fprintf(w, "Year\t%s\n", year)
+	fmt.Fprintf(w, "Category\t%s\n", orDash(content.Category))
+	fmt.Fprintf(w, "Extra info\t%s\n", orDash(content.ExtraInfo))
+	fmt.Fprintf(w, "Rating\t%s\n", rating)
+	fmt.Fprintf(w, "Source\t%s\n", sourceURL)
+	fmt.Fprintf(w, "Scraped\t%s\n", formatTime(content.ScrapedAt))
+	fmt.Fprintf(w, "Created\t%s\n", formatTime(content.CreatedAt))
+	fmt.Fprintf(w, "Updated\t%s\n", formatTime(content.UpdatedAt))
+	fmt.Fprintf(w, "Last seen\t%s\n", formatTime(content.LastSeenAt))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	sources, err := store.GetContentSources(id)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tFIRST SEEN\tLAST SEEN\tCHANGED\tEXTRA INFO")
+	for _, source := range sources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", source.URL, formatTime(&source.FirstSeenAt),
+			formatTime(&source.LastSeenAt), formatTime(&source.UpdatedAt), orDash(source.ExtraInfo))
+	}
+	return w.Flush()
+}
+
+func deleteContent(store storage.StorageInterface, id int64) error {
+	if err := store.DeleteContent(id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted content %d with its history, episodes and embeddings\n", id)
+	return nil
+}
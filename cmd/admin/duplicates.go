@@ -0,0 +1,94 @@
+package main
+
+import (
+	"cine-pulse/storage"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// listDuplicates shows groups of items that look like the same title and,
+// with -merge, folds each group into its oldest item
+func listDuplicates(store storage.StorageInterface, args []string) error {
+	flags := flag.NewFlagSet("content duplicates", flag.ContinueOnError)
+	threshold := flags.Float64("threshold", 0.6, "Lowest title similarity between 0 and 1")
+	contentType := flags.String("type", "", "Only this type (movie or series)")
+	merge := flags.Bool("merge", false, "Merge every group into its oldest item")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *threshold <= 0 || *threshold > 1 {
+		return fmt.Errorf("-threshold must be between 0 and 1")
+	}
+
+	clusters, err := store.FindDuplicates(storage.DuplicateOptions{Threshold: *threshold, Type: *contentType})
+	if err != nil {
+		return err
+	}
+	if len(clusters) == 0 {
+		fmt.Println("No duplicate candidates")
+		return nil
+	}
+
+	for i, cluster := range clusters {
+		fmt.Printf("Group %d, similarity %.2f:\n", i+1, cluster.Similarity)
+		for _, content := range cluster.Items {
+			year := "-"
+			if content.Year != nil {
+				year = strconv.Itoa(*content.Year)
+			}
+			fmt.Printf("  %d\t%s [%s]\t%s\tscraped %s\n", content.ID, content.Title, content.Type, year, formatTime(content.ScrapedAt))
+		}
+	}
+
+	if !*merge {
+		fmt.Printf("\n%d groups. Merge one with: content merge -keep ID -ids ID,ID\n", len(clusters))
+		return nil
+	}
+
+	fmt.Println()
+	for _, cluster := range clusters {
+		keep := cluster.Items[0]
+		var ids []int64
+		for _, duplicate := range cluster.Items[1:] {
+			ids = append(ids, duplicate.ID)
+		}
+		if err := mergeInto(store, keep.ID, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeContent folds the items given with -ids into the one given with -keep
+func mergeContent(store storage.StorageInterface, args []string) error {
+	flags := flag.NewFlagSet("content merge", flag.ContinueOnError)
+	keep := flags.Int64("keep", 0, "Id of the item to keep")
+	list := flags.String("ids", "", "Comma-separated ids of the duplicates to merge into it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *keep == 0 || *list == "" {
+		return fmt.Errorf("-keep and -ids are required")
+	}
+
+	var ids []int64
+	for _, field := range strings.Split(*list, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid id %q in -ids", field)
+		}
+		ids = append(ids, id)
+	}
+	return mergeInto(store, *keep, ids)
+}
+
+func mergeInto(store storage.StorageInterface, keepID int64, ids []int64) error {
+	kept, err := store.MergeContent(keepID, ids)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Merged %d items into %d %s, keeping their history, episodes and sources\n", len(ids), kept.ID, kept.Title)
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"cine-pulse/storage"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func usage() {
+	fmt.Println("Usage: admin [-data ./data] <command> <action> [flags]")
+	fmt.Println()
+	fmt.Println("The backend is selected with STORAGE_BACKEND; -data only applies to SQLite.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  sources list                       List registered sources and their health")
+	fmt.Println("  sources add -url URL [flags]       Register a source")
+	fmt.Println("  sources update -id N [flags]       Change a source's settings")
+	fmt.Println("  sources enable -id N               Enable a source")
+	fmt.Println("  sources disable -id N              Disable a source")
+	fmt.Println("  sources remove -id N               Remove a source")
+	fmt.Println("  sources coverage                   Compare the content each source lists and which is first with updates")
+	fmt.Println("  runs list [-limit 10]              Show recent scrape runs with per-source outcomes")
+	fmt.Println("  runs failures [-min 3]             Show sources failing repeatedly")
+	fmt.Println("  content show -id N                 Show a content item with its timestamps and the sources listing it")
+	fmt.Println("  content delete -id N               Delete a content item and its history, episodes and embeddings")
+	fmt.Println("  content export [-o file] [flags]   Export content as CSV, JSON Lines or Markdown, with query filters")
+	fmt.Println("  content import -i file [flags]     Merge a CSV or JSON Lines file (-dry-run, -skip-conflicts)")
+	fmt.Println("  content archived [-limit 20]       Show content moved to the archive by the retention policy")
+	fmt.Println("  content duplicates [flags]         List groups of near-identical titles (-threshold 0.6, -type, -merge)")
+	fmt.Println("  content merge -keep N -ids N,N     Merge duplicates into one item, keeping their history")
+	fmt.Println("  watchlist list                     List watched titles and keywords with their match counts")
+	fmt.Println("  watchlist add -title T [flags]     Alert on new items or episodes of a title, or -keyword K (-type, -category)")
+	fmt.Println("  watchlist remove -id N             Stop watching an entry")
+	fmt.Println("  maintenance run [flags]            Apply content retention now, then vacuum and analyze")
+	fmt.Println("  stats show [-weeks 8] [-json]      Show counts by category, source, day and week, top new items and source health")
+}
+
+func main() {
+	config := storage.ConfigFromEnv()
+	dataPath := flag.String("data", config.DataPath, "Path to the SQLite database directory")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	config.DataPath = *dataPath
+	store, err := storage.New(config)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := store.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	switch args[0] {
+	case "sources":
+		err = runSources(store, args[1], args[2:])
+	case "runs":
+		err = runRuns(store, args[1], args[2:])
+	case "content":
+		err = runContent(store, args[1], args[2:])
+	case "watchlist":
+		err = runWatchlist(store, args[1], args[2:])
+	case "maintenance":
+		err = runMaintenance(store, args[1], args[2:])
+	case "stats":
+		err = runStats(store, args[1], args[2:])
+	default:
+		fmt.Printf("Unknown command: %s\n", args[0])
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		log.Fatalf("%s %s failed: %v", args[0], args[1], err)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"cine-pulse/scheduler"
+	"cine-pulse/storage"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// runMaintenance executes a "maintenance" action
+func runMaintenance(store storage.StorageInterface, action string, args []string) error {
+	if action != "run" {
+		return fmt.Errorf("unknown action %q", action)
+	}
+
+	config := storage.MaintenanceConfigFromEnv()
+	flags := flag.NewFlagSet("maintenance run", flag.ContinueOnError)
+	days := flags.Int("days", int(config.Retention.MaxUnseen.Hours()/24), "Remove content not seen for this many days (0 keeps everything)")
+	purge := flags.Bool("purge", config.Retention.Action == storage.RetentionPurge, "Delete expired content instead of archiving it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *days < 0 {
+		return fmt.Errorf("-days must not be negative")
+	}
+
+	retention := storage.ContentRetention{MaxUnseen: time.Duration(*days) * 24 * time.Hour, Action: storage.RetentionArchive}
+	if *purge {
+		retention.Action = storage.RetentionPurge
+	}
+
+	report, err := scheduler.NewMaintenanceJob(store, retention).Maintain()
+	if report.Action != "" {
+		verb := "Archived"
+		if report.Action == storage.RetentionPurge {
+			verb = "Purged"
+		}
+		fmt.Printf("%s %d items last seen before %s\n", verb, len(report.Removed), report.Cutoff.Local().Format("2006-01-02 15:04"))
+		for _, content := range report.Removed {
+			fmt.Printf("  %d\t%s [%s]\tlast seen %s\n", content.ID, content.Title, content.Type, formatTime(content.LastSeenAt))
+		}
+	} else {
+		fmt.Println("Retention is off, no content removed")
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Database vacuumed and analyzed in %s\n", report.Duration.Round(time.Millisecond))
+	return nil
+}
+
+// listArchived shows the most recently archived content
+func listArchived(store storage.StorageInterface, args []string) error {
+	flags := flag.NewFlagSet("content archived", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "Number of items to show")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	archived, err := store.GetArchivedContent(*limit)
+	if err != nil {
+		return err
+	}
+	if len(archived) == 0 {
+		fmt.Println("No archived content")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tTYPE\tCATEGORY\tLAST SEEN\tARCHIVED")
+	for _, item := range archived {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", item.ID, item.Title, item.Type, orDash(item.Category),
+			formatTime(item.LastSeenAt), formatTime(&item.ArchivedAt))
+	}
+	return w.Flush()
+}
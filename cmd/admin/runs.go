@@ -0,0 +1,70 @@
+package main
+
+import (
+	"cine-pulse/storage"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// runRuns executes a "runs" action
+func runRuns(store storage.StorageInterface, action string, args []string) error {
+	flags := flag.NewFlagSet("runs "+action, flag.ContinueOnError)
+	limit := flags.Int("limit", 10, "Number of runs to show")
+	minFailures := flags.Int("min", 3, "Minimum consecutive failures to report")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		return listRuns(store, *limit)
+	case "failures":
+		return listFailureStreaks(store, *minFailures)
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+}
+
+func listRuns(store storage.StorageInterface, limit int) error {
+	runs, err := store.RecentScrapeRuns(limit)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, run := range runs {
+		duration := "running"
+		if run.FinishedAt != nil {
+			duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+		}
+		fmt.Fprintf(w, "Run %d\t%s\t%s\t%s\t%s\n", run.ID, run.Trigger, formatTime(&run.StartedAt), duration, orDash(run.Error))
+		for _, source := range run.Sources {
+			fmt.Fprintf(w, "  %s\tHTTP %d\t%d bytes\t%s\textracted %d, rejected %d, filtered %d, saved %d (%d new, %d updated)\t%s\n",
+				source.URL, source.HTTPStatus, source.Bytes, orDash(source.Model), source.Extracted, source.Rejected,
+				source.Filtered, source.Saved, source.Inserted, source.Updated, orDash(source.Error))
+		}
+	}
+	return w.Flush()
+}
+
+func listFailureStreaks(store storage.StorageInterface, minFailures int) error {
+	streaks, err := store.FailureStreaks(minFailures)
+	if err != nil {
+		return err
+	}
+
+	if len(streaks) == 0 {
+		fmt.Printf("No sources have failed %d or more times in a row\n", minFailures)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "URL\tFAILURES\tSINCE\tLAST ERROR")
+	for _, streak := range streaks {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", streak.URL, streak.Failures, formatTime(&streak.Since), streak.LastError)
+	}
+	return w.Flush()
+}
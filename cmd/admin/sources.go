@@ -0,0 +1,272 @@
+package main
+
+import (
+	"cine-pulse/extractor"
+	"cine-pulse/scheduler"
+	"cine-pulse/scraper"
+	"cine-pulse/storage"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runSources executes a "sources" action
+func runSources(store storage.StorageInterface, action string, args []string) error {
+	switch action {
+	case "list":
+		return listSources(store)
+	case "add":
+		return addSource(store, args)
+	case "update":
+		return updateSource(store, args)
+	case "enable", "disable":
+		return setSourceEnabled(store, args, action == "enable")
+	case "remove":
+		return removeSource(store, args)
+	case "coverage":
+		return showCoverage(store)
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+}
+
+func listSources(store storage.StorageInterface) error {
+	sources, err := store.ListSources()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTYPE\tENABLED\tSCHEDULE\tPROFILE\tLAST SUCCESS\tFAILURES\tLAST ERROR\tURL")
+	for _, source := range sources {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			source.ID, source.Name, source.Type, source.Enabled, orDash(source.Schedule), orDash(source.ExtractionProfile),
+			formatTime(source.LastSuccessAt), source.ConsecutiveFailures, orDash(source.LastError), source.URL)
+	}
+	return w.Flush()
+}
+
+// showCoverage compares what each source lists with the other sources
+func showCoverage(store storage.StorageInterface) error {
+	coverage, err := store.GetSourceCoverage()
+	if err != nil {
+		return err
+	}
+	if len(coverage) == 0 {
+		fmt.Println("No content has been recorded with a source yet")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ITEMS\tEXCLUSIVE\tSHARED\tFIRST WITH UPDATE\tURL")
+	for _, source := range coverage {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%s\n", source.Items, source.Exclusive, source.Shared, source.Leads, source.URL)
+	}
+	return w.Flush()
+}
+
+// sourceFlags holds the settings flags shared by add and update
+type sourceFlags struct {
+	set      *flag.FlagSet
+	id       *int64
+	url      *string
+	name     *string
+	typ      *string
+	schedule *string
+	profile  *string
+	disabled *bool
+}
+
+func newSourceFlags(name string) *sourceFlags {
+	set := flag.NewFlagSet(name, flag.ContinueOnError)
+	return &sourceFlags{
+		set:      set,
+		id:       set.Int64("id", 0, "Source id"),
+		url:      set.String("url", "", "Page URL"),
+		name:     set.String("name", "", "Display name (defaults to the URL's host)"),
+		typ:      set.String("type", "", "Source type: "+strings.Join(scraper.SourceTypes, ", ")),
+		schedule: set.String("schedule", "", "Cron schedule with seconds, e.g. \"0 0 12 * * *\"; \"default\" clears it"),
+		profile:  set.String("profile", "", "Extraction profile: "+strings.Join(profileNames(), ", ")),
+		disabled: set.Bool("disabled", false, "Register the source disabled"),
+	}
+}
+
+// visited reports whether a flag was given on the command line
+func (f *sourceFlags) visited(name string) bool {
+	found := false
+	f.set.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
+func addSource(store storage.StorageInterface, args []string) error {
+	flags := newSourceFlags("sources add")
+	if err := flags.set.Parse(args); err != nil {
+		return err
+	}
+	if *flags.url == "" {
+		return fmt.Errorf("-url is required")
+	}
+
+	source := storage.Source{
+		URL:               *flags.url,
+		Name:              *flags.name,
+		Type:              *flags.typ,
+		Enabled:           !*flags.disabled,
+		Schedule:          *flags.schedule,
+		ExtractionProfile: *flags.profile,
+	}
+	if err := checkSourceSettings(source); err != nil {
+		return err
+	}
+
+	id, err := store.AddSource(source)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added source %d: %s\n", id, source.URL)
+	return nil
+}
+
+func updateSource(store storage.StorageInterface, args []string) error {
+	flags := newSourceFlags("sources update")
+	if err := flags.set.Parse(args); err != nil {
+		return err
+	}
+
+	source, err := findSource(store, *flags.id)
+	if err != nil {
+		return err
+	}
+
+	// Only change the settings that were given
+	if flags.visited("url") {
+		source.URL = *flags.url
+	}
+	if flags.visited("name") {
+		source.Name = *flags.name
+	}
+	if flags.visited("type") {
+		source.Type = *flags.typ
+	}
+	if flags.visited("schedule") {
+		source.Schedule = *flags.schedule
+		if source.Schedule == "default" {
+			source.Schedule = ""
+		}
+	}
+	if flags.visited("profile") {
+		source.ExtractionProfile = *flags.profile
+	}
+	if flags.visited("disabled") {
+		source.Enabled = !*flags.disabled
+	}
+
+	if err := checkSourceSettings(*source); err != nil {
+		return err
+	}
+	if err := store.UpdateSource(*source); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated source %d: %s\n", source.ID, source.URL)
+	return nil
+}
+
+func setSourceEnabled(store storage.StorageInterface, args []string, enabled bool) error {
+	flags := newSourceFlags("sources enable")
+	if err := flags.set.Parse(args); err != nil {
+		return err
+	}
+
+	source, err := findSource(store, *flags.id)
+	if err != nil {
+		return err
+	}
+
+	source.Enabled = enabled
+	if err := store.UpdateSource(*source); err != nil {
+		return err
+	}
+
+	state := "Disabled"
+	if enabled {
+		state = "Enabled"
+	}
+	fmt.Printf("%s source %d: %s\n", state, source.ID, source.URL)
+	return nil
+}
+
+func removeSource(store storage.StorageInterface, args []string) error {
+	flags := newSourceFlags("sources remove")
+	if err := flags.set.Parse(args); err != nil {
+		return err
+	}
+
+	if err := store.DeleteSource(*flags.id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed source %d\n", *flags.id)
+	return nil
+}
+
+func findSource(store storage.StorageInterface, id int64) (*storage.Source, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("-id is required")
+	}
+	source, err := store.GetSource(id)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("source %d not found", id)
+	}
+	return source, nil
+}
+
+// checkSourceSettings validates the settings storage cannot check itself
+func checkSourceSettings(source storage.Source) error {
+	if source.Schedule != "" {
+		if err := scheduler.ValidateSchedule(source.Schedule); err != nil {
+			return err
+		}
+	}
+	if source.ExtractionProfile != "" {
+		if _, ok := extractor.Profiles[source.ExtractionProfile]; !ok {
+			return fmt.Errorf("unknown extraction profile %q", source.ExtractionProfile)
+		}
+	}
+	return nil
+}
+
+func profileNames() []string {
+	var names []string
+	for name := range extractor.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"cine-pulse/storage"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// runStats executes a "stats" action
+func runStats(store storage.StorageInterface, action string, args []string) error {
+	if action != "show" {
+		return fmt.Errorf("unknown action %q", action)
+	}
+
+	flags := flag.NewFlagSet("stats show", flag.ContinueOnError)
+	days := flags.Int("days", 14, "Number of daily counts")
+	weeks := flags.Int("weeks", 8, "Number of weekly counts; also the period of episode and extraction stats")
+	limit := flags.Int("limit", 10, "Length of the top lists")
+	asJSON := flags.Bool("json", false, "Print the stats as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	stats, err := store.GetDetailedStats(storage.StatsOptions{Days: *days, Weeks: *weeks, Limit: *limit})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Total %d\tmovies %d\tseries %d\n", stats.Total, stats.Movies, stats.Series)
+
+	fmt.Fprintln(w, "\nCATEGORY\tITEMS\tRATED\tAVG RATING")
+	for _, category := range stats.Categories {
+		average := "-"
+		if category.AverageRating != nil {
+			average = fmt.Sprintf("%.2f", *category.AverageRating)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", orDash(category.Category), category.Count, category.Rated, average)
+	}
+
+	fmt.Fprintln(w, "\nSOURCE\tITEMS")
+	for _, source := range stats.Sources {
+		fmt.Fprintf(w, "%s\t%d\n", orDash(source.SourceURL), source.Count)
+	}
+
+	fmt.Fprintln(w, "\nDAY\tNEW ITEMS")
+	for _, day := range stats.Daily {
+		fmt.Fprintf(w, "%s\t%d\n", day.Start.Format("2006-01-02 Mon"), day.Count)
+	}
+
+	fmt.Fprintln(w, "\nWEEK OF\tNEW ITEMS")
+	for _, week := range stats.Weekly {
+		fmt.Fprintf(w, "%s\t%d\n", week.Start.Format("2006-01-02"), week.Count)
+	}
+
+	fmt.Fprintln(w, "\nTOP RATED THIS WEEK\tTYPE\tRATING")
+	for _, content := range stats.TopNew {
+		fmt.Fprintf(w, "%s\t%s\t%.1f\n", content.Title, content.Type, *content.Rating)
+	}
+
+	fmt.Fprintln(w, "\nMOST UPDATED SERIES\tNEW EPISODES")
+	for _, series := range stats.MostUpdatedSeries {
+		fmt.Fprintf(w, "%s\t%d\n", series.Title, series.NewEpisodes)
+	}
+
+	fmt.Fprintln(w, "\nSOURCE\tSCRAPES\tSUCCESS\tEXTRACTED\tACCEPTED\tFILTERED\tSAVED")
+	for _, e := range stats.Extraction {
+		fmt.Fprintf(w, "%s\t%d\t%.0f%%\t%d\t%.0f%%\t%d\t%d\n", e.URL, e.Scrapes, e.SuccessRate*100,
+			e.Extracted, e.AcceptanceRate*100, e.Filtered, e.Saved)
+	}
+	return w.Flush()
+}
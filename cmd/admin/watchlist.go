@@ -0,0 +1,103 @@
+package main
+
+import (
+	"cine-pulse/storage"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runWatchlist executes a "watchlist" action
+func runWatchlist(store storage.StorageInterface, action string, args []string) error {
+	switch action {
+	case "list":
+		return listWatchlist(store)
+	case "add":
+		return addWatchlistEntry(store, args)
+	case "remove":
+		return removeWatchlistEntry(store, args)
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+}
+
+func listWatchlist(store storage.StorageInterface) error {
+	entries, err := store.ListWatchlist()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("The watchlist is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKIND\tPATTERN\tTYPE\tCATEGORY\tMATCHES\tLAST MATCH")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			entry.ID, entry.Kind, entry.Pattern, orDash(entry.Type), orDash(entry.Category), entry.Matches, formatTime(entry.LastMatchedAt))
+	}
+	return w.Flush()
+}
+
+func addWatchlistEntry(store storage.StorageInterface, args []string) error {
+	flags := flag.NewFlagSet("watchlist add", flag.ContinueOnError)
+	title := flags.String("title", "", "Title to watch, e.g. \"The Boys\"")
+	keyword := flags.String("keyword", "", "Words to watch for in titles, categories and extra info, e.g. \"marvel\"")
+	contentType := flags.String("type", "", "Only this type (movie or series)")
+	category := flags.String("category", "", "Only this category")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*title == "") == (*keyword == "") {
+		return fmt.Errorf("give one of -title or -keyword")
+	}
+
+	entry := storage.WatchlistEntry{Kind: storage.WatchTitle, Pattern: *title, Type: *contentType, Category: *category}
+	if *keyword != "" {
+		entry.Kind, entry.Pattern = storage.WatchKeyword, *keyword
+	}
+
+	id, err := store.AddWatchlistEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	// Show what the entry would have matched so far
+	contents, err := store.GetAllContent()
+	if err != nil {
+		return err
+	}
+	var matching []string
+	for _, content := range contents {
+		if entry.Match(content) {
+			matching = append(matching, content.Title)
+		}
+	}
+
+	fmt.Printf("Added watchlist entry %d: %s %q\n", id, entry.Kind, strings.TrimSpace(entry.Pattern))
+	if len(matching) > 0 {
+		fmt.Printf("Already stored and matching (%d): %s\n", len(matching), strings.Join(matching, ", "))
+	}
+	return nil
+}
+
+func removeWatchlistEntry(store storage.StorageInterface, args []string) error {
+	flags := flag.NewFlagSet("watchlist remove", flag.ContinueOnError)
+	id := flags.Int64("id", 0, "Watchlist entry id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	if err := store.DeleteWatchlistEntry(*id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed watchlist entry %d\n", *id)
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"cine-pulse/evaluation"
+	"cine-pulse/model"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// apiKeyEnv maps each model type to the environment variable holding its API key
+var apiKeyEnv = map[model.ModelType]string{
+	model.ModelTypeGemini: "GEMINI_API_KEY",
+	model.ModelTypeOpenAI: "OPENAI_API_KEY",
+}
+
+func main() {
+	var (
+		corpusDir = flag.String("corpus", "./corpus", "Directory of labelled pages (<name>.txt + <name>.expected.json)")
+		models    = flag.String("models", "gemini:gemini-1.5-flash,openai:gpt-4o", "Comma-separated list of provider:model to evaluate")
+		timeout   = flag.Int("timeout", 60, "Per-request timeout in seconds")
+	)
+	flag.Parse()
+
+	samples, err := evaluation.LoadCorpus(*corpusDir)
+	if err != nil {
+		log.Fatalf("Failed to load corpus: %v", err)
+	}
+	log.Printf("Loaded %d labelled pages from %s", len(samples), *corpusDir)
+
+	modelManager := model.NewModelManager()
+	defer modelManager.CloseAll()
+
+	var results []evaluation.ModelResult
+	for _, spec := range strings.Split(*models, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		modelType, modelName, found := strings.Cut(spec, ":")
+		if !found {
+			log.Fatalf("Invalid model %q, expected provider:model", spec)
+		}
+
+		apiKey := os.Getenv(apiKeyEnv[model.ModelType(modelType)])
+		if apiKey == "" {
+			log.Printf("Skipping %s: no API key configured", spec)
+			continue
+		}
+
+		m, err := modelManager.CreateModel(model.ModelType(modelType), &model.ModelConfig{
+			APIKey:    apiKey,
+			ModelName: modelName,
+			Timeout:   *timeout,
+		})
+		if err != nil {
+			log.Printf("Skipping %s: %v", spec, err)
+			continue
+		}
+
+		log.Printf("Evaluating %s", m.GetModelName())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*timeout*len(samples))*time.Second)
+		results = append(results, evaluation.EvaluateModel(ctx, m, samples))
+		cancel()
+	}
+
+	if len(results) == 0 {
+		log.Fatal("No models were evaluated")
+	}
+
+	fmt.Println()
+	if err := evaluation.WriteReport(os.Stdout, results); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
@@ -18,22 +18,20 @@ import (
 )
 
 func main() {
-	// Initialize storage
-	dataPath := os.Getenv("DATA_PATH")
-	if dataPath == "" {
-		dataPath = "./data"
-	}
-
 	// Initialize logger with timestamp
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 	log.Println("Starting Cine Pulse application...")
 
-	// Initialize storage
-	sqliteStorage := storage.NewSQLiteStorage(dataPath)
-	if err := sqliteStorage.Initialize(); err != nil {
+	// Initialize the backend selected by STORAGE_BACKEND
+	config := storage.ConfigFromEnv()
+	store, err := storage.New(config)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := store.Initialize(); err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
-	defer sqliteStorage.Close()
+	defer store.Close()
 
 	// Initialize scraper and model manager
 	webScraper := scraper.NewScraper()
@@ -41,7 +39,7 @@ func main() {
 
 	// Get configuration
 	runMode := os.Getenv("RUN_MODE")
-	sourceURLs := getSourceURLs()
+	syncSourceURLs(store)
 
 	if runMode == "scheduler" || runMode == "" {
 		log.Println("Starting in scheduler mode")
@@ -50,13 +48,22 @@ func main() {
 		sched := scheduler.NewScheduler()
 
 		// Create content scraper job
-		scraperJob := scheduler.NewContentScraperJob(webScraper, sqliteStorage, modelManager, sourceURLs)
+		scraperJob := scheduler.NewContentScraperJob(webScraper, store, modelManager)
 
 		// Add job to run at 10am and 5pm
 		if err := sched.AddMorningEveningJob(scraperJob); err != nil {
 			log.Fatalf("Failed to schedule content scraper job: %v", err)
 		}
 
+		// Sources with their own schedule get a job of their own
+		scheduleSourceJobs(sched, store, scraperJob)
+
+		// Snapshot the database if BACKUP_SCHEDULE is set
+		scheduleBackupJob(sched, store, storage.BackupConfigFromEnv(config.DataPath))
+
+		// Apply content retention and vacuum if MAINTENANCE_SCHEDULE is set
+		scheduleMaintenanceJob(sched, store, storage.MaintenanceConfigFromEnv())
+
 		// Start the scheduler
 		sched.Start()
 		log.Println("Scheduler started. Content will be scraped at 10:00 AM and 5:00 PM daily")
@@ -64,13 +71,13 @@ func main() {
 		// Run the job once at startup if specified
 		if os.Getenv("RUN_AT_STARTUP") == "true" {
 			log.Println("Running initial content scrape at startup")
-			if err := sched.RunJobNow(scraperJob.Name()); err != nil {
+			if err := sched.RunJobNowWithTrigger(scraperJob.Name(), scheduler.TriggerStartup); err != nil {
 				log.Printf("Error running initial job: %v", err)
 			}
 		}
 
 		// Display database stats
-		displayDatabaseStats(sqliteStorage)
+		displayDatabaseStats(store)
 
 		// Set up signal handling for graceful shutdown
 		quit := make(chan os.Signal, 1)
@@ -89,10 +96,10 @@ func main() {
 		log.Println("Running in single execution mode")
 
 		// Create the job
-		scraperJob := scheduler.NewContentScraperJob(webScraper, sqliteStorage, modelManager, sourceURLs)
+		scraperJob := scheduler.NewContentScraperJob(webScraper, store, modelManager)
 
 		// Run it once with a timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		ctx, cancel := context.WithTimeout(scheduler.WithTrigger(context.Background(), scheduler.TriggerManual), 10*time.Minute)
 		defer cancel()
 
 		if err := scraperJob.Run(ctx); err != nil {
@@ -100,33 +107,97 @@ func main() {
 		}
 
 		// Display database stats
-		displayDatabaseStats(sqliteStorage)
+		displayDatabaseStats(store)
 	}
 
 	log.Println("Application exiting")
 }
 
-// getSourceURLs returns the source URLs to scrape from environment variables
-func getSourceURLs() []string {
-	// Default source
-	sourceURLs := []string{"https://nkiri.com/"}
-
-	// Check for additional sources in environment variables
-	if sources := os.Getenv("SOURCE_URLS"); sources != "" {
-		// Parse comma-separated list
-		var additionalSources []string
-		if err := json.Unmarshal([]byte(sources), &additionalSources); err != nil {
-			log.Printf("Error parsing SOURCE_URLS: %v", err)
-		} else {
-			sourceURLs = additionalSources
+// syncSourceURLs registers URLs from the SOURCE_URLS environment variable in the
+// sources registry. Without SOURCE_URLS the default source is registered if
+// the registry is empty.
+func syncSourceURLs(store storage.StorageInterface) {
+	sources := os.Getenv("SOURCE_URLS")
+	if sources == "" {
+		if err := storage.RegisterDefaultSource(store); err != nil {
+			log.Printf("Error registering the default source: %v", err)
 		}
+		return
+	}
+
+	var sourceURLs []string
+	if err := json.Unmarshal([]byte(sources), &sourceURLs); err != nil {
+		log.Printf("Error parsing SOURCE_URLS: %v", err)
+		return
 	}
 
-	return sourceURLs
+	storage.RegisterSourceURLs(store, sourceURLs)
+}
+
+// scheduleSourceJobs adds a job for every enabled source with a schedule override
+func scheduleSourceJobs(sched *scheduler.Scheduler, store storage.StorageInterface, scraperJob *scheduler.ContentScraperJob) {
+	sources, err := store.ListSources()
+	if err != nil {
+		log.Printf("Error listing sources: %v", err)
+		return
+	}
+
+	for _, source := range sources {
+		if !source.Enabled || source.Schedule == "" {
+			continue
+		}
+
+		job := scraperJob.ForSource(source)
+		if err := sched.AddJob(source.Schedule, job); err != nil {
+			log.Printf("Failed to schedule source %s: %v", source.Name, err)
+			continue
+		}
+		log.Printf("Source %s scheduled separately: %s", source.Name, source.Schedule)
+	}
+}
+
+// scheduleBackupJob adds the backup job when a backup schedule is configured
+// and the backend supports online backups
+func scheduleBackupJob(sched *scheduler.Scheduler, store storage.StorageInterface, config storage.BackupConfig) {
+	if config.Schedule == "" {
+		return
+	}
+
+	snapshotter, ok := store.(storage.Snapshotter)
+	if !ok {
+		log.Println("BACKUP_SCHEDULE is ignored: the storage backend has no online backups")
+		return
+	}
+
+	job := scheduler.NewBackupJob(snapshotter, config.Dir, config.Retention)
+	if err := sched.AddJob(config.Schedule, job); err != nil {
+		log.Printf("Failed to schedule database backups: %v", err)
+		return
+	}
+	log.Printf("Database backups scheduled: %s into %s", config.Schedule, config.Dir)
+}
+
+// scheduleMaintenanceJob adds the maintenance job when a maintenance schedule is configured
+func scheduleMaintenanceJob(sched *scheduler.Scheduler, store storage.StorageInterface, config storage.MaintenanceConfig) {
+	if config.Schedule == "" {
+		return
+	}
+
+	job := scheduler.NewMaintenanceJob(store, config.Retention)
+	if err := sched.AddJob(config.Schedule, job); err != nil {
+		log.Printf("Failed to schedule database maintenance: %v", err)
+		return
+	}
+	if config.Retention.MaxUnseen > 0 {
+		log.Printf("Database maintenance scheduled: %s, content unseen for %d days is %sd",
+			config.Schedule, int(config.Retention.MaxUnseen.Hours()/24), config.Retention.Action)
+	} else {
+		log.Printf("Database maintenance scheduled: %s, all content is kept", config.Schedule)
+	}
 }
 
 // displayDatabaseStats shows database statistics
-func displayDatabaseStats(db *storage.SQLiteStorage) {
+func displayDatabaseStats(db storage.StorageInterface) {
 	log.Println("Database Statistics")
 
 	// Get database stats
@@ -141,20 +212,14 @@ func displayDatabaseStats(db *storage.SQLiteStorage) {
 	log.Printf("Series: %d", stats["series"])
 
 	// Show recent content
-	allContent, err := db.GetAllContent()
+	recent, err := db.QueryContent(storage.ContentFilter{SortBy: storage.SortScrapedAt, Descending: true, Limit: 5})
 	if err != nil {
 		log.Printf("Error getting content: %v", err)
 		return
 	}
 
-	limit := 5
-	if len(allContent) < limit {
-		limit = len(allContent)
-	}
-
-	log.Printf("Recent Content (last %d):", limit)
-	for i := 0; i < limit; i++ {
-		content := allContent[i]
+	log.Printf("Recent Content (last %d):", len(recent.Items))
+	for _, content := range recent.Items {
 		year := ""
 		if content.Year != nil {
 			year = fmt.Sprintf(" (%d)", *content.Year)
@@ -9,58 +9,194 @@ import (
 )
 
 func main() {
+	config := storage.ConfigFromEnv()
 	var (
-		dataPath = flag.String("data", "./data", "Path to database directory")
-		command  = flag.String("cmd", "up", "Migration command: up, down, status, version, reset")
+		dataPath = flag.String("data", config.DataPath, "Path to the SQLite database directory")
+		command  = flag.String("cmd", "up", "Migration command: up, down, status, version, verify, reset, backup, backups, restore")
+		backup   = flag.String("backup", "", "Backup file to restore (restore)")
+		dir      = flag.String("backup-dir", "", "Backup directory (default BACKUP_DIR or <data>/backups)")
+		dryRun   = flag.Bool("dry-run", false, "Apply pending migrations to a scratch copy and report what they would do (up)")
 	)
 	flag.Parse()
 
-	// Initialize storage
-	sqliteStorage := storage.NewSQLiteStorage(*dataPath)
-	if err := sqliteStorage.Initialize(); err != nil {
+	// Initialize the backend selected by STORAGE_BACKEND
+	config.DataPath = *dataPath
+	backupConfig := storage.BackupConfigFromEnv(config.DataPath)
+	if *dir != "" {
+		backupConfig.Dir = *dir
+	}
+
+	// Backups are SQLite files; restoring replaces the database before it is opened
+	if *command == "restore" {
+		restore(config, *backup)
+		return
+	}
+
+	// A dry run migrates a copy, so the database must not be migrated on open
+	if *command == "up" && *dryRun {
+		dryRunMigrations(config)
+		return
+	}
+
+	// Verifying inspects the database as it is, without migrating it first
+	if *command == "verify" {
+		verify(config)
+		return
+	}
+
+	store, err := storage.New(config)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := store.Initialize(); err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
-	defer sqliteStorage.Close()
+	defer store.Close()
 
 	// Execute command
 	switch *command {
 	case "up":
-		if err := sqliteStorage.RunMigrations(); err != nil {
+		if err := store.RunMigrations(); err != nil {
 			log.Fatalf("Failed to run migrations: %v", err)
 		}
 		fmt.Println("Migrations completed successfully")
 
 	case "down":
-		if err := sqliteStorage.RollbackMigration(); err != nil {
+		if err := store.RollbackMigration(); err != nil {
 			log.Fatalf("Failed to rollback migration: %v", err)
 		}
 		fmt.Println("Migration rolled back successfully")
 
 	case "status":
-		migrationManager := sqliteStorage.GetMigrationManager()
-		if err := migrationManager.Initialize(); err != nil {
-			log.Fatalf("Failed to initialize migration manager: %v", err)
-		}
-		if err := migrationManager.Status(); err != nil {
+		if err := store.MigrationStatus(); err != nil {
 			log.Fatalf("Failed to get migration status: %v", err)
 		}
 
 	case "version":
-		version, err := sqliteStorage.GetDatabaseVersion()
+		version, err := store.GetDatabaseVersion()
 		if err != nil {
 			log.Fatalf("Failed to get database version: %v", err)
 		}
 		fmt.Printf("Database version: %d\n", version)
 
+	case "backup":
+		snapshotter, ok := store.(storage.Snapshotter)
+		if !ok {
+			log.Fatalf("The %s backend has no online backups; use the database's own tools (e.g. pg_dump)", config.Backend)
+		}
+		path, err := snapshotter.Backup(backupConfig.Dir)
+		if err != nil {
+			log.Fatalf("Failed to back up database: %v", err)
+		}
+		fmt.Printf("Database backed up to %s\n", path)
+
+		removed, err := storage.RotateBackups(backupConfig.Dir, backupConfig.Retention)
+		for _, old := range removed {
+			fmt.Printf("Removed old backup %s\n", old)
+		}
+		if err != nil {
+			log.Fatalf("Failed to rotate backups: %v", err)
+		}
+
+	case "backups":
+		snapshots, err := storage.ListBackups(backupConfig.Dir)
+		if err != nil {
+			log.Fatalf("Failed to list backups: %v", err)
+		}
+		if len(snapshots) == 0 {
+			fmt.Printf("No backups in %s\n", backupConfig.Dir)
+		}
+		for _, snapshot := range snapshots {
+			fmt.Printf("%s  %s  %d bytes\n", snapshot.TakenAt.Format("2006-01-02 15:04:05"), snapshot.Path, snapshot.Size)
+		}
+
 	case "reset":
-		if err := sqliteStorage.ResetDatabase(); err != nil {
+		if err := store.ResetDatabase(); err != nil {
 			log.Fatalf("Failed to reset database: %v", err)
 		}
 		fmt.Println("Database reset completed successfully")
 
 	default:
 		fmt.Printf("Unknown command: %s\n", *command)
-		fmt.Println("Available commands: up, down, status, version, reset")
+		fmt.Println("Available commands: up, down, status, version, verify, reset, backup, backups, restore")
 		os.Exit(1)
 	}
 }
+
+// restore verifies a backup and replaces the SQLite database with it, then
+// migrates the restored database to the current schema
+func restore(config storage.Config, backup string) {
+	if config.Backend != storage.BackendSQLite {
+		log.Fatalf("Restore only supports the sqlite backend, not %s", config.Backend)
+	}
+	if backup == "" {
+		log.Fatal("Usage: migrate -cmd restore -backup <file>")
+	}
+
+	info, err := storage.RestoreBackup(backup, config.DataPath)
+	if err != nil {
+		log.Fatalf("Failed to restore backup: %v", err)
+	}
+	fmt.Printf("Restored %s (schema version %d, %d content items) to %s\n", backup, info.Version, info.Contents, info.Path)
+
+	store := storage.NewSQLiteStorage(config.DataPath)
+	if err := store.Initialize(); err != nil {
+		log.Fatalf("Failed to open restored database: %v", err)
+	}
+	defer store.Close()
+
+	version, err := store.GetDatabaseVersion()
+	if err != nil {
+		log.Fatalf("Failed to get database version: %v", err)
+	}
+	fmt.Printf("Database version: %d\n", version)
+}
+
+// verify compares the live SQLite schema with the one the migrations create
+// and exits with status 1 when they differ
+func verify(config storage.Config) {
+	if config.Backend != storage.BackendSQLite {
+		log.Fatalf("Verify only supports the sqlite backend, not %s", config.Backend)
+	}
+
+	differences, err := storage.NewSQLiteStorage(config.DataPath).VerifySchema()
+	if err != nil {
+		log.Fatalf("Failed to verify schema: %v", err)
+	}
+	if len(differences) == 0 {
+		fmt.Println("Schema matches the migrations")
+		return
+	}
+
+	fmt.Printf("Schema differs from the migrations in %d places:\n", len(differences))
+	for _, difference := range differences {
+		fmt.Printf("  %s\n", difference)
+	}
+	os.Exit(1)
+}
+
+// dryRunMigrations reports what the pending migrations would do to the
+// SQLite database without changing it
+func dryRunMigrations(config storage.Config) {
+	if config.Backend != storage.BackendSQLite {
+		log.Fatalf("Dry runs only support the sqlite backend, not %s", config.Backend)
+	}
+
+	results, err := storage.NewSQLiteStorage(config.DataPath).DryRunMigrations()
+	if err != nil {
+		log.Fatalf("Failed to dry run migrations: %v", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("No pending migrations")
+		return
+	}
+
+	fmt.Println("Dry run, nothing was changed. Pending migrations:")
+	for _, result := range results {
+		if result.Data {
+			fmt.Printf("  %s: %d of %d rows changed\n", result.Source, result.Changed, result.Rows)
+		} else {
+			fmt.Printf("  %s\n", result.Source)
+		}
+	}
+}
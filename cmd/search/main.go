@@ -0,0 +1,176 @@
+package main
+
+import (
+	"cine-pulse/model"
+	"cine-pulse/storage"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	config := storage.ConfigFromEnv()
+	var (
+		dataPath = flag.String("data", config.DataPath, "Path to the SQLite database directory")
+		query    = flag.String("q", "", "Search query")
+		semantic = flag.Bool("semantic", false, "Rank results by meaning using embeddings instead of title match")
+		reindex  = flag.Bool("reindex", false, "Compute embeddings for stored content that has none yet")
+		limit    = flag.Int("limit", 10, "Maximum number of results")
+		history  = flag.Bool("history", false, "Show the change timeline of each result")
+	)
+	flag.Parse()
+
+	if *query == "" && !*reindex {
+		fmt.Println("Usage: search -q \"dark knight\" [-limit 10]")
+		fmt.Println("       search -q \"dark sci-fi series with time travel\" -semantic [-limit 10]")
+		fmt.Println("       search -q \"The Boys\" -history")
+		fmt.Println("       search -reindex")
+		os.Exit(1)
+	}
+
+	config.DataPath = *dataPath
+	store, err := storage.New(config)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := store.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	if !*semantic && !*reindex {
+		results, err := store.SearchText(*query, *limit)
+		if err != nil {
+			log.Fatalf("Failed to search content: %v", err)
+		}
+		for _, result := range results {
+			printContent(result.Content, nil)
+			if result.Snippet != "" {
+				fmt.Printf("    %s\n", result.Snippet)
+			}
+			if *history {
+				printHistory(store, result.Content)
+			}
+		}
+		return
+	}
+
+	embeddingType, embeddingConfig := model.GetEmbeddingConfigFromEnv()
+	if embeddingType == "" {
+		log.Fatal("Semantic search requires EMBEDDING_PROVIDER to be set (openai, gemini or local)")
+	}
+
+	modelManager := model.NewModelManager()
+	defer modelManager.CloseAll()
+
+	embedder, err := modelManager.CreateEmbeddingModel(embeddingType, embeddingConfig)
+	if err != nil {
+		log.Fatalf("Failed to create embedding model: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	if *reindex {
+		if err := reindexEmbeddings(ctx, store, embedder); err != nil {
+			log.Fatalf("Failed to reindex embeddings: %v", err)
+		}
+		if *query == "" {
+			return
+		}
+	}
+
+	vectors, err := embedder.Embed(ctx, []string{*query})
+	if err != nil {
+		log.Fatalf("Failed to embed query: %v", err)
+	}
+
+	results, err := store.SemanticSearch(embedder.GetModelName(), vectors[0], *limit)
+	if err != nil {
+		log.Fatalf("Failed to search content: %v", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No embedded content found. Run with -reindex to compute embeddings.")
+	}
+	for _, result := range results {
+		score := result.Score
+		printContent(result.Content, &score)
+	}
+}
+
+// reindexEmbeddings embeds stored content in batches until every row has a vector
+func reindexEmbeddings(ctx context.Context, store storage.StorageInterface, embedder model.EmbeddingModel) error {
+	const batchSize = 50
+	total := 0
+
+	for {
+		contents, err := store.GetContentWithoutEmbedding(embedder.GetModelName(), batchSize)
+		if err != nil {
+			return err
+		}
+		if len(contents) == 0 {
+			break
+		}
+
+		texts := make([]string, len(contents))
+		for i, content := range contents {
+			texts[i] = content.EmbeddingText()
+		}
+
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return err
+		}
+
+		for i, content := range contents {
+			if err := store.SaveEmbedding(content, embedder.GetModelName(), vectors[i]); err != nil {
+				return err
+			}
+		}
+
+		total += len(contents)
+		log.Printf("Embedded %d content items", total)
+	}
+
+	log.Printf("Reindex complete: %d content items embedded with %s", total, embedder.GetModelName())
+	return nil
+}
+
+func printContent(content storage.Content, score *float64) {
+	year := ""
+	if content.Year != nil {
+		year = fmt.Sprintf(" (%d)", *content.Year)
+	}
+	if score != nil {
+		fmt.Printf("%.3f  #%d %s%s [%s] - %s - %s\n", *score, content.ID, content.Title, year, content.Type, content.Category, content.ExtraInfo)
+		return
+	}
+	fmt.Printf("#%d %s%s [%s] - %s - %s\n", content.ID, content.Title, year, content.Type, content.Category, content.ExtraInfo)
+}
+
+func printHistory(store storage.StorageInterface, content storage.Content) {
+	changes, err := store.GetContentHistory(content.Title, content.Type)
+	if err != nil {
+		log.Printf("Failed to get history for %s: %v", content.Title, err)
+		return
+	}
+	for _, change := range changes {
+		fmt.Printf("    %s  %s\n", change.ChangedAt.Format("2006-01-02 15:04"), change)
+	}
+
+	latest, err := store.LatestEpisode(content.Title, content.Type)
+	if err != nil {
+		log.Printf("Failed to get latest episode for %s: %v", content.Title, err)
+		return
+	}
+	if latest != nil {
+		fmt.Printf("    Latest episode: S%02dE%02d (first seen %s)\n",
+			latest.Season, latest.Number, latest.FirstSeenAt.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,80 @@
+package episodes
+
+import (
+	"cine-pulse/storage"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var (
+	// S02E15, S02E15-E18, S2 E15 - 18
+	seasonEpisodePattern = regexp.MustCompile(`(?i)\bS(\d{1,2})\s*E(\d{1,4})(?:\s*(?:-|–|—|~|to)\s*(?:E)?(\d{1,4}))?\b`)
+	// Season 2, Series 2, S2
+	seasonPattern = regexp.MustCompile(`(?i)\b(?:Season|Series|S)\s*(\d{1,2})\b`)
+	// Episode 15, Episodes 15–18, Ep 15 to Ep 18, E15-E18
+	episodePattern = regexp.MustCompile(`(?i)\b(?:Episodes?|Eps?\.?|E)\s*(\d{1,4})(?:\s*(?:-|–|—|~|to|&)\s*(?:(?:Episode|Ep\.?|E)\s*)?(\d{1,4}))?\b`)
+)
+
+// Parse reads a season and episode range from free text such as
+// "Episode 15–18 Added", "S02E05" or "Season 2". It reports false when the
+// text names neither a season nor an episode.
+func Parse(text string) (storage.EpisodeRange, bool) {
+	if m := seasonEpisodePattern.FindStringSubmatch(text); m != nil {
+		r := storage.EpisodeRange{Season: atoi(m[1]), First: atoi(m[2]), Last: atoi(m[3])}
+		return normalize(r), true
+	}
+
+	var r storage.EpisodeRange
+	found := false
+
+	if m := seasonPattern.FindStringSubmatch(text); m != nil {
+		r.Season = atoi(m[1])
+		found = true
+	}
+	if m := episodePattern.FindStringSubmatch(text); m != nil {
+		r.First = atoi(m[1])
+		r.Last = atoi(m[2])
+		found = true
+	}
+
+	if !found {
+		return storage.EpisodeRange{}, false
+	}
+	return normalize(r), true
+}
+
+// Ranges groups episodes into contiguous runs per season
+func Ranges(episodes []storage.Episode) []storage.EpisodeRange {
+	sorted := append([]storage.Episode(nil), episodes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Season != sorted[j].Season {
+			return sorted[i].Season < sorted[j].Season
+		}
+		return sorted[i].Number < sorted[j].Number
+	})
+
+	var ranges []storage.EpisodeRange
+	for _, episode := range sorted {
+		if n := len(ranges); n > 0 && ranges[n-1].Season == episode.Season && ranges[n-1].Last+1 == episode.Number {
+			ranges[n-1].Last = episode.Number
+			continue
+		}
+		ranges = append(ranges, storage.EpisodeRange{Season: episode.Season, First: episode.Number, Last: episode.Number})
+	}
+
+	return ranges
+}
+
+// normalize makes Last at least First so single episodes are one-long ranges
+func normalize(r storage.EpisodeRange) storage.EpisodeRange {
+	if r.Last < r.First {
+		r.Last = r.First
+	}
+	return r
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
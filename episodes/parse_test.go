@@ -0,0 +1,62 @@
+package episodes
+
+import (
+	"cine-pulse/storage"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected storage.EpisodeRange
+		ok       bool
+	}{
+		{"Episode 15–18 Added", storage.EpisodeRange{First: 15, Last: 18}, true},
+		{"Episode 15-18 Added", storage.EpisodeRange{First: 15, Last: 18}, true},
+		{"Episodes 1 to 8", storage.EpisodeRange{First: 1, Last: 8}, true},
+		{"EP 12", storage.EpisodeRange{First: 12, Last: 12}, true},
+		{"S02E15", storage.EpisodeRange{Season: 2, First: 15, Last: 15}, true},
+		{"s02e15-e18 added", storage.EpisodeRange{Season: 2, First: 15, Last: 18}, true},
+		{"S2 E3 - 4", storage.EpisodeRange{Season: 2, First: 3, Last: 4}, true},
+		{"Season 2 Episode 5 Added", storage.EpisodeRange{Season: 2, First: 5, Last: 5}, true},
+		{"Season 3", storage.EpisodeRange{Season: 3}, true},
+		{"Complete", storage.EpisodeRange{}, false},
+		{"Download Hollywood Movie", storage.EpisodeRange{}, false},
+		{"", storage.EpisodeRange{}, false},
+	}
+
+	for _, test := range tests {
+		got, ok := Parse(test.text)
+		if ok != test.ok || got != test.expected {
+			t.Errorf("Parse(%q) = %+v, %v; expected %+v, %v", test.text, got, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestRanges(t *testing.T) {
+	episodes := []storage.Episode{
+		{Season: 2, Number: 17},
+		{Season: 2, Number: 15},
+		{Season: 2, Number: 16},
+		{Season: 2, Number: 18},
+		{Season: 2, Number: 20},
+		{Season: 3, Number: 1},
+	}
+
+	ranges := Ranges(episodes)
+
+	var got []string
+	for _, r := range ranges {
+		got = append(got, r.String())
+	}
+
+	expected := []string{"S02E15–E18", "S02E20", "S03E01"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Range %d: expected %s, got %s", i, expected[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package evaluation
+
+import (
+	"cine-pulse/storage"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sample is a saved scraped page together with the content we expect a model to extract from it
+type Sample struct {
+	Name     string
+	Text     string
+	Expected []storage.Content
+}
+
+// LoadCorpus reads a labelled corpus from a directory.
+// Every page is stored as <name>.txt next to a <name>.expected.json file holding the expected content list.
+func LoadCorpus(dir string) ([]Sample, error) {
+	pages, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list corpus pages: %v", err)
+	}
+	sort.Strings(pages)
+
+	var samples []Sample
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".txt")
+
+		text, err := os.ReadFile(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %s: %v", page, err)
+		}
+
+		labelPath := filepath.Join(dir, name+".expected.json")
+		labels, err := os.ReadFile(labelPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read labels for %s: %v", name, err)
+		}
+
+		var expected []storage.Content
+		if err := json.Unmarshal(labels, &expected); err != nil {
+			return nil, fmt.Errorf("failed to parse labels %s: %v", labelPath, err)
+		}
+
+		samples = append(samples, Sample{
+			Name:     name,
+			Text:     string(text),
+			Expected: expected,
+		})
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no corpus pages found in %s", dir)
+	}
+
+	return samples, nil
+}
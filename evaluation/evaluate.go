@@ -0,0 +1,65 @@
+package evaluation
+
+import (
+	"cine-pulse/extractor"
+	"cine-pulse/model"
+	"context"
+	"log"
+	"time"
+)
+
+// ModelResult summarises how a single model performed over the corpus
+type ModelResult struct {
+	ModelName    string
+	Scores       Scores
+	Samples      int
+	Failures     int
+	TotalLatency time.Duration
+	InputTokens  int
+	OutputTokens int
+	Cost         float64 // estimated, in USD
+}
+
+// AverageLatency returns the mean time spent waiting for the model per sample
+func (r ModelResult) AverageLatency() time.Duration {
+	if r.Samples == 0 {
+		return 0
+	}
+	return r.TotalLatency / time.Duration(r.Samples)
+}
+
+// EvaluateModel runs every sample through the extraction pipeline with the given model
+func EvaluateModel(ctx context.Context, m model.ModelInterface, samples []Sample) ModelResult {
+	result := ModelResult{ModelName: m.GetModelName()}
+
+	for _, sample := range samples {
+		prompt := extractor.BuildPrompt() + sample.Text
+
+		start := time.Now()
+		response, err := m.GenerateText(ctx, prompt)
+		latency := time.Since(start)
+
+		result.Samples++
+		result.TotalLatency += latency
+		result.InputTokens += EstimateTokens(prompt)
+
+		if err != nil {
+			log.Printf("%s failed on %s: %v", result.ModelName, sample.Name, err)
+			result.Failures++
+			// Count the labels so a failing model is penalised on recall
+			result.Scores.Add(Score(sample.Expected, nil))
+			continue
+		}
+
+		result.OutputTokens += EstimateTokens(response)
+
+		predicted := extractor.ParseResponse(response)
+		result.Scores.Add(Score(sample.Expected, predicted))
+
+		log.Printf("%s extracted %d/%d items from %s in %s",
+			result.ModelName, len(predicted), len(sample.Expected), sample.Name, latency.Round(time.Millisecond))
+	}
+
+	result.Cost = EstimateCost(result.ModelName, result.InputTokens, result.OutputTokens)
+	return result
+}
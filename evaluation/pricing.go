@@ -0,0 +1,42 @@
+package evaluation
+
+import "strings"
+
+// Pricing is the list price of a model in USD per million tokens
+type Pricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// modelPricing holds published list prices for the models we commonly evaluate
+var modelPricing = map[string]Pricing{
+	"gemini-1.5-flash": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+	"gemini-1.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+	"gemini-1.0-pro":   {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+	"gpt-4o":           {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+	"gpt-4o-mini":      {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"gpt-4-turbo":      {InputPerMillion: 10.00, OutputPerMillion: 30.00},
+	"gpt-4":            {InputPerMillion: 30.00, OutputPerMillion: 60.00},
+	"gpt-3.5-turbo":    {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+}
+
+// EstimateTokens approximates the token count of a text (roughly four characters per token)
+func EstimateTokens(text string) int {
+	return (len([]rune(text)) + 3) / 4
+}
+
+// EstimateCost returns the estimated USD cost of a run, or 0 for models without known pricing
+func EstimateCost(modelName string, inputTokens, outputTokens int) float64 {
+	// Model names are reported as "provider:model"
+	if idx := strings.Index(modelName, ":"); idx != -1 {
+		modelName = modelName[idx+1:]
+	}
+
+	pricing, ok := modelPricing[modelName]
+	if !ok {
+		return 0
+	}
+
+	return float64(inputTokens)/1e6*pricing.InputPerMillion +
+		float64(outputTokens)/1e6*pricing.OutputPerMillion
+}
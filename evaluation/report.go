@@ -0,0 +1,32 @@
+package evaluation
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteReport prints a comparison table of model results
+func WriteReport(w io.Writer, results []ModelResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "MODEL\tTITLE P/R\tYEAR P/R\tTYPE P/R\tCATEGORY P/R\tAVG LATENCY\tEST. COST\tFAILURES")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t$%.4f\t%d/%d\n",
+			r.ModelName,
+			formatScore(r.Scores.Title),
+			formatScore(r.Scores.Year),
+			formatScore(r.Scores.Type),
+			formatScore(r.Scores.Category),
+			r.AverageLatency().Round(time.Millisecond),
+			r.Cost,
+			r.Failures, r.Samples)
+	}
+
+	return tw.Flush()
+}
+
+func formatScore(f FieldScore) string {
+	return fmt.Sprintf("%.2f/%.2f", f.Precision(), f.Recall())
+}
@@ -0,0 +1,118 @@
+package evaluation
+
+import (
+	"cine-pulse/storage"
+	"strings"
+)
+
+// FieldScore counts matches for a single field across one or more samples
+type FieldScore struct {
+	Correct   int // predicted values that agree with the label
+	Predicted int // predictions that carry a value for the field
+	Expected  int // labels that carry a value for the field
+}
+
+// Precision returns the share of predicted values that were correct
+func (f FieldScore) Precision() float64 {
+	if f.Predicted == 0 {
+		return 0
+	}
+	return float64(f.Correct) / float64(f.Predicted)
+}
+
+// Recall returns the share of expected values that were found
+func (f FieldScore) Recall() float64 {
+	if f.Expected == 0 {
+		return 0
+	}
+	return float64(f.Correct) / float64(f.Expected)
+}
+
+func (f *FieldScore) add(other FieldScore) {
+	f.Correct += other.Correct
+	f.Predicted += other.Predicted
+	f.Expected += other.Expected
+}
+
+// Scores holds per-field results for a model
+type Scores struct {
+	Title    FieldScore
+	Year     FieldScore
+	Type     FieldScore
+	Category FieldScore
+}
+
+// Add accumulates another set of scores, micro-averaging across samples
+func (s *Scores) Add(other Scores) {
+	s.Title.add(other.Title)
+	s.Year.add(other.Year)
+	s.Type.add(other.Type)
+	s.Category.add(other.Category)
+}
+
+// Score compares predicted content against the expected labels.
+// Items are paired by normalized title; the remaining fields are only
+// counted as correct on paired items whose values agree.
+func Score(expected, predicted []storage.Content) Scores {
+	var scores Scores
+
+	// Index expected items by title so each label can be matched at most once
+	remaining := make(map[string][]storage.Content)
+	for _, e := range expected {
+		key := normalizeTitle(e.Title)
+		remaining[key] = append(remaining[key], e)
+	}
+
+	scores.Title.Predicted = len(predicted)
+	scores.Title.Expected = len(expected)
+
+	for _, e := range expected {
+		if e.Year != nil {
+			scores.Year.Expected++
+		}
+		if e.Type != "" {
+			scores.Type.Expected++
+		}
+		if e.Category != "" {
+			scores.Category.Expected++
+		}
+	}
+
+	for _, p := range predicted {
+		if p.Year != nil {
+			scores.Year.Predicted++
+		}
+		if p.Type != "" {
+			scores.Type.Predicted++
+		}
+		if p.Category != "" {
+			scores.Category.Predicted++
+		}
+
+		key := normalizeTitle(p.Title)
+		candidates := remaining[key]
+		if len(candidates) == 0 {
+			continue
+		}
+		e := candidates[0]
+		remaining[key] = candidates[1:]
+
+		scores.Title.Correct++
+		if e.Year != nil && p.Year != nil && *e.Year == *p.Year {
+			scores.Year.Correct++
+		}
+		if e.Type != "" && strings.EqualFold(e.Type, p.Type) {
+			scores.Type.Correct++
+		}
+		if e.Category != "" && strings.EqualFold(e.Category, p.Category) {
+			scores.Category.Correct++
+		}
+	}
+
+	return scores
+}
+
+// normalizeTitle makes titles comparable regardless of case and spacing
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
@@ -0,0 +1,91 @@
+package evaluation
+
+import (
+	"cine-pulse/storage"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	expected := []storage.Content{
+		{Title: "Dune: Part Two", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie"},
+		{Title: "The Boys", Category: "TV Series", Type: "series"},
+		{Title: "Spirited Away", Year: &[]int{2001}[0], Category: "Anime", Type: "movie"},
+	}
+
+	predicted := []storage.Content{
+		{Title: "dune:  part two", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie"},
+		{Title: "The Boys", Category: "Foreign", Type: "series"},
+		{Title: "Made Up Movie", Year: &[]int{2020}[0], Category: "Hollywood", Type: "movie"},
+	}
+
+	scores := Score(expected, predicted)
+
+	// Two of three titles match in both directions
+	if scores.Title.Correct != 2 || scores.Title.Predicted != 3 || scores.Title.Expected != 3 {
+		t.Errorf("Unexpected title score: %+v", scores.Title)
+	}
+
+	// Only Dune has a correct year; Spirited Away was missed
+	if scores.Year.Correct != 1 || scores.Year.Predicted != 2 || scores.Year.Expected != 2 {
+		t.Errorf("Unexpected year score: %+v", scores.Year)
+	}
+
+	if scores.Type.Correct != 2 {
+		t.Errorf("Expected 2 correct types, got %d", scores.Type.Correct)
+	}
+
+	if scores.Category.Correct != 1 {
+		t.Errorf("Expected 1 correct category, got %d", scores.Category.Correct)
+	}
+
+	if p := scores.Title.Precision(); p < 0.66 || p > 0.67 {
+		t.Errorf("Expected title precision 0.67, got %.2f", p)
+	}
+
+	// Scoring nothing against the labels should give zero recall without dividing by zero
+	empty := Score(expected, nil)
+	if empty.Title.Recall() != 0 || empty.Title.Precision() != 0 {
+		t.Errorf("Expected zero scores for empty prediction, got %+v", empty.Title)
+	}
+}
+
+func TestLoadCorpus(t *testing.T) {
+	dir := t.TempDir()
+
+	page := "Latest uploads: Dune: Part Two (2024) Download Hollywood Movie"
+	labels := `[{"title":"Dune: Part Two","year":2024,"category":"Hollywood","extra_info":"Download Hollywood Movie","type":"movie"}]`
+
+	if err := os.WriteFile(filepath.Join(dir, "home.txt"), []byte(page), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "home.expected.json"), []byte(labels), 0644); err != nil {
+		t.Fatalf("Failed to write labels: %v", err)
+	}
+
+	samples, err := LoadCorpus(dir)
+	if err != nil {
+		t.Fatalf("Failed to load corpus: %v", err)
+	}
+
+	if len(samples) != 1 {
+		t.Fatalf("Expected 1 sample, got %d", len(samples))
+	}
+
+	if samples[0].Name != "home" || samples[0].Text != page {
+		t.Errorf("Unexpected sample: %+v", samples[0])
+	}
+
+	if len(samples[0].Expected) != 1 || samples[0].Expected[0].Title != "Dune: Part Two" {
+		t.Errorf("Unexpected labels: %+v", samples[0].Expected)
+	}
+
+	// A page without labels is an error rather than a silently skipped sample
+	if err := os.WriteFile(filepath.Join(dir, "orphan.txt"), []byte(page), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	if _, err := LoadCorpus(dir); err == nil {
+		t.Error("Expected error for page without labels")
+	}
+}
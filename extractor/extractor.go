@@ -0,0 +1,84 @@
+package extractor
+
+import (
+	"cine-pulse/model"
+	"cine-pulse/storage"
+	"context"
+	"fmt"
+	"log"
+)
+
+// DefaultProfile is the extraction profile used when a source does not name one
+const DefaultProfile = "default"
+
+// Profiles maps extraction profile names to extra instructions added to the
+// prompt for sources whose pages need them
+var Profiles = map[string]string{
+	DefaultProfile: "",
+	"series":       "The page only lists TV series updates: every item is a series, and episode or season details belong in extra_info.\n",
+	"movies":       "The page only lists movies: every item is a movie.\n",
+	"listing":      "The text is a list of page titles or links, one per line; derive each title from the line and ignore navigation entries.\n",
+}
+
+// Extract asks the given model to pull content items out of scraped page text
+func Extract(ctx context.Context, m model.ModelInterface, scrapedText string) ([]storage.Content, error) {
+	return ExtractWithProfile(ctx, m, DefaultProfile, scrapedText)
+}
+
+// ExtractWithProfile is like Extract but adds the instructions of the named extraction profile
+func ExtractWithProfile(ctx context.Context, m model.ModelInterface, profile string, scrapedText string) ([]storage.Content, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	hint, ok := Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown extraction profile %q", profile)
+	}
+
+	response, err := m.GenerateText(ctx, BuildPrompt()+hint+scrapedText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate text with %s: %w", m.GetModelName(), err)
+	}
+
+	return ParseResponse(response), nil
+}
+
+// ParseResponse converts a raw model response into content items
+func ParseResponse(response string) []storage.Content {
+	contents, err := parseContent(response)
+	if err != nil {
+		log.Printf("Error parsing model response: %v", err)
+		log.Printf("Raw response (first 100 chars): %s", truncateString(response, 100))
+		return nil
+	}
+
+	return contents
+}
+
+// BuildPrompt creates the prompt for extracting content
+func BuildPrompt() string {
+	return `You are a specialized JSON extraction tool. Extract movies and series from the provided text into a clean JSON array.
+
+Each entry must follow this exact schema:
+{
+  "title": string,
+  "year": number (for movies only, if available),
+  "category": string ("Hollywood", "Foreign", "Anime", "TV Series"),
+  "extra_info": string (e.g., "Download Hollywood Movie", "Episode 15–18 Added", "Complete"),
+  "type": string ("movie" or "series"),
+  "rating": number (optional, if available, on a scale of 1-10)
+}
+
+Critical rules:
+1. Output ONLY the raw JSON array with no explanations, no markdown code blocks, and no backticks
+2. For movies, extract year as an integer if available
+3. For series, ignore the year unless explicitly mentioned
+4. Preserve episode/season information in extra_info
+5. Ensure the output is valid parseable JSON with no additional text
+
+Examples of correct format:
+[{"title":"Movie 1","year":2023,"category":"Hollywood","extra_info":"Action","type":"movie"},{"title":"Series 1","category":"TV Series","extra_info":"Season 2","type":"series"}]
+
+YOUR ENTIRE RESPONSE MUST BE A VALID JSON ARRAY ONLY. DO NOT INCLUDE ANY OTHER TEXT.
+`
+}
@@ -0,0 +1,92 @@
+package extractor
+
+import (
+	"cine-pulse/lenientjson"
+	"cine-pulse/storage"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// leadingNumberPattern matches the number at the start of values like "7.5/10" or "2023 (HD)"
+var leadingNumberPattern = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)`)
+
+// contentFromObject converts a decoded JSON object into a content item.
+// Values are taken as-is; checking them is left to the validation package.
+func contentFromObject(obj map[string]any) storage.Content {
+	var content storage.Content
+
+	content.Title = stringField(obj, "title")
+	content.Category = stringField(obj, "category")
+	content.ExtraInfo = stringField(obj, "extra_info")
+	content.Type = stringField(obj, "type")
+
+	// Extract year and rating (optional)
+	if year, ok := numberField(obj, "year"); ok && year == math.Trunc(year) {
+		y := int(year)
+		content.Year = &y
+	}
+	if rating, ok := numberField(obj, "rating"); ok {
+		content.Rating = &rating
+	}
+
+	return content
+}
+
+// stringField returns a field as a string, rendering numbers as text
+func stringField(obj map[string]any, key string) string {
+	switch v := obj[key].(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// numberField returns a field as a number, accepting numeric strings
+func numberField(obj map[string]any, key string) (float64, bool) {
+	switch v := obj[key].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		m := leadingNumberPattern.FindStringSubmatch(v)
+		if m == nil {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(m[1], 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// parseContent extracts every complete content item from a model response
+func parseContent(response string) ([]storage.Content, error) {
+	objects := lenientjson.ExtractObjects(response)
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no JSON objects found in response")
+	}
+
+	var results []storage.Content
+	for _, obj := range objects {
+		results = append(results, contentFromObject(obj))
+	}
+
+	log.Printf("Parsed %d content items from %d objects", len(results), len(objects))
+	return results, nil
+}
+
+// truncateString truncates a string to a specified maximum length
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxLen], "") + "..."
+}
@@ -0,0 +1,58 @@
+package extractor
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestParseResponseGolden runs each saved model response in testdata through
+// ParseResponse and compares the result with the matching .golden.json file.
+// Run `go test ./extractor -update` to regenerate the golden files.
+func TestParseResponseGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.txt"))
+	if err != nil {
+		t.Fatalf("Failed to list testdata: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("No golden inputs found")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".txt")
+		t.Run(name, func(t *testing.T) {
+			response, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatalf("Failed to read input: %v", err)
+			}
+
+			got, err := json.MarshalIndent(ParseResponse(string(response)), "", "  ")
+			if err != nil {
+				t.Fatalf("Failed to marshal result: %v", err)
+			}
+			got = append(got, '\n')
+
+			goldenPath := filepath.Join("testdata", name+".golden.json")
+			if *update {
+				if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+
+			if string(got) != string(want) {
+				t.Errorf("Result differs from %s\n got: %s\nwant: %s", goldenPath, got, want)
+			}
+		})
+	}
+}
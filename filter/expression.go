@@ -0,0 +1,230 @@
+package filter
+
+import (
+	"cine-pulse/storage"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// condition is a single "field op value" comparison
+type condition struct {
+	field   string
+	op      string
+	text    string
+	number  float64
+	pattern *regexp.Regexp
+}
+
+// expression is a conjunction of conditions
+type expression []condition
+
+var numericFields = map[string]bool{"year": true, "rating": true}
+var textFields = map[string]bool{"category": true, "type": true, "title": true}
+
+// parseExpression compiles expressions such as
+//
+//	type = movie and year >= 2020
+//	category = 'TV Series'
+//	title ~ '(?i)marvel|avengers'
+//
+// Supported fields are category, type, title, year and rating. Text fields
+// support =, != and the regex operators ~ and !~; numeric fields support
+// =, !=, <, <=, > and >=. Values may be quoted with single or double quotes.
+func parseExpression(input string) (expression, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	var expr expression
+	for len(tokens) > 0 {
+		if len(tokens) < 3 {
+			return nil, fmt.Errorf("incomplete condition %q", strings.Join(tokens, " "))
+		}
+
+		cond := condition{
+			field: strings.ToLower(tokens[0]),
+			op:    tokens[1],
+		}
+		if cond.op == "==" {
+			cond.op = "="
+		}
+
+		// The value runs until the next "and"
+		end := 2
+		for end < len(tokens) && !strings.EqualFold(tokens[end], "and") {
+			end++
+		}
+		if end == 2 {
+			return nil, fmt.Errorf("missing value for %s %s", cond.field, cond.op)
+		}
+		value := strings.Join(tokens[2:end], " ")
+
+		if err := cond.compile(value); err != nil {
+			return nil, err
+		}
+		expr = append(expr, cond)
+
+		tokens = tokens[end:]
+		if len(tokens) > 0 {
+			tokens = tokens[1:] // drop "and"
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("expression ends with \"and\"")
+			}
+		}
+	}
+
+	return expr, nil
+}
+
+// compile validates the operator for the field and parses the value
+func (c *condition) compile(value string) error {
+	switch {
+	case numericFields[c.field]:
+		switch c.op {
+		case "=", "!=", "<", "<=", ">", ">=":
+		default:
+			return fmt.Errorf("operator %s not supported for %s", c.op, c.field)
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s needs a number, got %q", c.field, value)
+		}
+		c.number = n
+
+	case textFields[c.field]:
+		switch c.op {
+		case "=", "!=":
+			c.text = value
+		case "~", "!~":
+			pattern, err := regexp.Compile(value)
+			if err != nil {
+				return fmt.Errorf("invalid regex for %s: %v", c.field, err)
+			}
+			c.pattern = pattern
+		default:
+			return fmt.Errorf("operator %s not supported for %s", c.op, c.field)
+		}
+
+	default:
+		return fmt.Errorf("unknown field %q", c.field)
+	}
+
+	return nil
+}
+
+// matches reports whether every condition holds for the content.
+// Conditions on a missing year or rating never hold.
+func (e expression) matches(content storage.Content) bool {
+	for _, c := range e {
+		if !c.matches(content) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) matches(content storage.Content) bool {
+	if numericFields[c.field] {
+		var value float64
+		switch c.field {
+		case "year":
+			if content.Year == nil {
+				return false
+			}
+			value = float64(*content.Year)
+		case "rating":
+			if content.Rating == nil {
+				return false
+			}
+			value = *content.Rating
+		}
+
+		switch c.op {
+		case "=":
+			return value == c.number
+		case "!=":
+			return value != c.number
+		case "<":
+			return value < c.number
+		case "<=":
+			return value <= c.number
+		case ">":
+			return value > c.number
+		case ">=":
+			return value >= c.number
+		}
+		return false
+	}
+
+	var value string
+	switch c.field {
+	case "category":
+		value = content.Category
+	case "type":
+		value = content.Type
+	case "title":
+		value = content.Title
+	}
+
+	switch c.op {
+	case "=":
+		return strings.EqualFold(value, c.text)
+	case "!=":
+		return !strings.EqualFold(value, c.text)
+	case "~":
+		return c.pattern.MatchString(value)
+	case "!~":
+		return !c.pattern.MatchString(value)
+	}
+	return false
+}
+
+// tokenize splits an expression into words, operators and quoted strings
+func tokenize(input string) ([]string, error) {
+	var tokens []string
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote in %q", input)
+			}
+			tokens = append(tokens, string(runes[i+1:end]))
+			i = end + 1
+
+		case strings.ContainsRune("=!<>~", r):
+			end := i + 1
+			for end < len(runes) && strings.ContainsRune("=~", runes[end]) && end-i < 2 {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("=!<>~", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,167 @@
+package filter
+
+import (
+	"cine-pulse/storage"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Rule actions
+const (
+	ActionInclude = "include"
+	ActionExclude = "exclude"
+)
+
+// NotIncluded is the rule name reported for items dropped because no include rule matched them
+const NotIncluded = "(no include rule matched)"
+
+// Rule is a single include or exclude rule as written in configuration
+type Rule struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	When   string `json:"when"`
+
+	expr expression
+}
+
+// RuleSet decides which content is kept.
+// Items matching any exclude rule are dropped. If there are include rules,
+// items must also match at least one of them to be kept.
+type RuleSet struct {
+	rules []*Rule
+}
+
+// RuleResult reports how many items a rule matched during one Apply call
+type RuleResult struct {
+	Name    string
+	Action  string
+	Matched int
+}
+
+// Exclusion records a dropped item and the rule responsible
+type Exclusion struct {
+	Content storage.Content
+	Rule    string
+}
+
+// Report summarises the outcome of applying a rule set
+type Report struct {
+	Rules    []RuleResult
+	Excluded []Exclusion
+	Kept     int
+}
+
+// DefaultRules returns the rules used when none are configured
+func DefaultRules() *RuleSet {
+	rules, err := NewRuleSet([]Rule{
+		{Name: "no-korean", Action: ActionExclude, When: "category = Korean"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return rules
+}
+
+// NewRuleSet compiles the given rules
+func NewRuleSet(rules []Rule) (*RuleSet, error) {
+	rs := &RuleSet{}
+	for i := range rules {
+		rule := rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if rule.Action != ActionInclude && rule.Action != ActionExclude {
+			return nil, fmt.Errorf("rule %s: action must be %q or %q", rule.Name, ActionInclude, ActionExclude)
+		}
+
+		expr, err := parseExpression(rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+		rule.expr = expr
+
+		rs.rules = append(rs.rules, &rule)
+	}
+	return rs, nil
+}
+
+// LoadRules parses a JSON array of rules
+func LoadRules(data []byte) (*RuleSet, error) {
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse filter rules: %v", err)
+	}
+	return NewRuleSet(rules)
+}
+
+// LoadRulesFromEnv loads rules from the file named by FILTER_RULES_FILE or the
+// inline JSON in FILTER_RULES, falling back to the default rules
+func LoadRulesFromEnv() (*RuleSet, error) {
+	if path := os.Getenv("FILTER_RULES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read filter rules file: %v", err)
+		}
+		return LoadRules(data)
+	}
+
+	if rules := os.Getenv("FILTER_RULES"); rules != "" {
+		return LoadRules([]byte(rules))
+	}
+
+	return DefaultRules(), nil
+}
+
+// Rules returns the configured rules in order
+func (rs *RuleSet) Rules() []Rule {
+	rules := make([]Rule, len(rs.rules))
+	for i, rule := range rs.rules {
+		rules[i] = *rule
+	}
+	return rules
+}
+
+// Apply filters the items and reports what each rule did
+func (rs *RuleSet) Apply(items []storage.Content) ([]storage.Content, Report) {
+	report := Report{Rules: make([]RuleResult, len(rs.rules))}
+	hasInclude := false
+	for i, rule := range rs.rules {
+		report.Rules[i] = RuleResult{Name: rule.Name, Action: rule.Action}
+		if rule.Action == ActionInclude {
+			hasInclude = true
+		}
+	}
+
+	var kept []storage.Content
+	for _, item := range items {
+		excludedBy := ""
+		included := !hasInclude
+
+		for i, rule := range rs.rules {
+			if !rule.expr.matches(item) {
+				continue
+			}
+			report.Rules[i].Matched++
+
+			if rule.Action == ActionExclude && excludedBy == "" {
+				excludedBy = rule.Name
+			}
+			if rule.Action == ActionInclude {
+				included = true
+			}
+		}
+
+		switch {
+		case excludedBy != "":
+			report.Excluded = append(report.Excluded, Exclusion{Content: item, Rule: excludedBy})
+		case !included:
+			report.Excluded = append(report.Excluded, Exclusion{Content: item, Rule: NotIncluded})
+		default:
+			kept = append(kept, item)
+		}
+	}
+
+	report.Kept = len(kept)
+	return kept, report
+}
@@ -0,0 +1,131 @@
+package filter
+
+import (
+	"cine-pulse/storage"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testItems() []storage.Content {
+	return []storage.Content{
+		{Title: "Queen of Tears", Category: "Korean", Type: "series"},
+		{Title: "Frieren", Category: "Anime", Type: "series", Rating: &[]float64{9.1}[0]},
+		{Title: "Civil War", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{7.1}[0]},
+		{Title: "Old Classic", Year: &[]int{1999}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{5.2}[0]},
+		{Title: "Avengers: Endgame", Year: &[]int{2019}[0], Category: "Hollywood", Type: "movie"},
+	}
+}
+
+func titles(items []storage.Content) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.Title)
+	}
+	return result
+}
+
+func TestDefaultRules(t *testing.T) {
+	kept, report := DefaultRules().Apply(testItems())
+
+	if len(kept) != 4 {
+		t.Fatalf("Expected 4 items kept, got %v", titles(kept))
+	}
+
+	if len(report.Excluded) != 1 || report.Excluded[0].Rule != "no-korean" {
+		t.Errorf("Expected Korean item excluded by no-korean, got %+v", report.Excluded)
+	}
+
+	if report.Rules[0].Matched != 1 {
+		t.Errorf("Expected no-korean to match once, got %d", report.Rules[0].Matched)
+	}
+}
+
+func TestRuleSetApply(t *testing.T) {
+	rules, err := LoadRules([]byte(`[
+		{"name": "no-anime", "action": "exclude", "when": "category = anime"},
+		{"name": "low-rated", "action": "exclude", "when": "rating < 6"},
+		{"name": "recent-movies", "action": "include", "when": "type = movie and year > 2020"},
+		{"name": "marvel", "action": "include", "when": "title ~ '(?i)avengers|marvel'"}
+	]`))
+	if err != nil {
+		t.Fatalf("Failed to load rules: %v", err)
+	}
+
+	kept, report := rules.Apply(testItems())
+
+	got := titles(kept)
+	if len(got) != 2 || got[0] != "Civil War" || got[1] != "Avengers: Endgame" {
+		t.Fatalf("Expected Civil War and Avengers: Endgame, got %v", got)
+	}
+
+	reasons := make(map[string]string)
+	for _, exclusion := range report.Excluded {
+		reasons[exclusion.Content.Title] = exclusion.Rule
+	}
+
+	expected := map[string]string{
+		"Queen of Tears": NotIncluded,
+		"Frieren":        "no-anime",
+		"Old Classic":    "low-rated",
+	}
+	for title, rule := range expected {
+		if reasons[title] != rule {
+			t.Errorf("Expected %s to be excluded by %s, got %q", title, rule, reasons[title])
+		}
+	}
+
+	if report.Kept != 2 {
+		t.Errorf("Expected report to count 2 kept items, got %d", report.Kept)
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"year >= recent",
+		"genre = drama",
+		"title < 5",
+		"rating ~ 5",
+		"title ~ '('",
+		"type = movie and",
+		"category = 'TV Series",
+	}
+
+	for _, input := range invalid {
+		if _, err := parseExpression(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+
+	// Quoted values may contain spaces
+	expr, err := parseExpression(`category = "TV Series" and rating >= 6`)
+	if err != nil {
+		t.Fatalf("Failed to parse expression: %v", err)
+	}
+	if len(expr) != 2 || expr[0].text != "TV Series" || expr[1].number != 6 {
+		t.Errorf("Unexpected expression: %+v", expr)
+	}
+}
+
+func TestLoadRulesFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "movies-only", "action": "include", "when": "type = movie"}]`), 0644); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+
+	t.Setenv("FILTER_RULES_FILE", path)
+	rules, err := LoadRulesFromEnv()
+	if err != nil {
+		t.Fatalf("Failed to load rules from file: %v", err)
+	}
+	if len(rules.Rules()) != 1 || rules.Rules()[0].Name != "movies-only" {
+		t.Errorf("Unexpected rules: %+v", rules.Rules())
+	}
+
+	t.Setenv("FILTER_RULES_FILE", "")
+	t.Setenv("FILTER_RULES", `[{"action": "drop", "when": "type = movie"}]`)
+	if _, err := LoadRulesFromEnv(); err == nil {
+		t.Error("Expected error for invalid action")
+	}
+}
@@ -0,0 +1,102 @@
+package lenientjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var fuzzSeeds = []string{
+	`[{"title":"Dune: Part Two","year":2024,"category":"Hollywood","extra_info":"Download Hollywood Movie","type":"movie"}]`,
+	`{"title": "The \"Real\" Story", "rating": 7.5}`,
+	"```json\n[{title: 'A', type: 'movie',},]\n```",
+	`[{"title":"A"},{"title":"B","year":20`,
+	`{"a": [1, 2, {"b": null}], "c": "é🎬"}`,
+	`[1e10, -0.5, 0, "x"]`,
+	"{/* comment */ a: 1 // trailing\n}",
+	`{"extra_info": Episode 15–18 Added}`,
+	`[[[]]]`,
+	`{"\ud800": "\udc00"}`,
+	`true`,
+	`false`,
+	`null`,
+	`null `,
+	` 42`,
+	`[true, null]`,
+	strings.Repeat("[", 2000),
+	"[" + strings.Repeat("{a:", 2000),
+	strings.Repeat("[", MaxDepth+1) + strings.Repeat("]", MaxDepth+1),
+	`[{"title":"A"},{"title":"B","extra_info":"Episode`,
+}
+
+// FuzzParse checks that the parser never panics and that it agrees with
+// encoding/json on every input that is already strict JSON
+func FuzzParse(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, err := Parse(input)
+		ExtractObjects(input)
+
+		if !json.Valid([]byte(input)) {
+			return
+		}
+
+		if errors.Is(err, ErrTooDeep) {
+			return
+		}
+		if err != nil {
+			t.Fatalf("Parse failed on valid JSON %q: %v", input, err)
+		}
+
+		var want any
+		dec := json.NewDecoder(bytes.NewReader([]byte(input)))
+		dec.UseNumber()
+		if err := dec.Decode(&want); err != nil {
+			t.Fatalf("encoding/json failed on valid JSON %q: %v", input, err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Mismatch on %q\n got: %#v\nwant: %#v", input, got, want)
+		}
+	})
+}
+
+// FuzzExtractObjectsTruncation checks that cutting a valid array short never
+// yields objects that were not in the original
+func FuzzExtractObjectsTruncation(f *testing.F) {
+	f.Add(`[{"title":"A","year":2020},{"title":"B"},{"title":"C","extra_info":"x, y"}]`, 30)
+	f.Add(`[{"a":"}"},{"b":"{"}]`, 10)
+
+	f.Fuzz(func(t *testing.T, input string, cut int) {
+		var full []map[string]any
+		dec := json.NewDecoder(bytes.NewReader([]byte(input)))
+		dec.UseNumber()
+		if err := dec.Decode(&full); err != nil || cut < 0 || cut > len(input) {
+			return
+		}
+
+		partial, err := Parse(input[:cut])
+		if err != nil && !errors.Is(err, ErrTruncated) {
+			return
+		}
+		arr, ok := partial.([]any)
+		if !ok {
+			return
+		}
+
+		if len(arr) > len(full) {
+			t.Fatalf("Truncated input produced %d elements, original has %d", len(arr), len(full))
+		}
+		for i, elem := range arr {
+			if !reflect.DeepEqual(elem, any(full[i])) {
+				t.Fatalf("Element %d differs after truncation\n got: %#v\nwant: %#v", i, elem, full[i])
+			}
+		}
+	})
+}
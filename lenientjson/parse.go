@@ -0,0 +1,537 @@
+// Package lenientjson parses the loosely formatted JSON that language models
+// tend to produce. On top of strict JSON it accepts a JSON5-style superset:
+// single-quoted strings, unquoted keys and values, trailing or missing commas,
+// comments, and input that was cut off part way through.
+package lenientjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrTruncated is returned when the input ends before the value is complete.
+// The partial value returned alongside it holds every element that was complete.
+var ErrTruncated = errors.New("unexpected end of input")
+
+// ErrTooDeep is returned when arrays and objects are nested more than
+// MaxDepth levels deep
+var ErrTooDeep = errors.New("nesting too deep")
+
+// MaxDepth is the deepest nesting of arrays and objects that is parsed.
+// Model output never comes close; the limit keeps a bad reply from
+// exhausting the stack.
+const MaxDepth = 1000
+
+// SyntaxError describes input that cannot be parsed even leniently
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Offset, e.Msg)
+}
+
+// Parse decodes a single lenient JSON value from input.
+// Objects decode to map[string]any, arrays to []any, numbers to json.Number,
+// and strings, booleans and null to their Go equivalents. Trailing text after
+// the value is ignored.
+func Parse(input string) (any, error) {
+	p := &parser{input: input}
+	p.skipSpace()
+	return p.parseValue()
+}
+
+// ExtractObjects locates the first array or object in free-form text such as a
+// model response and returns every complete object in it. Surrounding prose,
+// markdown code fences, malformed elements and a truncated tail are skipped.
+func ExtractObjects(input string) []map[string]any {
+	for start := 0; start < len(input); {
+		c := input[start]
+		if c != '[' && c != '{' {
+			start++
+			continue
+		}
+
+		p := &parser{input: input, pos: start}
+		var objects []map[string]any
+		if c == '[' {
+			objects = p.collectArrayObjects()
+		} else if value, err := p.parseObject(); err == nil {
+			objects = []map[string]any{value}
+		}
+
+		if len(objects) > 0 {
+			return objects
+		}
+
+		// Carry on after the span that was read, so nothing is parsed twice
+		if p.pos > start {
+			start = p.pos
+		} else {
+			start++
+		}
+	}
+
+	return nil
+}
+
+type parser struct {
+	input string
+	pos   int
+	depth int
+}
+
+// enter counts one more level of nesting, failing past MaxDepth
+func (p *parser) enter() error {
+	if p.depth >= MaxDepth {
+		return ErrTooDeep
+	}
+	p.depth++
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// skipSpace skips whitespace and // or /* */ comments
+func (p *parser) skipSpace() {
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		switch {
+		case unicode.IsSpace(r) || r == '\uFEFF':
+			p.pos += size
+		case strings.HasPrefix(p.input[p.pos:], "//"):
+			end := strings.IndexByte(p.input[p.pos:], '\n')
+			if end == -1 {
+				p.pos = len(p.input)
+			} else {
+				p.pos += end + 1
+			}
+		case strings.HasPrefix(p.input[p.pos:], "/*"):
+			end := strings.Index(p.input[p.pos+2:], "*/")
+			if end == -1 {
+				p.pos = len(p.input)
+			} else {
+				p.pos += end + 4
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) parseValue() (any, error) {
+	if p.eof() {
+		return nil, ErrTruncated
+	}
+
+	switch c := p.peek(); {
+	case c == '{':
+		obj, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case c == '[':
+		return p.parseArray()
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		if num, ok := p.parseNumber(); ok {
+			return num, nil
+		}
+		return p.parseBareword()
+	case c == ',' || c == ':' || c == '}' || c == ']':
+		return nil, p.errorf("unexpected %q", c)
+	default:
+		return p.parseBareword()
+	}
+}
+
+// parseObject parses an object. If the input is truncated the error is
+// ErrTruncated and no partial object is returned, since a half-read object
+// cannot be trusted.
+func (p *parser) parseObject() (map[string]any, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	p.pos++ // consume '{'
+	obj := make(map[string]any)
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, ErrTruncated
+		}
+
+		switch p.peek() {
+		case '}':
+			p.pos++
+			return obj, nil
+		case ',':
+			// Tolerate leading, doubled and trailing commas
+			p.pos++
+			continue
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		if p.eof() {
+			return nil, ErrTruncated
+		}
+		if p.peek() != ':' && p.peek() != '=' {
+			return nil, p.errorf("expected ':' after key %q", key)
+		}
+		p.pos++
+
+		p.skipSpace()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = value
+	}
+}
+
+// parseArray parses an array. If the input is truncated, the complete
+// elements read so far are returned together with ErrTruncated.
+func (p *parser) parseArray() ([]any, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	p.pos++ // consume '['
+	arr := []any{}
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return arr, ErrTruncated
+		}
+
+		switch p.peek() {
+		case ']':
+			p.pos++
+			return arr, nil
+		case ',':
+			p.pos++
+			continue
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			if errors.Is(err, ErrTruncated) {
+				return arr, err
+			}
+			return nil, err
+		}
+		arr = append(arr, value)
+	}
+}
+
+// collectArrayObjects walks an array and keeps every complete object element,
+// including those of arrays nested in it, as in [[{...}]]. Unlike parseArray
+// it recovers from malformed elements by resuming at the next object.
+func (p *parser) collectArrayObjects() []map[string]any {
+	p.pos++ // consume '['
+	var objects []map[string]any
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return objects
+		}
+
+		switch p.peek() {
+		case ']':
+			return objects
+		case ',':
+			p.pos++
+			continue
+		}
+
+		start := p.pos
+		value, err := p.parseValue()
+		if err == nil {
+			objects = appendObjects(objects, value)
+			continue
+		}
+		if errors.Is(err, ErrTruncated) {
+			// A cut-off nested array still holds its complete objects
+			return appendObjects(objects, value)
+		}
+
+		// Skip the broken element and carry on with the next object after
+		// where parsing stopped, so nested objects are not parsed again
+		resume := start + 1
+		if p.pos > resume {
+			resume = p.pos
+		}
+		next := strings.IndexByte(p.input[resume:], '{')
+		if next == -1 {
+			return objects
+		}
+		p.pos = resume + next
+	}
+}
+
+// appendObjects appends value if it is an object, or the objects in it if it
+// is an array
+func appendObjects(objects []map[string]any, value any) []map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		objects = append(objects, v)
+	case []any:
+		for _, elem := range v {
+			objects = appendObjects(objects, elem)
+		}
+	}
+	return objects
+}
+
+// parseKey parses a quoted or unquoted object key
+func (p *parser) parseKey() (string, error) {
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.parseString()
+	}
+
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$' || r == '-') {
+			break
+		}
+		p.pos += size
+	}
+
+	if p.pos == start {
+		if p.eof() {
+			return "", ErrTruncated
+		}
+		return "", p.errorf("unexpected %q in object key", p.peek())
+	}
+	return p.input[start:p.pos], nil
+}
+
+// parseString parses a single- or double-quoted string
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", ErrTruncated
+		}
+
+		c := p.input[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c == '\\':
+			if p.pos+1 >= len(p.input) {
+				return "", ErrTruncated
+			}
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		case c == '\n' || c == '\r':
+			// Raw newlines are not valid JSON but models emit them; keep them
+			sb.WriteByte(c)
+			p.pos++
+		default:
+			r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+// parseEscape decodes the escape sequence at the current position
+func (p *parser) parseEscape(sb *strings.Builder) error {
+	esc := p.input[p.pos+1]
+	p.pos += 2
+
+	switch esc {
+	case 'n':
+		sb.WriteByte('\n')
+	case 't':
+		sb.WriteByte('\t')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case '\n':
+		// Line continuation
+	case 'u':
+		r, ok := p.parseHex4()
+		if !ok {
+			if p.pos+4 > len(p.input) {
+				return ErrTruncated
+			}
+			return p.errorf("invalid unicode escape")
+		}
+		if utf16IsHighSurrogate(r) && strings.HasPrefix(p.input[p.pos:], `\u`) {
+			save := p.pos
+			p.pos += 2
+			if low, ok := p.parseHex4(); ok && utf16IsLowSurrogate(low) {
+				r = (r-0xD800)<<10 + (low - 0xDC00) + 0x10000
+			} else {
+				// Not a valid pair; leave the second escape for the next iteration
+				p.pos = save
+			}
+		}
+		// Lone surrogates are written as utf8.RuneError, matching encoding/json
+		sb.WriteRune(r)
+	default:
+		// Covers \" \' \\ \/ and keeps unknown escapes as the literal character
+		sb.WriteByte(esc)
+	}
+	return nil
+}
+
+func (p *parser) parseHex4() (rune, bool) {
+	if p.pos+4 > len(p.input) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range p.input[p.pos : p.pos+4] {
+		switch {
+		case c >= '0' && c <= '9':
+			r = r<<4 | (c - '0')
+		case c >= 'a' && c <= 'f':
+			r = r<<4 | (c - 'a' + 10)
+		case c >= 'A' && c <= 'F':
+			r = r<<4 | (c - 'A' + 10)
+		default:
+			return 0, false
+		}
+	}
+	p.pos += 4
+	return r, true
+}
+
+func utf16IsHighSurrogate(r rune) bool { return r >= 0xD800 && r < 0xDC00 }
+func utf16IsLowSurrogate(r rune) bool  { return r >= 0xDC00 && r < 0xE000 }
+
+// parseNumber parses a number, returning false if the text at the current
+// position is not purely numeric (e.g. "2-Part Special")
+func (p *parser) parseNumber() (json.Number, bool) {
+	start := p.pos
+	i := p.pos
+
+	if i < len(p.input) && (p.input[i] == '-' || p.input[i] == '+') {
+		i++
+	}
+	digits := 0
+	for i < len(p.input) && p.input[i] >= '0' && p.input[i] <= '9' {
+		i++
+		digits++
+	}
+	if i < len(p.input) && p.input[i] == '.' {
+		i++
+		for i < len(p.input) && p.input[i] >= '0' && p.input[i] <= '9' {
+			i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return "", false
+	}
+	if i < len(p.input) && (p.input[i] == 'e' || p.input[i] == 'E') {
+		j := i + 1
+		if j < len(p.input) && (p.input[j] == '-' || p.input[j] == '+') {
+			j++
+		}
+		if j < len(p.input) && p.input[j] >= '0' && p.input[j] <= '9' {
+			for j < len(p.input) && p.input[j] >= '0' && p.input[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+
+	// A number must be followed by a delimiter, otherwise it is part of a bare word
+	rest := strings.TrimLeft(p.input[i:], " \t")
+	if rest != "" && !strings.ContainsAny(rest[:1], ",}]\r\n/") {
+		return "", false
+	}
+
+	text := p.input[start:i]
+	text = strings.TrimPrefix(text, "+")
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "-.") {
+		text = strings.Replace(text, ".", "0.", 1)
+	}
+	if strings.HasSuffix(text, ".") {
+		text += "0"
+	}
+	if text == "-" {
+		return "", false
+	}
+
+	// Normalise through encoding/json so callers always receive a valid number literal
+	var check json.Number
+	if err := json.Unmarshal([]byte(text), &check); err != nil {
+		return "", false
+	}
+
+	p.pos = i
+	return json.Number(text), true
+}
+
+// parseBareword parses an unquoted literal: true, false, null, or text that
+// runs to the next delimiter, which is treated as a string
+func (p *parser) parseBareword() (any, error) {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == ',' || c == '}' || c == ']' || c == '\n' || c == '\r' {
+			break
+		}
+		p.pos++
+	}
+
+	word := strings.TrimSpace(p.input[start:p.pos])
+	if word == "" {
+		return nil, p.errorf("expected value")
+	}
+
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "None", "undefined":
+		return nil, nil
+	}
+	if p.eof() {
+		// Text running to the end, such as "tr" or "Episode 1", may have been cut short
+		return nil, ErrTruncated
+	}
+	return word, nil
+}
@@ -0,0 +1,232 @@
+package lenientjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  any
+	}{
+		{
+			name:  "strict JSON",
+			input: `{"title":"Dune","year":2024,"rating":8.5,"tags":["a","b"],"ok":true,"none":null}`,
+			want: map[string]any{
+				"title": "Dune", "year": json.Number("2024"), "rating": json.Number("8.5"),
+				"tags": []any{"a", "b"}, "ok": true, "none": nil,
+			},
+		},
+		{
+			name:  "single quotes and unquoted keys",
+			input: `{title: 'The Boys', type: 'series'}`,
+			want:  map[string]any{"title": "The Boys", "type": "series"},
+		},
+		{
+			name:  "trailing commas",
+			input: `[{"a": 1,}, {"a": 2},]`,
+			want:  []any{map[string]any{"a": json.Number("1")}, map[string]any{"a": json.Number("2")}},
+		},
+		{
+			name:  "missing commas",
+			input: "{\"a\": 1\n\"b\": 2}",
+			want:  map[string]any{"a": json.Number("1"), "b": json.Number("2")},
+		},
+		{
+			name:  "escaped quotes stay in the string",
+			input: `{"title": "The \"Real\" Story"}`,
+			want:  map[string]any{"title": `The "Real" Story`},
+		},
+		{
+			name:  "unicode escapes and surrogate pairs",
+			input: `{"title": "Amélie 🎬"}`,
+			want:  map[string]any{"title": "Amélie 🎬"},
+		},
+		{
+			name:  "unquoted string values",
+			input: "{category: Hollywood, extra_info: Episode 15–18 Added\n}",
+			want:  map[string]any{"category": "Hollywood", "extra_info": "Episode 15–18 Added"},
+		},
+		{
+			name:  "bare words that start with digits are strings",
+			input: `{"extra_info": 2 Episodes Added}`,
+			want:  map[string]any{"extra_info": "2 Episodes Added"},
+		},
+		{
+			name:  "number forms",
+			input: `[-1, +2, .5, 3., 1e3]`,
+			want:  []any{json.Number("-1"), json.Number("2"), json.Number("0.5"), json.Number("3.0"), json.Number("1e3")},
+		},
+		{
+			name:  "comments",
+			input: "[1, // first\n /* second */ 2]",
+			want:  []any{json.Number("1"), json.Number("2")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q)\n got: %#v\nwant: %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	got, err := Parse(`[{"a": 1}, {"a": 2}, {"a": `)
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("Expected ErrTruncated, got %v", err)
+	}
+
+	// Complete elements are still returned
+	arr, ok := got.([]any)
+	if !ok || len(arr) != 2 {
+		t.Fatalf("Expected 2 complete elements, got %#v", got)
+	}
+
+	if _, err := Parse(`{"title": "Du`); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for cut-off string, got %v", err)
+	}
+
+	// A literal is only cut off when it is a strict prefix
+	for _, input := range []string{"tr", "nul", "fals"} {
+		if _, err := Parse(input); !errors.Is(err, ErrTruncated) {
+			t.Errorf("Expected ErrTruncated for %q, got %v", input, err)
+		}
+	}
+	got, err = Parse(`[true, fa`)
+	if arr, ok := got.([]any); !errors.Is(err, ErrTruncated) || !ok || len(arr) != 1 || arr[0] != true {
+		t.Errorf("Expected [true] with ErrTruncated, got %#v, %v", got, err)
+	}
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	_, err := Parse(`{"a" 1}`)
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected SyntaxError, got %v", err)
+	}
+	if syntaxErr.Offset != 5 {
+		t.Errorf("Expected error at offset 5, got %d", syntaxErr.Offset)
+	}
+}
+
+func TestParseTooDeep(t *testing.T) {
+	if _, err := Parse(strings.Repeat("[", MaxDepth) + strings.Repeat("]", MaxDepth)); err != nil {
+		t.Errorf("Expected %d levels to parse, got %v", MaxDepth, err)
+	}
+	for _, input := range []string{strings.Repeat("[", MaxDepth+1), strings.Repeat("{a:", MaxDepth+1)} {
+		if _, err := Parse(input); !errors.Is(err, ErrTooDeep) {
+			t.Errorf("Expected ErrTooDeep for %.10q..., got %v", input, err)
+		}
+	}
+}
+
+func TestExtractObjects(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		titles []string
+	}{
+		{
+			name:   "code fence with prose",
+			input:  "Here is the data:\n```json\n[{\"title\":\"A\"},{\"title\":\"B\"}]\n```\nLet me know!",
+			titles: []string{"A", "B"},
+		},
+		{
+			name:   "truncated array keeps complete objects",
+			input:  `[{"title":"A"},{"title":"B"},{"title":"C","year":20`,
+			titles: []string{"A", "B"},
+		},
+		{
+			name:   "malformed element is skipped",
+			input:  `[{"title":"A"},{"title" "broken"},{"title":"C"}]`,
+			titles: []string{"A", "C"},
+		},
+		{
+			name:   "single object",
+			input:  `Result: {"title":"Solo"}`,
+			titles: []string{"Solo"},
+		},
+		{
+			name:   "bracketed prose before the array",
+			input:  `[Note] Extracted: [{"title":"A"}]`,
+			titles: []string{"A"},
+		},
+		{
+			name:   "nested array",
+			input:  `[[{"title":"A"},{"title":"B"}]]`,
+			titles: []string{"A", "B"},
+		},
+		{
+			name:   "broken object before the array",
+			input:  `{found 2 items} [{"title":"A"}]`,
+			titles: []string{"A"},
+		},
+		{
+			name:   "too deep element is skipped",
+			input:  "[" + strings.Repeat("{a:", MaxDepth+1) + `1, {"title":"A"}]`,
+			titles: []string{"A"},
+		},
+		{
+			name:   "no JSON",
+			input:  "Sorry, I could not find any movies.",
+			titles: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := ExtractObjects(tt.input)
+
+			var titles []string
+			for _, obj := range objects {
+				titles = append(titles, obj["title"].(string))
+			}
+
+			if strings.Join(titles, ",") != strings.Join(tt.titles, ",") {
+				t.Errorf("Expected titles %v, got %v", tt.titles, titles)
+			}
+		})
+	}
+}
+
+// pathologicalInputs are model replies that used to take quadratic time
+var pathologicalInputs = map[string]string{
+	"open brackets":        strings.Repeat("[", 16000),
+	"open objects":         "[" + strings.Repeat("{a:", 16000),
+	"truncated objects":    strings.Repeat(`{"title":"A",`, 4000),
+	"many broken elements": "[" + strings.Repeat(`{"title" "x"},`, 4000),
+	"nested prose":         strings.Repeat("[note ", 8000),
+}
+
+func TestExtractObjectsPathological(t *testing.T) {
+	for name, input := range pathologicalInputs {
+		start := time.Now()
+		ExtractObjects(input)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: took %s", name, elapsed)
+		}
+	}
+}
+
+func BenchmarkExtractObjects(b *testing.B) {
+	for name, input := range pathologicalInputs {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ExtractObjects(input)
+			}
+		})
+	}
+}
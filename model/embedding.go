@@ -0,0 +1,57 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// EmbeddingModel defines the contract for models that turn text into vectors
+type EmbeddingModel interface {
+	// Embed returns one vector per input text, in the same order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+
+	// GetModelName returns the name/identifier of the model
+	GetModelName() string
+
+	// Close cleans up any resources used by the model
+	Close() error
+}
+
+// EmbeddingFactory is a factory interface for creating embedding models
+type EmbeddingFactory interface {
+	CreateEmbeddingModel(config *ModelConfig) (EmbeddingModel, error)
+}
+
+// GetEmbeddingConfigFromEnv loads the embedding provider configuration from environment variables.
+// An empty model type means embeddings are disabled.
+func GetEmbeddingConfigFromEnv() (ModelType, *ModelConfig) {
+	provider := ModelType(os.Getenv("EMBEDDING_PROVIDER"))
+	config := &ModelConfig{
+		ModelName: os.Getenv("EMBEDDING_MODEL"),
+	}
+
+	switch provider {
+	case ModelTypeOpenAI:
+		config.APIKey = os.Getenv("OPENAI_API_KEY")
+	case ModelTypeGemini:
+		config.APIKey = os.Getenv("GEMINI_API_KEY")
+	case ModelTypeLocal:
+		config.BaseURL = os.Getenv("LOCAL_EMBEDDING_URL")
+	}
+
+	return provider, config
+}
+
+// validateEmbeddings checks that a provider returned exactly one vector per input
+func validateEmbeddings(vectors [][]float32, inputs int) error {
+	if len(vectors) != inputs {
+		return fmt.Errorf("expected %d embeddings, got %d", inputs, len(vectors))
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return fmt.Errorf("empty embedding for input %d", i)
+		}
+	}
+	return nil
+}
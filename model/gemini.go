@@ -195,3 +195,122 @@ func (f *GeminiFactory) GetSupportedModels() []string {
 func NewGeminiFactory() ModelFactory {
 	return &GeminiFactory{}
 }
+
+// GeminiEmbeddingModel implements EmbeddingModel for Google's Gemini embedding API
+type GeminiEmbeddingModel struct {
+	apiKey    string
+	modelName string
+	client    *http.Client
+}
+
+type geminiEmbedRequest struct {
+	Model   string        `json:"model"`
+	Content geminiContent `json:"content"`
+}
+
+type geminiBatchEmbedRequest struct {
+	Requests []geminiEmbedRequest `json:"requests"`
+}
+
+type geminiBatchEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+	Error *geminiError `json:"error,omitempty"`
+}
+
+// NewGeminiEmbeddingModel creates a new Gemini embedding model instance
+func NewGeminiEmbeddingModel(config *ModelConfig) (*GeminiEmbeddingModel, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("API key is required for Gemini embedding model")
+	}
+
+	modelName := config.ModelName
+	if modelName == "" {
+		modelName = "text-embedding-004"
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 30 // default 30 seconds
+	}
+
+	return &GeminiEmbeddingModel{
+		apiKey:    config.APIKey,
+		modelName: modelName,
+		client: &http.Client{
+			Timeout: time.Duration(timeout) * time.Second,
+		},
+	}, nil
+}
+
+// Embed generates embeddings using Gemini's batch endpoint
+func (g *GeminiEmbeddingModel) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	req := geminiBatchEmbedRequest{}
+	for _, text := range texts {
+		req.Requests = append(req.Requests, geminiEmbedRequest{
+			Model:   "models/" + g.modelName,
+			Content: geminiContent{Parts: []geminiPart{{Text: text}}},
+		})
+	}
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:batchEmbedContents?key=%s", g.modelName, g.apiKey)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var embedResp geminiBatchEmbedResponse
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if embedResp.Error != nil {
+		return nil, fmt.Errorf("Gemini API error: %s", embedResp.Error.Message)
+	}
+
+	vectors := make([][]float32, len(embedResp.Embeddings))
+	for i, e := range embedResp.Embeddings {
+		vectors[i] = e.Values
+	}
+
+	if err := validateEmbeddings(vectors, len(texts)); err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
+
+// GetModelName returns the name of the Gemini embedding model
+func (g *GeminiEmbeddingModel) GetModelName() string {
+	return fmt.Sprintf("gemini:%s", g.modelName)
+}
+
+// Close cleans up resources (no-op for Gemini HTTP client)
+func (g *GeminiEmbeddingModel) Close() error {
+	return nil
+}
+
+// CreateEmbeddingModel creates a new Gemini embedding model instance
+func (f *GeminiFactory) CreateEmbeddingModel(config *ModelConfig) (EmbeddingModel, error) {
+	return NewGeminiEmbeddingModel(config)
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// LocalEmbeddingModel implements EmbeddingModel for a locally hosted
+// Ollama-compatible embedding server
+type LocalEmbeddingModel struct {
+	baseURL   string
+	modelName string
+	client    *http.Client
+}
+
+type localEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type localEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// NewLocalEmbeddingModel creates a new local embedding model instance
+func NewLocalEmbeddingModel(config *ModelConfig) (*LocalEmbeddingModel, error) {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+
+	modelName := config.ModelName
+	if modelName == "" {
+		modelName = "nomic-embed-text"
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 60 // local models can be slow to load
+	}
+
+	return &LocalEmbeddingModel{
+		baseURL:   baseURL,
+		modelName: modelName,
+		client: &http.Client{
+			Timeout: time.Duration(timeout) * time.Second,
+		},
+	}, nil
+}
+
+// Embed generates embeddings using the local server
+func (l *LocalEmbeddingModel) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	jsonData, err := json.Marshal(localEmbedRequest{Model: l.modelName, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", l.baseURL+"/api/embed", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var embedResp localEmbedResponse
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if embedResp.Error != "" {
+		return nil, fmt.Errorf("local embedding error: %s", embedResp.Error)
+	}
+
+	if err := validateEmbeddings(embedResp.Embeddings, len(texts)); err != nil {
+		return nil, err
+	}
+
+	return embedResp.Embeddings, nil
+}
+
+// GetModelName returns the name of the local embedding model
+func (l *LocalEmbeddingModel) GetModelName() string {
+	return fmt.Sprintf("local:%s", l.modelName)
+}
+
+// Close cleans up resources (no-op for the HTTP client)
+func (l *LocalEmbeddingModel) Close() error {
+	return nil
+}
+
+// LocalFactory implements EmbeddingFactory for local models
+type LocalFactory struct{}
+
+// CreateEmbeddingModel creates a new local embedding model instance
+func (f *LocalFactory) CreateEmbeddingModel(config *ModelConfig) (EmbeddingModel, error) {
+	return NewLocalEmbeddingModel(config)
+}
+
+// NewLocalFactory creates a new local model factory
+func NewLocalFactory() *LocalFactory {
+	return &LocalFactory{}
+}
@@ -12,6 +12,7 @@ type ModelType string
 const (
 	ModelTypeOpenAI ModelType = "openai"
 	ModelTypeGemini ModelType = "gemini"
+	ModelTypeLocal  ModelType = "local"
 	// Add more model types as needed
 	// ModelTypeClaude  ModelType = "claude"
 	// ModelTypeLlama   ModelType = "llama"
@@ -19,21 +20,30 @@ const (
 
 // ModelManager manages different AI models and provides a unified interface
 type ModelManager struct {
-	factories map[ModelType]ModelFactory
-	models    map[string]ModelInterface
+	factories          map[ModelType]ModelFactory
+	embeddingFactories map[ModelType]EmbeddingFactory
+	models             map[string]ModelInterface
+	embeddingModels    map[string]EmbeddingModel
 }
 
 // NewModelManager creates a new model manager
 func NewModelManager() *ModelManager {
 	manager := &ModelManager{
-		factories: make(map[ModelType]ModelFactory),
-		models:    make(map[string]ModelInterface),
+		factories:          make(map[ModelType]ModelFactory),
+		embeddingFactories: make(map[ModelType]EmbeddingFactory),
+		models:             make(map[string]ModelInterface),
+		embeddingModels:    make(map[string]EmbeddingModel),
 	}
 
 	// Register available factories
 	manager.RegisterFactory(ModelTypeOpenAI, NewOpenAIFactory())
 	manager.RegisterFactory(ModelTypeGemini, NewGeminiFactory())
 
+	// Register available embedding factories
+	manager.RegisterEmbeddingFactory(ModelTypeOpenAI, &OpenAIFactory{})
+	manager.RegisterEmbeddingFactory(ModelTypeGemini, &GeminiFactory{})
+	manager.RegisterEmbeddingFactory(ModelTypeLocal, NewLocalFactory())
+
 	return manager
 }
 
@@ -42,6 +52,11 @@ func (m *ModelManager) RegisterFactory(modelType ModelType, factory ModelFactory
 	m.factories[modelType] = factory
 }
 
+// RegisterEmbeddingFactory registers an embedding model factory
+func (m *ModelManager) RegisterEmbeddingFactory(modelType ModelType, factory EmbeddingFactory) {
+	m.embeddingFactories[modelType] = factory
+}
+
 // CreateModel creates a model instance
 func (m *ModelManager) CreateModel(modelType ModelType, config *ModelConfig) (ModelInterface, error) {
 	factory, exists := m.factories[modelType]
@@ -61,6 +76,24 @@ func (m *ModelManager) CreateModel(modelType ModelType, config *ModelConfig) (Mo
 	return model, nil
 }
 
+// CreateEmbeddingModel creates an embedding model instance
+func (m *ModelManager) CreateEmbeddingModel(modelType ModelType, config *ModelConfig) (EmbeddingModel, error) {
+	factory, exists := m.embeddingFactories[modelType]
+	if !exists {
+		return nil, fmt.Errorf("unsupported embedding model type: %s", modelType)
+	}
+
+	model, err := factory.CreateEmbeddingModel(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding model: %w", err)
+	}
+
+	key := fmt.Sprintf("%s:%s", modelType, config.ModelName)
+	m.embeddingModels[key] = model
+
+	return model, nil
+}
+
 // GetModel retrieves a previously created model
 func (m *ModelManager) GetModel(modelType ModelType, modelName string) (ModelInterface, bool) {
 	key := fmt.Sprintf("%s:%s", modelType, modelName)
@@ -93,13 +126,19 @@ func (m *ModelManager) CloseAll() error {
 			errors = append(errors, fmt.Sprintf("%s: %v", key, err))
 		}
 	}
+	for key, model := range m.embeddingModels {
+		if err := model.Close(); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
 
 	if len(errors) > 0 {
 		return fmt.Errorf("errors closing models: %s", strings.Join(errors, ", "))
 	}
 
-	// Clear the models map
+	// Clear the models maps
 	m.models = make(map[string]ModelInterface)
+	m.embeddingModels = make(map[string]EmbeddingModel)
 	return nil
 }
 
@@ -182,3 +182,121 @@ func (f *OpenAIFactory) GetSupportedModels() []string {
 func NewOpenAIFactory() ModelFactory {
 	return &OpenAIFactory{}
 }
+
+// OpenAIEmbeddingModel implements EmbeddingModel for the OpenAI embeddings API
+type OpenAIEmbeddingModel struct {
+	apiKey    string
+	baseURL   string
+	modelName string
+	client    *http.Client
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *apiError `json:"error,omitempty"`
+}
+
+// NewOpenAIEmbeddingModel creates a new OpenAI embedding model instance
+func NewOpenAIEmbeddingModel(config *ModelConfig) (*OpenAIEmbeddingModel, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("API key is required for OpenAI embedding model")
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+
+	modelName := config.ModelName
+	if modelName == "" {
+		modelName = "text-embedding-3-small"
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 30 // default 30 seconds
+	}
+
+	return &OpenAIEmbeddingModel{
+		apiKey:    config.APIKey,
+		baseURL:   baseURL,
+		modelName: modelName,
+		client: &http.Client{
+			Timeout: time.Duration(timeout) * time.Second,
+		},
+	}, nil
+}
+
+// Embed generates embeddings using OpenAI
+func (o *OpenAIEmbeddingModel) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	jsonData, err := json.Marshal(openAIEmbeddingRequest{Model: o.modelName, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/embeddings", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var embedResp openAIEmbeddingResponse
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if embedResp.Error != nil {
+		return nil, fmt.Errorf("OpenAI API error: %s", embedResp.Error.Message)
+	}
+
+	// The API reports the input index for each vector, so restore input order
+	vectors := make([][]float32, len(texts))
+	for _, d := range embedResp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+
+	if err := validateEmbeddings(vectors, len(texts)); err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
+
+// GetModelName returns the name of the OpenAI embedding model
+func (o *OpenAIEmbeddingModel) GetModelName() string {
+	return fmt.Sprintf("openai:%s", o.modelName)
+}
+
+// Close cleans up resources (no-op for OpenAI)
+func (o *OpenAIEmbeddingModel) Close() error {
+	return nil
+}
+
+// CreateEmbeddingModel creates a new OpenAI embedding model instance
+func (f *OpenAIFactory) CreateEmbeddingModel(config *ModelConfig) (EmbeddingModel, error) {
+	return NewOpenAIEmbeddingModel(config)
+}
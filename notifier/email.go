@@ -20,7 +20,9 @@ type EmailNotifier struct {
 	senderEmail    string
 	senderPass     string
 	recipientEmail string
+	alertRecipient string
 	htmlTemplate   *template.Template
+	alertTemplate  *template.Template
 }
 
 // EmailConfig contains configuration for email notifications
@@ -30,6 +32,60 @@ type EmailConfig struct {
 	SenderEmail    string
 	SenderPassword string
 	RecipientEmail string
+	// AlertRecipient receives watchlist alerts; empty means RecipientEmail
+	AlertRecipient string
+}
+
+// ContentUpdate summarises what a scraper run changed in the database
+type ContentUpdate struct {
+	New         []storage.Content
+	Updated     []storage.SaveResult
+	NewEpisodes []EpisodeUpdate
+	Unchanged   int
+}
+
+// EpisodeUpdate lists the episodes first seen for a tracked series
+type EpisodeUpdate struct {
+	Content storage.Content
+	Ranges  []storage.EpisodeRange
+}
+
+// Summary describes the new episodes, e.g. "S02E15–E18 added"
+func (u EpisodeUpdate) Summary() string {
+	parts := make([]string, len(u.Ranges))
+	for i, r := range u.Ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ", ") + " added"
+}
+
+// WatchlistAlert is a new item or episode update matching a watchlist entry
+type WatchlistAlert struct {
+	Entry   storage.WatchlistEntry
+	Content storage.Content
+	// Episodes is set for new episodes of a series already stored
+	Episodes *EpisodeUpdate
+}
+
+// Summary describes what was found, e.g. "new movie" or "S04E06–E07 added"
+func (a WatchlistAlert) Summary() string {
+	if a.Episodes != nil {
+		return a.Episodes.Summary()
+	}
+	return "new " + a.Content.Type
+}
+
+// episodeItem is a series with new episodes prepared for the email template
+type episodeItem struct {
+	Title   string
+	Summary string
+}
+
+// updatedItem is an updated content item prepared for the email template
+type updatedItem struct {
+	Title   string
+	Type    string
+	Changes []string
 }
 
 // NewEmailNotifier creates a new email notifier
@@ -59,7 +115,7 @@ func NewEmailNotifier(config EmailConfig) (*EmailNotifier, error) {
     <h1>Cine Pulse - Content Update</h1>
     <p>The following content was scraped on {{.Date}} from {{.SourcesCount}} source(s).</p>
     
-    <p>Total content scraped: <span class="count">{{.TotalCount}}</span></p>
+    <p>New: <span class="count">{{.NewCount}}</span>, updated: <span class="count">{{.UpdatedCount}}</span>, unchanged: {{.UnchangedCount}}</p>
 
     {{if .Movies}}
     <h2>Movies ({{len .Movies}})</h2>
@@ -103,6 +159,40 @@ func NewEmailNotifier(config EmailConfig) (*EmailNotifier, error) {
     </table>
     {{end}}
 
+    {{if .NewEpisodes}}
+    <h2>New Episodes ({{len .NewEpisodes}})</h2>
+    <table>
+        <tr>
+            <th>Series</th>
+            <th>Episodes</th>
+        </tr>
+        {{range .NewEpisodes}}
+        <tr class="series">
+            <td>{{.Title}}</td>
+            <td>{{.Summary}}</td>
+        </tr>
+        {{end}}
+    </table>
+    {{end}}
+
+    {{if .Updated}}
+    <h2>Updated ({{len .Updated}})</h2>
+    <table>
+        <tr>
+            <th>Title</th>
+            <th>Type</th>
+            <th>What Changed</th>
+        </tr>
+        {{range .Updated}}
+        <tr class="{{.Type}}">
+            <td>{{.Title}}</td>
+            <td>{{.Type}}</td>
+            <td>{{range .Changes}}{{.}}<br>{{end}}</td>
+        </tr>
+        {{end}}
+    </table>
+    {{end}}
+
     <div class="source">
         <p>Source(s): {{.SourceURLs}}</p>
     </div>
@@ -117,13 +207,69 @@ func NewEmailNotifier(config EmailConfig) (*EmailNotifier, error) {
 		return nil, fmt.Errorf("failed to parse email template: %v", err)
 	}
 
+	alertTmpl, err := template.New("alert").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Cine Pulse - Watchlist Alert</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 800px; margin: 0 auto; }
+        h1 { color: #e50914; }
+        table { width: 100%; border-collapse: collapse; margin-bottom: 20px; }
+        th { background-color: #f4f4f4; text-align: left; padding: 10px; }
+        td { padding: 10px; border-bottom: 1px solid #ddd; }
+        .movie { background-color: #fff3e0; }
+        .series { background-color: #e3f2fd; }
+        .footer { font-size: 12px; color: #666; margin-top: 50px; text-align: center; }
+        .watch { font-style: italic; color: #666; }
+    </style>
+</head>
+<body>
+    <h1>Cine Pulse - Watchlist Alert</h1>
+    <p>The scrape on {{.Date}} found {{len .Alerts}} item(s) on your watchlist.</p>
+
+    <table>
+        <tr>
+            <th>Title</th>
+            <th>What's New</th>
+            <th>Extra Info</th>
+            <th>Watching</th>
+        </tr>
+        {{range .Alerts}}
+        <tr class="{{.Content.Type}}">
+            <td>{{.Content.Title}}{{if .Content.Year}} ({{.Content.Year}}){{end}}</td>
+            <td>{{.Summary}}</td>
+            <td>{{.Content.ExtraInfo}}</td>
+            <td class="watch">{{.Entry.Kind}} "{{.Entry.Pattern}}"</td>
+        </tr>
+        {{end}}
+    </table>
+
+    <div class="footer">
+        <p>This is an automated email from Cine Pulse. Please do not reply.</p>
+    </div>
+</body>
+</html>
+`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse alert template: %v", err)
+	}
+
+	alertRecipient := config.AlertRecipient
+	if alertRecipient == "" {
+		alertRecipient = config.RecipientEmail
+	}
+
 	return &EmailNotifier{
 		smtpHost:       config.SMTPHost,
 		smtpPort:       config.SMTPPort,
 		senderEmail:    config.SenderEmail,
 		senderPass:     config.SenderPassword,
 		recipientEmail: config.RecipientEmail,
+		alertRecipient: alertRecipient,
 		htmlTemplate:   tmpl,
+		alertTemplate:  alertTmpl,
 	}, nil
 }
 
@@ -142,6 +288,7 @@ func GetEmailConfigFromEnv() EmailConfig {
 	senderEmail := os.Getenv("EMAIL_SENDER")
 	password := os.Getenv("EMAIL_PASSWORD")
 	recipient := os.Getenv("EMAIL_RECIPIENT")
+	alertRecipient := os.Getenv("WATCHLIST_ALERT_RECIPIENT")
 
 	// Log configuration (without showing full password)
 	passwordDisplay := ""
@@ -153,8 +300,8 @@ func GetEmailConfigFromEnv() EmailConfig {
 		}
 	}
 
-	log.Printf("Email Configuration: Host=%s, Port=%d, Sender=%s, Token=%s, Recipient=%s",
-		smtpHost, smtpPort, senderEmail, passwordDisplay, recipient)
+	log.Printf("Email Configuration: Host=%s, Port=%d, Sender=%s, Token=%s, Recipient=%s, Alert recipient=%s",
+		smtpHost, smtpPort, senderEmail, passwordDisplay, recipient, alertRecipient)
 
 	return EmailConfig{
 		SMTPHost:       smtpHost,
@@ -162,13 +309,15 @@ func GetEmailConfigFromEnv() EmailConfig {
 		SenderEmail:    senderEmail,
 		SenderPassword: password,
 		RecipientEmail: recipient,
+		AlertRecipient: alertRecipient,
 	}
 }
 
-// NotifyContentUpdate sends an email with details about newly scraped content
-func (n *EmailNotifier) NotifyContentUpdate(contents []storage.Content, sourceURLs []string) error {
-	if len(contents) == 0 {
-		log.Println("No content to notify about")
+// NotifyContentUpdate sends an email listing new and updated content.
+// Unchanged items are only counted in the summary.
+func (n *EmailNotifier) NotifyContentUpdate(update ContentUpdate, sourceURLs []string) error {
+	if len(update.New) == 0 && len(update.Updated) == 0 {
+		log.Println("No new or updated content to notify about")
 		return nil
 	}
 
@@ -181,13 +330,53 @@ func (n *EmailNotifier) NotifyContentUpdate(contents []storage.Content, sourceUR
 	log.Printf("Email configuration - SMTP Host: %s, Port: %d, Sender: %s, Auth Credentials Length: %d chars",
 		n.smtpHost, n.smtpPort, n.senderEmail, len(n.senderPass))
 
+	email, err := n.renderContentUpdate(update, sourceURLs)
+	if err != nil {
+		return err
+	}
+
+	// Create a new message using gomail
+	m := gomail.NewMessage()
+
+	// Set email headers
+	m.SetHeader("From", n.senderEmail)
+	m.SetHeader("To", n.recipientEmail)
+	m.SetHeader("Subject", email.subject)
+
+	// Set both plain text and HTML versions
+	m.SetBody("text/plain", email.plainText)
+	m.AddAlternative("text/html", email.html)
+
+	// Setup dialer with Mailtrap SMTP credentials
+	// For Mailtrap, username should be "api" and password should be your API token
+	d := gomail.NewDialer(n.smtpHost, n.smtpPort, "api", n.senderPass)
+
+	// Send the email
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+
+	log.Printf("Email notification sent to %s with %d new and %d updated content items",
+		n.recipientEmail, len(update.New), len(update.Updated))
+	return nil
+}
+
+// contentEmail is a rendered content update email
+type contentEmail struct {
+	subject   string
+	plainText string
+	html      string
+}
+
+// renderContentUpdate renders the subject and bodies of a content update email
+func (n *EmailNotifier) renderContentUpdate(update ContentUpdate, sourceURLs []string) (contentEmail, error) {
 	// Prepare data for template
 	var movies []storage.Content
 	var series []storage.Content
 	hasRatings := false
 
-	// Separate movies and series, and check for ratings
-	for _, content := range contents {
+	// Separate new movies and series, and check for ratings
+	for _, content := range update.New {
 		if content.Rating != nil {
 			hasRatings = true
 		}
@@ -199,62 +388,152 @@ func (n *EmailNotifier) NotifyContentUpdate(contents []storage.Content, sourceUR
 		}
 	}
 
+	// A series with new episodes is listed once, under New Episodes, with
+	// whatever else changed; the summary stands for its extra_info change
+	changes := make(map[int64][]storage.ContentChange)
+	for _, result := range update.Updated {
+		changes[result.Content.ID] = result.Changes
+	}
+	withEpisodes := make(map[int64]bool)
+	var newEpisodes []episodeItem
+	for _, episodeUpdate := range update.NewEpisodes {
+		item := episodeItem{Title: episodeUpdate.Content.Title, Summary: episodeUpdate.Summary()}
+		for _, change := range changes[episodeUpdate.Content.ID] {
+			if change.Meaningful() && change.Field != "extra_info" {
+				item.Summary += "; " + change.String()
+			}
+		}
+		newEpisodes = append(newEpisodes, item)
+		withEpisodes[episodeUpdate.Content.ID] = true
+	}
+
+	// Describe what changed for the other updated items
+	var updated []updatedItem
+	for _, result := range update.Updated {
+		if withEpisodes[result.Content.ID] {
+			continue
+		}
+		item := updatedItem{Title: result.Content.Title, Type: result.Content.Type}
+		for _, change := range result.Changes {
+			if change.Meaningful() {
+				item.Changes = append(item.Changes, change.String())
+			}
+		}
+		updated = append(updated, item)
+	}
+
 	// Prepare template data
 	data := struct {
-		Date         string
-		TotalCount   int
-		Movies       []storage.Content
-		Series       []storage.Content
-		HasRatings   bool
-		SourcesCount int
-		SourceURLs   string
+		Date           string
+		NewCount       int
+		UpdatedCount   int
+		UnchangedCount int
+		Movies         []storage.Content
+		Series         []storage.Content
+		Updated        []updatedItem
+		NewEpisodes    []episodeItem
+		HasRatings     bool
+		SourcesCount   int
+		SourceURLs     string
 	}{
-		Date:         time.Now().Format("January 2, 2006 at 3:04 PM"),
-		TotalCount:   len(contents),
-		Movies:       movies,
-		Series:       series,
-		HasRatings:   hasRatings,
-		SourcesCount: len(sourceURLs),
-		SourceURLs:   strings.Join(sourceURLs, ", "),
+		Date:           time.Now().Format("January 2, 2006 at 3:04 PM"),
+		NewCount:       len(update.New),
+		UpdatedCount:   len(update.Updated),
+		UnchangedCount: update.Unchanged,
+		Movies:         movies,
+		Series:         series,
+		Updated:        updated,
+		NewEpisodes:    newEpisodes,
+		HasRatings:     hasRatings,
+		SourcesCount:   len(sourceURLs),
+		SourceURLs:     strings.Join(sourceURLs, ", "),
 	}
 
 	// Render email template
 	var emailBody bytes.Buffer
 	if err := n.htmlTemplate.Execute(&emailBody, data); err != nil {
-		return fmt.Errorf("failed to render email template: %v", err)
+		return contentEmail{}, fmt.Errorf("failed to render email template: %v", err)
 	}
 
-	// Create a new message using gomail
-	m := gomail.NewMessage()
+	subject := fmt.Sprintf("Cine Pulse: %d New Content Items (%d Movies, %d Series), %d Updated",
+		len(update.New), len(movies), len(series), data.UpdatedCount)
 
-	// Set email headers
-	m.SetHeader("From", n.senderEmail)
-	m.SetHeader("To", n.recipientEmail)
-	m.SetHeader("Subject", fmt.Sprintf("Cine Pulse: %d New Content Items (%d Movies, %d Series)",
-		len(contents), len(movies), len(series)))
+	var changesText strings.Builder
+	for _, item := range newEpisodes {
+		fmt.Fprintf(&changesText, "- %s: %s\n", item.Title, item.Summary)
+	}
+	for _, item := range updated {
+		fmt.Fprintf(&changesText, "- %s (%s): %s\n", item.Title, item.Type, strings.Join(item.Changes, "; "))
+	}
 
-	// Set both plain text and HTML versions
 	plainText := fmt.Sprintf(
 		"Cine Pulse Content Update\n\n"+
-			"New content scraped on %s from %d source(s).\n"+
-			"Total items: %d (%d movies, %d series)\n\n"+
+			"Content scraped on %s from %d source(s).\n"+
+			"New items: %d (%d movies, %d series)\n"+
+			"Updated items: %d\n%s"+
+			"Unchanged items: %d\n\n"+
 			"Sources: %s\n\n"+
 			"This is an automated email from Cine Pulse. Please do not reply.",
-		data.Date, data.SourcesCount, data.TotalCount, len(movies), len(series), data.SourceURLs)
+		data.Date, data.SourcesCount, data.NewCount, len(movies), len(series),
+		data.UpdatedCount, changesText.String(), data.UnchangedCount, data.SourceURLs)
+
+	return contentEmail{subject: subject, plainText: plainText, html: emailBody.String()}, nil
+}
 
-	m.SetBody("text/plain", plainText)
+// NotifyWatchlistMatches sends a high-priority email for items matching the
+// watchlist, separate from the content update digest
+func (n *EmailNotifier) NotifyWatchlistMatches(alerts []WatchlistAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	if n.alertRecipient == "" {
+		log.Println("No alert recipient configured, skipping watchlist alert")
+		return nil
+	}
+
+	data := struct {
+		Date   string
+		Alerts []WatchlistAlert
+	}{
+		Date:   time.Now().Format("January 2, 2006 at 3:04 PM"),
+		Alerts: alerts,
+	}
+
+	var emailBody bytes.Buffer
+	if err := n.alertTemplate.Execute(&emailBody, data); err != nil {
+		return fmt.Errorf("failed to render alert template: %v", err)
+	}
+
+	subject := fmt.Sprintf("Cine Pulse alert: %d watchlist matches", len(alerts))
+	if len(alerts) == 1 {
+		subject = fmt.Sprintf("Cine Pulse alert: %s, %s", alerts[0].Content.Title, alerts[0].Summary())
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", n.senderEmail)
+	m.SetHeader("To", n.alertRecipient)
+	m.SetHeader("Subject", subject)
+	// Mail clients show these as high importance
+	m.SetHeader("X-Priority", "1 (Highest)")
+	m.SetHeader("Importance", "High")
+
+	var plainText strings.Builder
+	fmt.Fprintf(&plainText, "Cine Pulse Watchlist Alert\n\nThe scrape on %s found %d item(s) on your watchlist:\n", data.Date, len(alerts))
+	for _, alert := range alerts {
+		fmt.Fprintf(&plainText, "- %s (%s): %s, watching %s %q\n",
+			alert.Content.Title, alert.Content.Type, alert.Summary(), alert.Entry.Kind, alert.Entry.Pattern)
+	}
+	plainText.WriteString("\nThis is an automated email from Cine Pulse. Please do not reply.")
+
+	m.SetBody("text/plain", plainText.String())
 	m.AddAlternative("text/html", emailBody.String())
 
-	// Setup dialer with Mailtrap SMTP credentials
-	// For Mailtrap, username should be "api" and password should be your API token
 	d := gomail.NewDialer(n.smtpHost, n.smtpPort, "api", n.senderPass)
-
-	// Send the email
 	if err := d.DialAndSend(m); err != nil {
-		return fmt.Errorf("failed to send email: %v", err)
+		return fmt.Errorf("failed to send watchlist alert: %v", err)
 	}
 
-	log.Printf("Email notification sent to %s with %d content items",
-		n.recipientEmail, len(contents))
+	log.Printf("Watchlist alert sent to %s with %d matches", n.alertRecipient, len(alerts))
 	return nil
 }
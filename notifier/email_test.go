@@ -0,0 +1,55 @@
+package notifier
+
+import (
+	"cine-pulse/storage"
+	"strings"
+	"testing"
+)
+
+func TestRenderContentUpdateListsSeriesOnce(t *testing.T) {
+	n, err := NewEmailNotifier(EmailConfig{RecipientEmail: "team@example.com"})
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	value := func(s string) *string { return &s }
+	boys := storage.Content{ID: 1, Title: "The Boys", Type: "series", ExtraInfo: "Season 4 Episode 6-7 Added"}
+	dune := storage.Content{ID: 2, Title: "Dune: Part Two", Type: "movie"}
+	update := ContentUpdate{
+		Updated: []storage.SaveResult{
+			{Content: boys, Outcome: storage.OutcomeUpdated, Changes: []storage.ContentChange{
+				{Field: "extra_info", OldValue: value("Season 4 Episode 5 Added"), NewValue: value(boys.ExtraInfo)},
+				{Field: "rating", OldValue: value("8.1"), NewValue: value("8.4")},
+			}},
+			{Content: dune, Outcome: storage.OutcomeUpdated, Changes: []storage.ContentChange{
+				{Field: "rating", OldValue: nil, NewValue: value("8.6")},
+			}},
+		},
+		NewEpisodes: []EpisodeUpdate{
+			{Content: boys, Ranges: []storage.EpisodeRange{{Season: 4, First: 6, Last: 7}}},
+		},
+	}
+
+	email, err := n.renderContentUpdate(update, []string{"https://nkiri.com/"})
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+
+	for name, body := range map[string]string{"html": email.html, "text": email.plainText} {
+		if count := strings.Count(body, "The Boys"); count != 1 {
+			t.Errorf("Expected The Boys once in the %s body, got %d:\n%s", name, count, body)
+		}
+		if !strings.Contains(body, "S04E06–E07 added; rating: ") {
+			t.Errorf("Expected the episodes with the other changes in the %s body:\n%s", name, body)
+		}
+		if strings.Contains(body, "Season 4 Episode 5 Added") {
+			t.Errorf("Expected the extra_info change to be left to the episode summary in the %s body", name)
+		}
+		if !strings.Contains(body, "Dune: Part Two") {
+			t.Errorf("Expected the updated movie in the %s body", name)
+		}
+	}
+	if !strings.Contains(email.subject, "2 Updated") || !strings.Contains(email.plainText, "Updated items: 2") {
+		t.Errorf("Expected both items counted as updated, got %q", email.subject)
+	}
+}
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"cine-pulse/storage"
+	"context"
+	"log"
+)
+
+// BackupJob snapshots the database and removes snapshots the retention policy no longer keeps
+type BackupJob struct {
+	storage   storage.Snapshotter
+	dir       string
+	retention storage.RetentionPolicy
+}
+
+// NewBackupJob creates a job that writes snapshots into dir
+func NewBackupJob(store storage.Snapshotter, dir string, retention storage.RetentionPolicy) *BackupJob {
+	return &BackupJob{
+		storage:   store,
+		dir:       dir,
+		retention: retention,
+	}
+}
+
+// Name returns the name of the job
+func (j *BackupJob) Name() string {
+	return "database_backup"
+}
+
+// Run takes a snapshot and then rotates old ones. Nothing is removed when the snapshot fails.
+func (j *BackupJob) Run(ctx context.Context) error {
+	path, err := j.storage.Backup(j.dir)
+	if err != nil {
+		return err
+	}
+	log.Printf("Database backed up to %s", path)
+
+	removed, err := storage.RotateBackups(j.dir, j.retention)
+	for _, old := range removed {
+		log.Printf("Removed old backup %s", old)
+	}
+	return err
+}
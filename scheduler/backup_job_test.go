@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"cine-pulse/storage"
+	"context"
+	"fmt"
+	"testing"
+)
+
+// fakeSnapshotter records backups instead of writing them
+type fakeSnapshotter struct {
+	dirs []string
+	err  error
+}
+
+func (s *fakeSnapshotter) Backup(dir string) (string, error) {
+	s.dirs = append(s.dirs, dir)
+	return dir + "/snapshot.db", s.err
+}
+
+func TestBackupJob(t *testing.T) {
+	dir := t.TempDir()
+	snapshotter := &fakeSnapshotter{}
+	job := NewBackupJob(snapshotter, dir, storage.RetentionPolicy{KeepLast: 3})
+
+	if job.Name() != "database_backup" {
+		t.Errorf("Unexpected job name %q", job.Name())
+	}
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Backup job failed: %v", err)
+	}
+	if len(snapshotter.dirs) != 1 || snapshotter.dirs[0] != dir {
+		t.Errorf("Expected one backup into %s, got %v", dir, snapshotter.dirs)
+	}
+
+	snapshotter.err = fmt.Errorf("disk full")
+	if err := job.Run(context.Background()); err == nil {
+		t.Error("Expected the backup error to be returned")
+	}
+}
@@ -1,31 +1,37 @@
 package scheduler
 
 import (
+	"cine-pulse/episodes"
+	"cine-pulse/extractor"
+	"cine-pulse/filter"
 	"cine-pulse/model"
 	"cine-pulse/notifier"
 	"cine-pulse/scraper"
 	"cine-pulse/storage"
+	"cine-pulse/validation"
 	"context"
-	"encoding/json"
+	"fmt"
 	"log"
 	"os"
-	"regexp"
-	"strconv"
-	"strings"
+	"time"
 )
 
 // ContentScraperJob is a job that scrapes content and stores it in the database
 type ContentScraperJob struct {
 	scraper       scraper.ScraperInterface
-	storage       *storage.SQLiteStorage
+	storage       storage.StorageInterface
 	modelMgr      *model.ModelManager
-	sourceURLs    []string
+	source        *storage.Source
 	emailNotifier *notifier.EmailNotifier
 	sendEmails    bool
+	embedder      model.EmbeddingModel
+	validator     *validation.Validator
+	rules         *filter.RuleSet
 }
 
-// NewContentScraperJob creates a new content scraper job
-func NewContentScraperJob(scraper scraper.ScraperInterface, storage *storage.SQLiteStorage, modelMgr *model.ModelManager, sourceURLs []string) *ContentScraperJob {
+// NewContentScraperJob creates a new content scraper job.
+// The job scrapes every enabled source in the sources registry that has no schedule of its own.
+func NewContentScraperJob(scraper scraper.ScraperInterface, storage storage.StorageInterface, modelMgr *model.ModelManager) *ContentScraperJob {
 	// Get email configuration from environment variables
 	emailConfig := notifier.GetEmailConfigFromEnv()
 	var emailNotifier *notifier.EmailNotifier
@@ -45,36 +51,132 @@ func NewContentScraperJob(scraper scraper.ScraperInterface, storage *storage.SQL
 		log.Println("Email notifications disabled: missing configuration")
 	}
 
+	// Only compute embeddings if an embedding provider is configured
+	var embedder model.EmbeddingModel
+	if embeddingType, embeddingConfig := model.GetEmbeddingConfigFromEnv(); embeddingType != "" {
+		var err error
+		embedder, err = modelMgr.CreateEmbeddingModel(embeddingType, embeddingConfig)
+		if err != nil {
+			log.Printf("Failed to create embedding model: %v", err)
+		} else {
+			log.Printf("Content embeddings will be computed with: %s", embedder.GetModelName())
+		}
+	}
+
+	// Load content filter rules, keeping the defaults if the configuration is broken
+	rules, err := filter.LoadRulesFromEnv()
+	if err != nil {
+		log.Printf("Failed to load filter rules, using defaults: %v", err)
+		rules = filter.DefaultRules()
+	}
+	for _, rule := range rules.Rules() {
+		log.Printf("Filter rule %s: %s when %s", rule.Name, rule.Action, rule.When)
+	}
+
 	return &ContentScraperJob{
 		scraper:       scraper,
 		storage:       storage,
 		modelMgr:      modelMgr,
-		sourceURLs:    sourceURLs,
 		emailNotifier: emailNotifier,
 		sendEmails:    sendEmails,
+		embedder:      embedder,
+		validator:     validation.NewValidator(),
+		rules:         rules,
 	}
 }
 
+// ForSource returns a copy of the job that only scrapes the given source,
+// used for sources with their own schedule
+func (j *ContentScraperJob) ForSource(source storage.Source) *ContentScraperJob {
+	job := *j
+	job.source = &source
+	return &job
+}
+
 // Name returns the name of the job
 func (j *ContentScraperJob) Name() string {
+	if j.source != nil {
+		// Names default to the host, so several sources can share one
+		return fmt.Sprintf("content_scraper_%d_%s", j.source.ID, j.source.Name)
+	}
 	return "content_scraper"
 }
 
-// Run executes the job
+// sources returns the enabled sources this job is responsible for
+func (j *ContentScraperJob) sources() ([]storage.Source, error) {
+	all, err := j.storage.ListSources()
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []storage.Source
+	for _, source := range all {
+		if !source.Enabled {
+			continue
+		}
+		if j.source != nil {
+			if source.ID == j.source.ID {
+				sources = append(sources, source)
+			}
+		} else if source.Schedule == "" {
+			sources = append(sources, source)
+		}
+	}
+
+	return sources, nil
+}
+
+// Run executes the job and records it in the scrape run log
 func (j *ContentScraperJob) Run(ctx context.Context) error {
-	log.Printf("Running content scraper job with %d sources", len(j.sourceURLs))
+	trigger := TriggerFromContext(ctx)
+	runID, err := j.storage.StartScrapeRun(string(trigger))
+	if err != nil {
+		return err
+	}
+
+	err = j.run(ctx, runID)
+
+	runErr := ""
+	if err != nil {
+		runErr = err.Error()
+	}
+	if finishErr := j.storage.FinishScrapeRun(runID, runErr); finishErr != nil {
+		log.Printf("Error finishing scrape run %d: %v", runID, finishErr)
+	}
+
+	return err
+}
 
-	// If no sources are provided, use default
-	if len(j.sourceURLs) == 0 {
-		j.sourceURLs = []string{"https://nkiri.com/"}
+func (j *ContentScraperJob) run(ctx context.Context, runID int64) error {
+	sources, err := j.sources()
+	if err != nil {
+		return err
+	}
+	log.Printf("Running content scraper job with %d sources (run %d, %s)", len(sources), runID, TriggerFromContext(ctx))
+
+	var sourceURLs []string
+	for _, source := range sources {
+		sourceURLs = append(sourceURLs, source.URL)
 	}
 
 	var totalContentScraped int
-	var allScrapedContent []storage.Content
+	var update notifier.ContentUpdate
+	var totalRejected int
+	var totalFiltered int
+	filterCounts := make(map[string]int)
+
+	// Alerts for the watchlist are found as items are saved
+	watchlist, err := j.storage.ListWatchlist()
+	if err != nil {
+		log.Printf("Error loading watchlist: %v", err)
+	}
+	var alerts []notifier.WatchlistAlert
 
-	// Process each source URL
-	for _, url := range j.sourceURLs {
-		log.Printf("Scraping content from %s", url)
+	// Process each source
+	for _, source := range sources {
+		url := source.URL
+		log.Printf("Scraping content from %s (%s)", url, source.Type)
+		outcome := &storage.ScrapeRunSource{SourceID: source.ID, URL: url, StartedAt: time.Now()}
 
 		// Check if context is cancelled
 		select {
@@ -85,392 +187,258 @@ func (j *ContentScraperJob) Run(ctx context.Context) error {
 		}
 
 		// Scrape the URL
-		scrapedText, err := j.scraper.Scrape(url)
+		page, err := j.scraper.Scrape(url, source.Type)
+		outcome.HTTPStatus = page.StatusCode
+		outcome.Bytes = page.Bytes
 		if err != nil {
 			log.Printf("Error scraping %s: %v", url, err)
+			j.recordFailure(runID, source, outcome, err.Error())
 			continue
 		}
 
-		// Set up the prompt for content extraction
-		prompt := buildContentExtractionPrompt()
-
 		// Try with Gemini model first
-		geminiConfig := &model.ModelConfig{
-			APIKey:    os.Getenv("GEMINI_API_KEY"),
-			ModelName: "gemini-1.5-flash",
-		}
-
-		var response string
 		var contents []storage.Content
 
-		if geminiConfig.APIKey != "" {
-			geminiModel, err := j.modelMgr.CreateModel(model.ModelTypeGemini, geminiConfig)
-			if err != nil {
-				log.Printf("Failed to create Gemini model: %v", err)
-			} else {
-				response, err = geminiModel.GenerateText(ctx, prompt+scrapedText)
-				if err != nil {
-					log.Printf("Error generating text with Gemini: %v", err)
-				} else {
-					// Manual JSON construction approach
-					contents = extractContentManually(response)
-
-					if len(contents) == 0 {
-						// If manual extraction fails, fall back to standard approach
-						processedResponse := preprocessModelResponse(response)
-
-						// Parse response
-						if err := json.Unmarshal([]byte(processedResponse), &contents); err != nil {
-							log.Printf("Error parsing Gemini JSON response: %v", err)
-							log.Printf("Raw response (first 100 chars): %s", truncateString(response, 100))
-							log.Printf("Processed response (first 100 chars): %s", truncateString(processedResponse, 100))
-							contents = nil
-						}
-					}
-				}
-			}
+		if apiKey := os.Getenv("GEMINI_API_KEY"); apiKey != "" {
+			outcome.Model = "gemini-1.5-flash"
+			contents = j.extractWithModel(ctx, model.ModelTypeGemini, &model.ModelConfig{
+				APIKey:    apiKey,
+				ModelName: outcome.Model,
+			}, source.ExtractionProfile, page.Text)
 		}
 
 		// If Gemini failed, try OpenAI
-		if len(contents) == 0 && os.Getenv("OPENAI_API_KEY") != "" {
-			openaiConfig := &model.ModelConfig{
-				APIKey:    os.Getenv("OPENAI_API_KEY"),
-				ModelName: "gpt-4o",
-			}
+		if apiKey := os.Getenv("OPENAI_API_KEY"); len(contents) == 0 && apiKey != "" {
+			outcome.Model = "gpt-4o"
+			contents = j.extractWithModel(ctx, model.ModelTypeOpenAI, &model.ModelConfig{
+				APIKey:    apiKey,
+				ModelName: outcome.Model,
+			}, source.ExtractionProfile, page.Text)
+		}
 
-			openaiModel, err := j.modelMgr.CreateModel(model.ModelTypeOpenAI, openaiConfig)
-			if err != nil {
-				log.Printf("Failed to create OpenAI model: %v", err)
-			} else {
-				response, err = openaiModel.GenerateText(ctx, prompt+scrapedText)
-				if err != nil {
-					log.Printf("Error generating text with OpenAI: %v", err)
-				} else {
-					// Preprocess response to extract valid JSON
-					processedResponse := preprocessModelResponse(response)
-
-					// Parse response
-					if err := json.Unmarshal([]byte(processedResponse), &contents); err != nil {
-						log.Printf("Error parsing OpenAI JSON response: %v", err)
-						log.Printf("Raw response: %s", response)
-						log.Printf("Processed response: %s", processedResponse)
-						contents = nil
-					}
-				}
+		// Validate and normalize before anything is stored
+		extracted := len(contents)
+		contents, rejected := j.validator.Validate(contents)
+		for _, rejection := range rejected {
+			log.Printf("Rejected %q from %s: %s", rejection.Content.Title, url, rejection.Reason)
+		}
+		totalRejected += len(rejected)
+
+		// Apply the configured filter rules
+		contents, report := j.rules.Apply(contents)
+		for _, result := range report.Rules {
+			if result.Matched > 0 {
+				log.Printf("Filter rule %s (%s) matched %d items from %s", result.Name, result.Action, result.Matched, url)
 			}
 		}
+		for _, exclusion := range report.Excluded {
+			filterCounts[exclusion.Rule]++
+		}
+		totalFiltered += len(report.Excluded)
 
-		// Save contents to database and collect for email notification
+		outcome.Extracted = extracted
+		outcome.Rejected = len(rejected)
+		outcome.Filtered = len(report.Excluded)
+
+		// Save contents to database and collect new and updated items for email notification
 		if len(contents) > 0 {
-			log.Printf("Extracted %d content items from %s", len(contents), url)
+			log.Printf("Extracted %d content items from %s (%d rejected, %d filtered out)",
+				extracted, url, len(rejected), len(report.Excluded))
 
 			// Add source URL to each content item
 			sourceURL := url
-			var scrapedContentForSource []storage.Content
-
 			for i := range contents {
 				contents[i].SourceURL = &sourceURL
 				// The scraped_at timestamp will be set by the database
 			}
 
 			// Save to database
-			for _, content := range contents {
-				if err := j.storage.SaveContent(content); err != nil {
-					log.Printf("Error saving content %s: %v", content.Title, err)
-				} else {
-					totalContentScraped++
-					scrapedContentForSource = append(scrapedContentForSource, content)
+			results, err := j.storage.SaveContents(contents)
+			if err != nil {
+				log.Printf("Error saving content from %s: %v", url, err)
+				j.recordFailure(runID, source, outcome, err.Error())
+				continue
+			}
+
+			// Only new and changed items need fresh embeddings and a notification
+			var changedContent []storage.Content
+			for _, result := range results {
+				if result.ScrapedTitle != "" {
+					log.Printf("Saved %q from %s onto %q", result.ScrapedTitle, url, result.Content.Title)
+				}
+				switch result.Outcome {
+				case storage.OutcomeInserted:
+					outcome.Inserted++
+					update.New = append(update.New, result.Content)
+					changedContent = append(changedContent, result.Content)
+					alerts = j.matchWatchlist(alerts, watchlist, result.Content, nil)
+				case storage.OutcomeUpdated:
+					outcome.Updated++
+					update.Updated = append(update.Updated, result)
+					changedContent = append(changedContent, result.Content)
+				default:
+					update.Unchanged++
+				}
+
+				// Report episodes added to series we were already tracking
+				added := j.trackEpisodes(result.Content)
+				if len(added) > 0 && result.Outcome == storage.OutcomeUpdated {
+					episodeUpdate := notifier.EpisodeUpdate{
+						Content: result.Content,
+						Ranges:  episodes.Ranges(added),
+					}
+					update.NewEpisodes = append(update.NewEpisodes, episodeUpdate)
+					alerts = j.matchWatchlist(alerts, watchlist, result.Content, &episodeUpdate)
 				}
 			}
+			totalContentScraped += len(results)
+			outcome.Saved = len(results)
 
-			// Add successfully saved content to our collection for email
-			allScrapedContent = append(allScrapedContent, scrapedContentForSource...)
-		} else {
+			// Compute embeddings for semantic search
+			j.embedContent(ctx, changedContent)
+			j.recordSuccess(runID, source, outcome)
+		} else if extracted == 0 {
 			log.Printf("No content extracted from %s", url)
+			j.recordFailure(runID, source, outcome, "no content extracted")
+		} else {
+			log.Printf("No content kept from %s (%d rejected, %d filtered out)", url, len(rejected), len(report.Excluded))
+			j.recordSuccess(runID, source, outcome)
 		}
 	}
 
 	// Log job summary
-	log.Printf("Content scraper job complete. Scraped %d content items from %d sources",
-		totalContentScraped, len(j.sourceURLs))
+	log.Printf("Content scraper job complete. Scraped %d content items from %d sources (%d new, %d updated, %d unchanged, %d rejected, %d filtered out)",
+		totalContentScraped, len(sources), len(update.New), len(update.Updated), update.Unchanged, totalRejected, totalFiltered)
+	for rule, count := range filterCounts {
+		log.Printf("Filter rule %s excluded %d items", rule, count)
+	}
+
+	// Watchlist matches get their own high-priority email ahead of the digest
+	if len(alerts) > 0 && j.sendEmails && j.emailNotifier != nil {
+		log.Printf("Sending watchlist alert with %d matches", len(alerts))
+		if err := j.emailNotifier.NotifyWatchlistMatches(alerts); err != nil {
+			log.Printf("Failed to send watchlist alert: %v", err)
+		}
+	} else if len(alerts) > 0 {
+		log.Printf("Email notifications disabled, not sending watchlist alert with %d matches", len(alerts))
+	}
 
-	// Send email notification if content was scraped and email notifications are enabled
-	if j.sendEmails && j.emailNotifier != nil && len(allScrapedContent) > 0 {
-		log.Printf("Sending email notification with %d content items", len(allScrapedContent))
-		if err := j.emailNotifier.NotifyContentUpdate(allScrapedContent, j.sourceURLs); err != nil {
+	// Send email notification if anything new or changed and email notifications are enabled
+	hasChanges := len(update.New) > 0 || len(update.Updated) > 0
+	if j.sendEmails && j.emailNotifier != nil && hasChanges {
+		log.Printf("Sending email notification with %d new and %d updated content items", len(update.New), len(update.Updated))
+		if err := j.emailNotifier.NotifyContentUpdate(update, sourceURLs); err != nil {
 			log.Printf("Failed to send email notification: %v", err)
 		}
-	} else if len(allScrapedContent) > 0 {
-		log.Println("Email notifications disabled or no content scraped")
+	} else if hasChanges {
+		log.Println("Email notifications disabled")
+	} else {
+		log.Println("No new or updated content, skipping email notification")
 	}
 
 	return nil
 }
 
-// preprocessModelResponse cleans and extracts valid JSON from model responses
-func preprocessModelResponse(response string) string {
-	log.Printf("Raw model response (first 200 chars): %s", truncateString(response, 200))
-
-	// Remove markdown code block markers
-	response = strings.ReplaceAll(response, "```json", "")
-	response = strings.ReplaceAll(response, "```", "")
-
-	// Find the first '[' and the last ']' to extract only the JSON array
-	startIdx := strings.Index(response, "[")
-	if startIdx == -1 {
-		log.Println("No JSON array found in response")
-		// Let's check if we have a valid JSON object instead
-		objectStart := strings.Index(response, "{")
-		if objectStart != -1 {
-			log.Println("Found JSON object instead of array, wrapping in array")
-			objectEnd := strings.LastIndex(response, "}")
-			if objectEnd != -1 && objectEnd > objectStart {
-				jsonObj := response[objectStart : objectEnd+1]
-				return "[" + jsonObj + "]" // Wrap the single object in an array
-			}
-		}
-		log.Println("Full response: " + response)
-		return "[]" // Return empty array if no JSON found
-	}
-
-	endIdx := strings.LastIndex(response, "]")
-	if endIdx == -1 || endIdx <= startIdx {
-		log.Println("Invalid JSON array format in response")
-		log.Println("Full response: " + response)
-		return "[]" // Return empty array if invalid format
+// recordSuccess stores a successful scrape in the run log and the source's health fields
+func (j *ContentScraperJob) recordSuccess(runID int64, source storage.Source, outcome *storage.ScrapeRunSource) {
+	j.recordOutcome(runID, outcome)
+	if err := j.storage.RecordSourceSuccess(source.ID); err != nil {
+		log.Printf("Error recording success for %s: %v", source.URL, err)
 	}
+}
 
-	// Extract the JSON array part, ensuring we get the entire array
-	jsonPart := response[startIdx : endIdx+1]
-	log.Printf("Extracted JSON (first 200 chars): %s", truncateString(jsonPart, 200))
-
-	// Remove any unexpected backticks that might be in the content
-	jsonPart = strings.ReplaceAll(jsonPart, "`", "")
-
-	// Fix common JSON formatting issues that Gemini tends to produce
-	// 1. Fix spaces between field names and colons (e.g., "title :" -> "title:")
-	jsonPart = regexp.MustCompile(`"([^"]+)" :`).ReplaceAllString(jsonPart, `"$1":`)
-
-	// 2. Fix missing quotes around string values
-	jsonPart = regexp.MustCompile(`: *([^"{}\[\],\d][^{}\[\],\s]*),`).ReplaceAllString(jsonPart, `:"$1",`)
-	jsonPart = regexp.MustCompile(`: *([^"{}\[\],\d][^{}\[\],\s]*)$`).ReplaceAllString(jsonPart, `:"$1"`)
-
-	// 3. Replace any escaped quotes that might cause issues
-	jsonPart = strings.ReplaceAll(jsonPart, "\\\"", "\"")
-
-	// 4. Clean up any control characters that might have slipped in
-	jsonPart = regexp.MustCompile(`[\x00-\x1F\x7F]`).ReplaceAllString(jsonPart, "")
-
-	// 5. Check trailing commas in arrays and objects
-	jsonPart = regexp.MustCompile(`,\s*\}`).ReplaceAllString(jsonPart, `}`)
-	jsonPart = regexp.MustCompile(`,\s*\]`).ReplaceAllString(jsonPart, `]`)
-
-	// Do a manual check to see if the extracted JSON is valid
-	var testJson []interface{}
-	if err := json.Unmarshal([]byte(jsonPart), &testJson); err != nil {
-		log.Printf("Extracted JSON is not valid: %v", err)
-
-		// Try to fix common quotes issues in JSON manually
-		jsonPart = strings.ReplaceAll(jsonPart, "\"\"", "\"") // Fix double quotes
-		jsonPart = strings.ReplaceAll(jsonPart, "''", "'")    // Fix double single quotes
-		jsonPart = strings.ReplaceAll(jsonPart, "…", "...")   // Fix ellipsis
-
-		// Try again after fixes
-		if err := json.Unmarshal([]byte(jsonPart), &testJson); err != nil {
-			log.Printf("JSON is still invalid after fixes: %v", err)
-			log.Println("Full JSON extract: " + jsonPart)
-
-			// As a last resort, try to reformat the JSON properly
-			jsonPart = reformatJSON(jsonPart)
-			return jsonPart
-		}
+// recordFailure stores a failed scrape in the run log and the source's health fields
+func (j *ContentScraperJob) recordFailure(runID int64, source storage.Source, outcome *storage.ScrapeRunSource, message string) {
+	outcome.Error = message
+	j.recordOutcome(runID, outcome)
+	if err := j.storage.RecordSourceFailure(source.ID, message); err != nil {
+		log.Printf("Error recording failure for %s: %v", source.URL, err)
 	}
-
-	log.Printf("Successfully extracted valid JSON array with %d items", len(testJson))
-	return jsonPart
 }
 
-// reformatJSON attempts to reformat malformed JSON into valid JSON
-func reformatJSON(input string) string {
-	// This is a simplified reformatter for common issues
-
-	// First, ensure we have an array
-	if !strings.HasPrefix(input, "[") || !strings.HasSuffix(input, "]") {
-		log.Println("Input is not a proper JSON array, returning empty array")
-		return "[]"
+func (j *ContentScraperJob) recordOutcome(runID int64, outcome *storage.ScrapeRunSource) {
+	outcome.FinishedAt = time.Now()
+	if err := j.storage.RecordScrapeRunSource(runID, *outcome); err != nil {
+		log.Printf("Error recording run outcome for %s: %v", outcome.URL, err)
 	}
+}
 
-	// Strip the outer brackets to work with the content
-	content := strings.TrimSpace(input[1 : len(input)-1])
-
-	// Split by objects - look for closing brace followed by comma
-	parts := strings.Split(content, "},")
-
-	// Last part won't have a comma, so it needs special handling
-	if len(parts) > 1 {
-		lastPart := parts[len(parts)-1]
-		if !strings.HasSuffix(lastPart, "}") {
-			lastPart = lastPart + "}"
+// matchWatchlist adds an alert when a new item or episode update matches a
+// watchlist entry. An item matching several entries is reported once, under
+// the first of them.
+func (j *ContentScraperJob) matchWatchlist(alerts []notifier.WatchlistAlert, watchlist []storage.WatchlistEntry, content storage.Content, episodeUpdate *notifier.EpisodeUpdate) []notifier.WatchlistAlert {
+	for _, entry := range watchlist {
+		if !entry.Match(content) {
+			continue
 		}
-		parts[len(parts)-1] = lastPart
 
-		// Add closing brace to all other parts
-		for i := 0; i < len(parts)-1; i++ {
-			parts[i] = parts[i] + "}"
+		alert := notifier.WatchlistAlert{Entry: entry, Content: content, Episodes: episodeUpdate}
+		log.Printf("Watchlist %s %q matched %s: %s", entry.Kind, entry.Pattern, content.Title, alert.Summary())
+		if err := j.storage.RecordWatchlistMatch(entry.ID); err != nil {
+			log.Printf("Error recording watchlist match for %s: %v", content.Title, err)
 		}
+		return append(alerts, alert)
 	}
+	return alerts
+}
 
-	// Process each object
-	var validObjects []string
-	for _, part := range parts {
-		// Ensure it's an object
-		trimmed := strings.TrimSpace(part)
-		if !strings.HasPrefix(trimmed, "{") {
-			trimmed = "{" + trimmed
-		}
-		if !strings.HasSuffix(trimmed, "}") {
-			trimmed = trimmed + "}"
-		}
-
-		// Validate the object
-		var obj map[string]interface{}
-		if err := json.Unmarshal([]byte(trimmed), &obj); err == nil {
-			// It's valid, keep it
-			validObjStr, _ := json.Marshal(obj)
-			validObjects = append(validObjects, string(validObjStr))
-		} else {
-			log.Printf("Skipping invalid object: %s", trimmed)
-		}
+// extractWithModel runs content extraction with a single model, logging any failure
+func (j *ContentScraperJob) extractWithModel(ctx context.Context, modelType model.ModelType, config *model.ModelConfig, profile string, scrapedText string) []storage.Content {
+	m, err := j.modelMgr.CreateModel(modelType, config)
+	if err != nil {
+		log.Printf("Failed to create %s model: %v", modelType, err)
+		return nil
 	}
 
-	// If we have any valid objects, return them as an array
-	if len(validObjects) > 0 {
-		return "[" + strings.Join(validObjects, ",") + "]"
+	contents, err := extractor.ExtractWithProfile(ctx, m, profile, scrapedText)
+	if err != nil {
+		log.Printf("Error extracting content with %s: %v", m.GetModelName(), err)
+		return nil
 	}
 
-	// If all else fails, return empty array
-	return "[]"
+	return contents
 }
 
-// extractContentManually extracts content entries from the model response using regex
-func extractContentManually(response string) []storage.Content {
-	var results []storage.Content
-
-	// Define regex pattern to match content entries with the expected fields
-	titlePattern := regexp.MustCompile(`"title":\s*"([^"]+)"`)
-	yearPattern := regexp.MustCompile(`"year":\s*(\d+)`)
-	categoryPattern := regexp.MustCompile(`"category":\s*"([^"]+)"`)
-	extraInfoPattern := regexp.MustCompile(`"extra_info":\s*"([^"]+)"`)
-	typePattern := regexp.MustCompile(`"type":\s*"([^"]+)"`)
-	ratingPattern := regexp.MustCompile(`"rating":\s*(\d+(?:\.\d+)?)`)
-
-	// Find all object blocks in the response
-	objectPattern := regexp.MustCompile(`\{[^{}]*\}`)
-	objects := objectPattern.FindAllString(response, -1)
-
-	log.Printf("Found %d potential content objects in response", len(objects))
-
-	for _, obj := range objects {
-		var content storage.Content
-		var valid bool = true
-
-		// Extract title (required)
-		if titleMatches := titlePattern.FindStringSubmatch(obj); len(titleMatches) > 1 {
-			content.Title = titleMatches[1]
-		} else {
-			valid = false
-			continue // Skip if no title
-		}
-
-		// Extract year (optional)
-		if yearMatches := yearPattern.FindStringSubmatch(obj); len(yearMatches) > 1 {
-			if year, err := strconv.Atoi(yearMatches[1]); err == nil {
-				content.Year = &year
-			}
-		}
-
-		// Extract category (required)
-		if categoryMatches := categoryPattern.FindStringSubmatch(obj); len(categoryMatches) > 1 {
-			content.Category = categoryMatches[1]
-			// Skip Korean content
-			if content.Category == "Korean" {
-				valid = false
-				continue
-			}
-		} else {
-			valid = false
-			continue
-		}
-
-		// Extract extra_info (required)
-		if extraInfoMatches := extraInfoPattern.FindStringSubmatch(obj); len(extraInfoMatches) > 1 {
-			content.ExtraInfo = extraInfoMatches[1]
-		} else {
-			content.ExtraInfo = "" // Set empty if not found
-		}
-
-		// Extract type (required)
-		if typeMatches := typePattern.FindStringSubmatch(obj); len(typeMatches) > 1 {
-			content.Type = typeMatches[1]
-			if content.Type != "movie" && content.Type != "series" {
-				valid = false
-				continue
-			}
-		} else {
-			valid = false
-			continue
-		}
+// trackEpisodes records the episodes named in a series' extra info and returns the ones not seen before
+func (j *ContentScraperJob) trackEpisodes(content storage.Content) []storage.Episode {
+	if content.Type != validation.TypeSeries {
+		return nil
+	}
 
-		// Extract rating (optional)
-		if ratingMatches := ratingPattern.FindStringSubmatch(obj); len(ratingMatches) > 1 {
-			if rating, err := strconv.ParseFloat(ratingMatches[1], 64); err == nil {
-				content.Rating = &rating
-			}
-		}
+	r, ok := episodes.Parse(content.ExtraInfo)
+	if !ok {
+		return nil
+	}
 
-		// Add to results if valid
-		if valid {
-			results = append(results, content)
-		}
+	added, err := j.storage.SaveEpisodes(content.Title, content.Type, r)
+	if err != nil {
+		log.Printf("Error saving episodes for %s: %v", content.Title, err)
+		return nil
 	}
 
-	log.Printf("Manually extracted %d valid content items", len(results))
-	return results
+	return added
 }
 
-// truncateString truncates a string to a specified maximum length
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// embedContent computes and stores embeddings for saved content if an embedding model is configured
+func (j *ContentScraperJob) embedContent(ctx context.Context, contents []storage.Content) {
+	if j.embedder == nil || len(contents) == 0 {
+		return
 	}
-	return s[:maxLen] + "..."
-}
-
-// buildContentExtractionPrompt creates the prompt for extracting content
-func buildContentExtractionPrompt() string {
-	return `You are a specialized JSON extraction tool. Extract movies and series from the provided text into a clean JSON array.
-
-Each entry must follow this exact schema:
-{
-  "title": string,
-  "year": number (for movies only, if available),
-  "category": string ("Hollywood", "Foreign", "Anime", "TV Series"),
-  "extra_info": string (e.g., "Download Hollywood Movie", "Episode 15–18 Added", "Complete"),
-  "type": string ("movie" or "series"),
-  "rating": number (optional, if available, on a scale of 1-10)
-}
 
-Critical rules:
-1. Output ONLY the raw JSON array with no explanations, no markdown code blocks, and no backticks
-2. Do not include Korean content
-3. For movies, extract year as an integer if available
-4. For series, ignore the year unless explicitly mentioned
-5. Preserve episode/season information in extra_info
-6. Ensure the output is valid parseable JSON with no additional text
+	texts := make([]string, len(contents))
+	for i, content := range contents {
+		texts[i] = content.EmbeddingText()
+	}
 
-Examples of correct format:
-[{"title":"Movie 1","year":2023,"category":"Hollywood","extra_info":"Action","type":"movie"},{"title":"Series 1","category":"TV Series","extra_info":"Season 2","type":"series"}]
+	vectors, err := j.embedder.Embed(ctx, texts)
+	if err != nil {
+		log.Printf("Error computing embeddings with %s: %v", j.embedder.GetModelName(), err)
+		return
+	}
 
-YOUR ENTIRE RESPONSE MUST BE A VALID JSON ARRAY ONLY. DO NOT INCLUDE ANY OTHER TEXT.
-`
+	for i, content := range contents {
+		if err := j.storage.SaveEmbedding(content, j.embedder.GetModelName(), vectors[i]); err != nil {
+			log.Printf("Error saving embedding for %s: %v", content.Title, err)
+		}
+	}
 }
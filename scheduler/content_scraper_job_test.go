@@ -0,0 +1,257 @@
+package scheduler
+
+import (
+	"cine-pulse/model"
+	"cine-pulse/scraper"
+	"cine-pulse/storage"
+	"context"
+	"fmt"
+	"testing"
+)
+
+// fakeScraper returns canned pages and fails for URLs without one
+type fakeScraper struct {
+	pages map[string]string
+}
+
+func (s *fakeScraper) Scrape(url string, sourceType string) (scraper.Page, error) {
+	text, ok := s.pages[url]
+	if !ok {
+		return scraper.Page{StatusCode: 503}, fmt.Errorf("HTTP 503")
+	}
+	return scraper.Page{Text: text, StatusCode: 200, Bytes: len(text)}, nil
+}
+
+// fakeModel answers every prompt with the same response
+type fakeModel struct {
+	response *string
+}
+
+func (m *fakeModel) GenerateText(ctx context.Context, prompt string) (string, error) {
+	return *m.response, nil
+}
+
+func (m *fakeModel) GenerateTextWithOptions(ctx context.Context, prompt string, options *model.GenerationOptions) (string, error) {
+	return *m.response, nil
+}
+
+func (m *fakeModel) GetModelName() string { return "fake" }
+
+func (m *fakeModel) Close() error { return nil }
+
+type fakeModelFactory struct {
+	model *fakeModel
+}
+
+func (f *fakeModelFactory) CreateModel(config *model.ModelConfig) (model.ModelInterface, error) {
+	return f.model, nil
+}
+
+func (f *fakeModelFactory) GetSupportedModels() []string { return []string{"fake"} }
+
+// setJobEnv makes jobs extract with the fake model only, without embeddings or emails
+func setJobEnv(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "test")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("EMBEDDING_PROVIDER", "")
+	t.Setenv("EMAIL_SMTP_HOST", "")
+	t.Setenv("EMAIL_RECIPIENT", "")
+	t.Setenv("FILTER_RULES", "")
+	t.Setenv("FILTER_RULES_FILE", "")
+}
+
+func TestContentScraperJobRun(t *testing.T) {
+	setJobEnv(t)
+
+	store := storage.NewMemoryStorage()
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	if err := storage.RegisterDefaultSource(store); err != nil {
+		t.Fatalf("Failed to register the default source: %v", err)
+	}
+	brokenID, err := store.AddSource(storage.Source{URL: "https://broken.example.com/", Enabled: true})
+	if err != nil {
+		t.Fatalf("Failed to add source: %v", err)
+	}
+	for _, entry := range []storage.WatchlistEntry{
+		{Kind: storage.WatchTitle, Pattern: "The Boys"},
+		{Kind: storage.WatchKeyword, Pattern: "dune", Type: "movie"},
+		{Kind: storage.WatchKeyword, Pattern: "marvel"},
+	} {
+		if _, err := store.AddWatchlistEntry(entry); err != nil {
+			t.Fatalf("Failed to add watchlist entry: %v", err)
+		}
+	}
+
+	response := `[
+		{"title": "Dune: Part Two", "year": 2024, "category": "Hollywood", "type": "movie", "rating": 8.6},
+		{"title": "The Boys", "category": "TV Series", "type": "series", "extra_info": "Season 4 Episode 5 Added"},
+		{"title": "Squid Game", "category": "Korean", "type": "series"},
+		{"title": "", "category": "Hollywood", "type": "movie"}
+	]`
+	manager := model.NewModelManager()
+	manager.RegisterFactory(model.ModelTypeGemini, &fakeModelFactory{model: &fakeModel{response: &response}})
+
+	job := NewContentScraperJob(&fakeScraper{pages: map[string]string{"https://nkiri.com/": "page"}}, store, manager)
+	if err := job.Run(WithTrigger(context.Background(), TriggerCron)); err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+
+	stats, err := store.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats["movies"] != 1 || stats["series"] != 1 {
+		t.Fatalf("Expected one movie and one series to be saved, got %v", stats)
+	}
+
+	runs, err := store.RecentScrapeRuns(1)
+	if err != nil {
+		t.Fatalf("Failed to get runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Trigger != "cron" || runs[0].FinishedAt == nil || len(runs[0].Sources) != 2 {
+		t.Fatalf("Unexpected run: %+v", runs)
+	}
+	outcome := runs[0].Sources[0]
+	if outcome.Extracted != 4 || outcome.Rejected != 1 || outcome.Filtered != 1 || outcome.Inserted != 2 || outcome.Error != "" {
+		t.Errorf("Unexpected outcome for nkiri: %+v", outcome)
+	}
+	if failed := runs[0].Sources[1]; failed.HTTPStatus != 503 || failed.Error != "HTTP 503" {
+		t.Errorf("Unexpected outcome for the broken source: %+v", failed)
+	}
+
+	broken, err := store.GetSource(brokenID)
+	if err != nil {
+		t.Fatalf("Failed to get source: %v", err)
+	}
+	if broken.ConsecutiveFailures != 1 || broken.LastError != "HTTP 503" {
+		t.Errorf("Expected the broken source to record a failure, got %+v", broken)
+	}
+
+	// New episodes of a known series update it
+	response = `[{"title": "The Boys", "category": "TV Series", "type": "series", "extra_info": "Season 4 Episode 6-7 Added"}]`
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+
+	runs, err = store.RecentScrapeRuns(1)
+	if err != nil {
+		t.Fatalf("Failed to get runs: %v", err)
+	}
+	if runs[0].Trigger != "manual" || runs[0].Sources[0].Updated != 1 {
+		t.Errorf("Expected a manual run updating one item, got %+v", runs[0])
+	}
+
+	latest, err := store.LatestEpisode("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get latest episode: %v", err)
+	}
+	if latest == nil || latest.Season != 4 || latest.Number != 7 {
+		t.Errorf("Expected S04E07 as latest episode, got %+v", latest)
+	}
+
+	// The Boys matched as a new item and again with new episodes
+	watchlist, err := store.ListWatchlist()
+	if err != nil {
+		t.Fatalf("Failed to list watchlist: %v", err)
+	}
+	if watchlist[0].Matches != 2 || watchlist[1].Matches != 1 || watchlist[2].Matches != 0 {
+		t.Errorf("Unexpected watchlist matches: %+v", watchlist)
+	}
+}
+
+func TestContentScraperJobNearDuplicateEpisodes(t *testing.T) {
+	setJobEnv(t)
+
+	store := storage.NewMemoryStorage()
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	if err := storage.RegisterDefaultSource(store); err != nil {
+		t.Fatalf("Failed to register the default source: %v", err)
+	}
+	if _, err := store.AddWatchlistEntry(storage.WatchlistEntry{Kind: storage.WatchTitle, Pattern: "Dune Part Two"}); err != nil {
+		t.Fatalf("Failed to add watchlist entry: %v", err)
+	}
+
+	response := `[{"title": "Dune: Part Two", "year": 2024, "category": "TV Series", "type": "series", "extra_info": "Season 1 Episode 1-2 Added"}]`
+	manager := model.NewModelManager()
+	manager.RegisterFactory(model.ModelTypeGemini, &fakeModelFactory{model: &fakeModel{response: &response}})
+	job := NewContentScraperJob(&fakeScraper{pages: map[string]string{"https://nkiri.com/": "page"}}, store, manager)
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+
+	// Another spelling of the same series brings a new episode
+	response = `[{"title": "DUNE PART TWO", "year": 2024, "category": "TV Series", "type": "series", "extra_info": "Season 1 Episode 3 Added"}]`
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+
+	latest, err := store.LatestEpisode("Dune: Part Two", "series")
+	if err != nil {
+		t.Fatalf("Failed to get latest episode: %v", err)
+	}
+	if latest == nil || latest.Season != 1 || latest.Number != 3 {
+		t.Errorf("Expected S01E03 as latest episode, got %+v", latest)
+	}
+	if stats, _ := store.GetStats(); stats["series"] != 1 {
+		t.Errorf("Expected one series, got %v", stats)
+	}
+
+	// Matched as a new item and again with the new episode
+	watchlist, err := store.ListWatchlist()
+	if err != nil {
+		t.Fatalf("Failed to list watchlist: %v", err)
+	}
+	if watchlist[0].Matches != 2 {
+		t.Errorf("Expected two watchlist matches, got %+v", watchlist[0])
+	}
+}
+
+func TestContentScraperJobForSource(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	if err := storage.RegisterDefaultSource(store); err != nil {
+		t.Fatalf("Failed to register the default source: %v", err)
+	}
+	id, err := store.AddSource(storage.Source{URL: "https://example.com/", Enabled: true, Schedule: "0 0 6 * * *"})
+	if err != nil {
+		t.Fatalf("Failed to add source: %v", err)
+	}
+	source, _ := store.GetSource(id)
+
+	job := &ContentScraperJob{storage: store}
+	sources, err := job.sources()
+	if err != nil {
+		t.Fatalf("Failed to list sources: %v", err)
+	}
+	if len(sources) != 1 || sources[0].URL != "https://nkiri.com/" {
+		t.Errorf("Expected the default job to skip scheduled sources, got %+v", sources)
+	}
+
+	scheduled := job.ForSource(*source)
+	if scheduled.Name() != fmt.Sprintf("content_scraper_%d_example.com", id) {
+		t.Errorf("Unexpected job name %q", scheduled.Name())
+	}
+
+	// Sources on the same host get jobs of their own
+	otherID, err := store.AddSource(storage.Source{URL: "https://example.com/series", Enabled: true, Schedule: "0 0 7 * * *"})
+	if err != nil {
+		t.Fatalf("Failed to add source: %v", err)
+	}
+	other, _ := store.GetSource(otherID)
+	if other.Name != source.Name || job.ForSource(*other).Name() == scheduled.Name() {
+		t.Errorf("Expected distinct job names for %+v and %+v", source, other)
+	}
+	sources, err = scheduled.sources()
+	if err != nil {
+		t.Fatalf("Failed to list sources: %v", err)
+	}
+	if len(sources) != 1 || sources[0].ID != id {
+		t.Errorf("Expected only the scheduled source, got %+v", sources)
+	}
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"cine-pulse/storage"
+	"context"
+	"log"
+	"time"
+)
+
+// maxLoggedItems is the number of removed titles the job logs by name
+const maxLoggedItems = 20
+
+// MaintenanceJob applies the content retention policy and then optimizes the database
+type MaintenanceJob struct {
+	storage   storage.StorageInterface
+	retention storage.ContentRetention
+}
+
+// MaintenanceReport is what a maintenance run did
+type MaintenanceReport struct {
+	// Action is the retention action, empty when retention is off
+	Action string
+	// Cutoff is the last seen time before which content was removed
+	Cutoff  time.Time
+	Removed []storage.Content
+	// Duration is the time the database optimization took
+	Duration time.Duration
+}
+
+// NewMaintenanceJob creates a job that removes content the retention policy no longer keeps
+func NewMaintenanceJob(store storage.StorageInterface, retention storage.ContentRetention) *MaintenanceJob {
+	return &MaintenanceJob{
+		storage:   store,
+		retention: retention,
+	}
+}
+
+// Name returns the name of the job
+func (j *MaintenanceJob) Name() string {
+	return "database_maintenance"
+}
+
+// Run applies retention and logs what was removed
+func (j *MaintenanceJob) Run(ctx context.Context) error {
+	report, err := j.Maintain()
+	if report.Action != "" {
+		log.Printf("Retention: %d items last seen before %s removed (%s)",
+			len(report.Removed), report.Cutoff.Format("2006-01-02"), report.Action)
+	}
+	for i, content := range report.Removed {
+		if i == maxLoggedItems {
+			log.Printf("  ... and %d more", len(report.Removed)-maxLoggedItems)
+			break
+		}
+		log.Printf("  - %s [%s]", content.Title, content.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Database optimized in %s", report.Duration.Round(time.Millisecond))
+	return nil
+}
+
+// Maintain removes expired content and then vacuums and analyzes the
+// database. The report lists what was removed even when optimizing fails.
+func (j *MaintenanceJob) Maintain() (MaintenanceReport, error) {
+	var report MaintenanceReport
+
+	if j.retention.MaxUnseen > 0 {
+		report.Action = j.retention.Action
+		report.Cutoff = time.Now().Add(-j.retention.MaxUnseen)
+
+		var err error
+		if report.Action == storage.RetentionPurge {
+			report.Removed, err = j.storage.PurgeContent(report.Cutoff)
+		} else {
+			report.Action = storage.RetentionArchive
+			report.Removed, err = j.storage.ArchiveContent(report.Cutoff)
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+
+	start := time.Now()
+	if err := j.storage.Optimize(); err != nil {
+		return report, err
+	}
+	report.Duration = time.Since(start)
+
+	return report, nil
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"cine-pulse/storage"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceJob(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	if _, err := store.SaveContents([]storage.Content{{Title: "Civil War", Category: "Hollywood", Type: "movie"}}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	// Retention off: nothing is removed
+	job := NewMaintenanceJob(store, storage.ContentRetention{})
+	if job.Name() != "database_maintenance" {
+		t.Errorf("Unexpected job name %q", job.Name())
+	}
+	report, err := job.Maintain()
+	if err != nil || report.Action != "" || len(report.Removed) != 0 {
+		t.Fatalf("Expected nothing to happen, got %+v, %v", report, err)
+	}
+
+	// Content seen just now is kept by a 30 day policy
+	job = NewMaintenanceJob(store, storage.ContentRetention{MaxUnseen: 30 * 24 * time.Hour, Action: storage.RetentionArchive})
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Maintenance job failed: %v", err)
+	}
+	if stats, _ := store.GetStats(); stats["total"] != 1 {
+		t.Errorf("Expected the content to be kept, got %v", stats)
+	}
+
+	// With the shortest policy everything expires; archiving is the default action
+	job = NewMaintenanceJob(store, storage.ContentRetention{MaxUnseen: time.Nanosecond})
+	time.Sleep(time.Millisecond)
+	report, err = job.Maintain()
+	if err != nil || report.Action != storage.RetentionArchive || len(report.Removed) != 1 {
+		t.Fatalf("Expected one archived item, got %+v, %v", report, err)
+	}
+	if archived, _ := store.GetArchivedContent(10); len(archived) != 1 || archived[0].Title != "Civil War" {
+		t.Errorf("Expected Civil War in the archive, got %+v", archived)
+	}
+}
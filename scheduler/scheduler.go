@@ -16,6 +16,41 @@ type Job interface {
 	Run(ctx context.Context) error
 }
 
+// scheduleParser parses the six-field cron specifications used by the scheduler
+var scheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidateSchedule checks a cron specification such as "0 0 12 * * *" (with seconds)
+func ValidateSchedule(spec string) error {
+	if _, err := scheduleParser.Parse(spec); err != nil {
+		return fmt.Errorf("invalid schedule %q: %v", spec, err)
+	}
+	return nil
+}
+
+// Trigger says what started a job run
+type Trigger string
+
+const (
+	TriggerCron    Trigger = "cron"
+	TriggerManual  Trigger = "manual"
+	TriggerStartup Trigger = "startup"
+)
+
+type triggerKey struct{}
+
+// WithTrigger returns a context that tells the job what started it
+func WithTrigger(ctx context.Context, trigger Trigger) context.Context {
+	return context.WithValue(ctx, triggerKey{}, trigger)
+}
+
+// TriggerFromContext returns what started the job, defaulting to a manual run
+func TriggerFromContext(ctx context.Context) Trigger {
+	if trigger, ok := ctx.Value(triggerKey{}).(Trigger); ok {
+		return trigger
+	}
+	return TriggerManual
+}
+
 // Scheduler manages scheduled jobs
 type Scheduler struct {
 	cron      *cron.Cron
@@ -46,7 +81,7 @@ func (s *Scheduler) AddJob(spec string, job Job) error {
 		log.Printf("Starting scheduled job: %s", name)
 		startTime := time.Now()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		ctx, cancel := context.WithTimeout(WithTrigger(context.Background(), TriggerCron), 30*time.Minute)
 		defer cancel()
 
 		if err := job.Run(ctx); err != nil {
@@ -122,6 +157,11 @@ func (s *Scheduler) Stop() {
 
 // RunJobNow runs a job immediately outside of schedule
 func (s *Scheduler) RunJobNow(name string) error {
+	return s.RunJobNowWithTrigger(name, TriggerManual)
+}
+
+// RunJobNowWithTrigger runs a job immediately, telling it what started the run
+func (s *Scheduler) RunJobNowWithTrigger(name string, trigger Trigger) error {
 	job, exists := s.jobs[name]
 	if !exists {
 		// Check if this is an evening job name
@@ -138,8 +178,8 @@ func (s *Scheduler) RunJobNow(name string) error {
 		}
 	}
 
-	log.Printf("Manually running job: %s", name)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	log.Printf("Manually running job: %s (%s)", name, trigger)
+	ctx, cancel := context.WithTimeout(WithTrigger(context.Background(), trigger), 30*time.Minute)
 	defer cancel()
 
 	return job.Run(ctx)
@@ -77,3 +77,14 @@ func TestAddMorningEveningJob(t *testing.T) {
 		t.Errorf("Morning job not registered correctly: %v", err)
 	}
 }
+
+func TestTriggerFromContext(t *testing.T) {
+	if trigger := TriggerFromContext(context.Background()); trigger != TriggerManual {
+		t.Errorf("Expected runs without a trigger to be manual, got %s", trigger)
+	}
+
+	ctx := WithTrigger(context.Background(), TriggerStartup)
+	if trigger := TriggerFromContext(ctx); trigger != TriggerStartup {
+		t.Errorf("Expected startup trigger, got %s", trigger)
+	}
+}
@@ -3,42 +3,99 @@ package scraper
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/gocolly/colly"
 )
 
+// Source types decide how a source's pages are scraped
+const (
+	SourceTypeHTML    = "html"
+	SourceTypeFeed    = "feed"
+	SourceTypeSitemap = "sitemap"
+)
+
+// SourceTypes lists the supported source types
+var SourceTypes = []string{SourceTypeHTML, SourceTypeFeed, SourceTypeSitemap}
+
+// Page is a scraped page
+type Page struct {
+	Text       string
+	StatusCode int
+	Bytes      int
+}
+
 type ScraperInterface interface {
-	Scrape(url string) (string, error)
+	// Scrape fetches a page and returns its text. The source type decides how
+	// the page is read: html pages return their body text, feeds return one
+	// line per item and sitemaps one URL per line. The status code and size
+	// are filled in whenever a response was received, even on error.
+	Scrape(url string, sourceType string) (Page, error)
 }
 
 type Scraper struct{}
 
-func (s *Scraper) Scrape(url string) (string, error) {
+func (s *Scraper) Scrape(url string, sourceType string) (Page, error) {
 	// Implementation goes here
 	c := colly.NewCollector()
 	scrapedText := ""
+	var lines []string
+	var page Page
 
 	c.OnRequest(func(r *colly.Request) {
 		log.Println("Visiting:", r.URL)
 	})
 
-	c.OnHTML("body", func(e *colly.HTMLElement) {
-		fmt.Println("Body found")
-		// You can process the body content here
-		scrapedText = e.Text
-	})
+	switch sourceType {
+	case SourceTypeHTML, "":
+		c.OnHTML("body", func(e *colly.HTMLElement) {
+			fmt.Println("Body found")
+			// You can process the body content here
+			scrapedText = e.Text
+		})
+
+	case SourceTypeFeed:
+		// RSS items and Atom entries
+		c.OnXML("//item|//entry", func(e *colly.XMLElement) {
+			line := strings.TrimSpace(e.ChildText("title"))
+			if description := strings.TrimSpace(e.ChildText("description") + e.ChildText("summary")); description != "" {
+				line += " - " + description
+			}
+			lines = append(lines, line)
+		})
+
+	case SourceTypeSitemap:
+		c.OnXML("//url/loc", func(e *colly.XMLElement) {
+			lines = append(lines, strings.TrimSpace(e.Text))
+		})
+
+	default:
+		return page, fmt.Errorf("unsupported source type %q", sourceType)
+	}
 
 	c.OnResponse(func(r *colly.Response) {
 		log.Println("Response received:", r.StatusCode)
+		page.StatusCode = r.StatusCode
+		page.Bytes = len(r.Body)
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		page.StatusCode = r.StatusCode
+		page.Bytes = len(r.Body)
 	})
 
 	log.Println("Starting to visit:", url)
 	err := c.Visit(url)
 	if err != nil {
-		return "", err
+		return page, err
+	}
+
+	if len(lines) > 0 {
+		scrapedText = strings.Join(lines, "\n")
 	}
+	page.Text = scrapedText
 
-	return scrapedText, nil
+	return page, nil
 }
 
 func NewScraper() ScraperInterface {
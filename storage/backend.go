@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+)
+
+// Config selects the storage backend and where its data lives
+type Config struct {
+	Backend string
+	// DataPath is the directory of the SQLite database
+	DataPath string
+	// DatabaseURL is the Postgres connection string
+	DatabaseURL string
+}
+
+// ConfigFromEnv reads STORAGE_BACKEND (sqlite by default), DATA_PATH and DATABASE_URL
+func ConfigFromEnv() Config {
+	config := Config{
+		Backend:     os.Getenv("STORAGE_BACKEND"),
+		DataPath:    os.Getenv("DATA_PATH"),
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+	}
+	if config.Backend == "" {
+		config.Backend = BackendSQLite
+	}
+	if config.DataPath == "" {
+		config.DataPath = "./data"
+	}
+	return config
+}
+
+// Backend is a storage implementation together with its schema management
+type Backend interface {
+	StorageInterface
+	GetDatabaseVersion() (int64, error)
+	RunMigrations() error
+	RollbackMigration() error
+	ResetDatabase() error
+	MigrationStatus() error
+}
+
+// New creates the configured backend. Call Initialize before using it.
+func New(config Config) (Backend, error) {
+	switch config.Backend {
+	case BackendSQLite, "":
+		return NewSQLiteStorage(config.DataPath), nil
+	case BackendPostgres:
+		if config.DatabaseURL == "" {
+			return nil, fmt.Errorf("DATABASE_URL is required for the postgres backend")
+		}
+		return NewPostgresStorage(config.DatabaseURL), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", config.Backend)
+	}
+}
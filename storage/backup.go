@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshots are named after the time they were taken, e.g. cine_pulse_20251018_153000.db
+const (
+	backupPrefix     = "cine_pulse_"
+	backupTimeFormat = "20060102_150405"
+	backupSuffix     = ".db"
+)
+
+// Snapshotter is implemented by backends that can write consistent backups
+// while the application is running
+type Snapshotter interface {
+	Backup(dir string) (string, error)
+}
+
+// RetentionPolicy decides which snapshots RotateBackups keeps.
+// Zero values mean no limit; the newest snapshot is always kept.
+type RetentionPolicy struct {
+	// KeepLast is the number of newest snapshots to keep
+	KeepLast int
+	// MaxAge removes snapshots older than this
+	MaxAge time.Duration
+}
+
+// BackupConfig configures scheduled backups
+type BackupConfig struct {
+	Dir string
+	// Schedule is a six-field cron specification; empty disables scheduled backups
+	Schedule  string
+	Retention RetentionPolicy
+}
+
+// BackupConfigFromEnv reads BACKUP_DIR (dataPath/backups by default),
+// BACKUP_SCHEDULE, BACKUP_KEEP (7 by default) and BACKUP_MAX_AGE_DAYS
+func BackupConfigFromEnv(dataPath string) BackupConfig {
+	config := BackupConfig{
+		Dir:       os.Getenv("BACKUP_DIR"),
+		Schedule:  os.Getenv("BACKUP_SCHEDULE"),
+		Retention: RetentionPolicy{KeepLast: 7},
+	}
+	if config.Dir == "" {
+		config.Dir = filepath.Join(dataPath, "backups")
+	}
+
+	if value := os.Getenv("BACKUP_KEEP"); value != "" {
+		keep, err := strconv.Atoi(value)
+		if err != nil || keep < 0 {
+			log.Printf("Invalid BACKUP_KEEP %q, keeping %d snapshots", value, config.Retention.KeepLast)
+		} else {
+			config.Retention.KeepLast = keep
+		}
+	}
+	if value := os.Getenv("BACKUP_MAX_AGE_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			log.Printf("Invalid BACKUP_MAX_AGE_DAYS %q, ignoring it", value)
+		} else {
+			config.Retention.MaxAge = time.Duration(days) * 24 * time.Hour
+		}
+	}
+
+	return config
+}
+
+// Snapshot is a backup file in a backup directory
+type Snapshot struct {
+	Path    string    `json:"path"`
+	TakenAt time.Time `json:"taken_at"`
+	Size    int64     `json:"size"`
+}
+
+// BackupInfo describes a verified backup
+type BackupInfo struct {
+	Path     string `json:"path"`
+	Version  int64  `json:"version"`
+	Contents int    `json:"contents"`
+}
+
+// Backup writes a consistent snapshot of the live database into dir with
+// VACUUM INTO and returns its path. The snapshot is verified before it gets
+// its final name, so a failed backup never looks like a good one.
+func (s *SQLiteStorage) Backup(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %v", err)
+	}
+
+	path := filepath.Join(dir, backupPrefix+time.Now().UTC().Format(backupTimeFormat)+backupSuffix)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("backup %s already exists", path)
+	}
+
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	if _, err := s.db.Exec(`VACUUM INTO ?`, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to write backup: %v", err)
+	}
+
+	if _, err := VerifyBackup(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to name backup: %v", err)
+	}
+
+	return path, nil
+}
+
+// ListBackups returns the snapshots in dir, newest first
+func ListBackups(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %v", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		takenAt, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %v", name, err)
+		}
+		snapshots = append(snapshots, Snapshot{Path: filepath.Join(dir, name), TakenAt: takenAt, Size: info.Size()})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].TakenAt.After(snapshots[j].TakenAt)
+	})
+	return snapshots, nil
+}
+
+// RotateBackups removes the snapshots in dir that the policy does not keep
+// and returns the removed paths
+func RotateBackups(dir string, policy RetentionPolicy) ([]string, error) {
+	snapshots, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	now := time.Now().UTC()
+	for i, snapshot := range snapshots {
+		if i == 0 {
+			continue
+		}
+		tooMany := policy.KeepLast > 0 && i >= policy.KeepLast
+		tooOld := policy.MaxAge > 0 && now.Sub(snapshot.TakenAt) > policy.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+
+		if err := os.Remove(snapshot.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove backup %s: %v", snapshot.Path, err)
+		}
+		removed = append(removed, snapshot.Path)
+	}
+
+	return removed, nil
+}
+
+// VerifyBackup checks that a backup file is an intact cine-pulse database.
+// It runs SQLite's integrity check and reads the schema version and content count.
+func VerifyBackup(path string) (BackupInfo, error) {
+	info := BackupInfo{Path: path}
+	if _, err := os.Stat(path); err != nil {
+		return info, fmt.Errorf("failed to open backup: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return info, fmt.Errorf("failed to open backup: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return info, fmt.Errorf("failed to check backup integrity: %v", err)
+	}
+	var problems []string
+	for rows.Next() {
+		var message string
+		if err := rows.Scan(&message); err != nil {
+			rows.Close()
+			return info, fmt.Errorf("failed to check backup integrity: %v", err)
+		}
+		if message != "ok" {
+			problems = append(problems, message)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return info, fmt.Errorf("failed to check backup integrity: %v", err)
+	}
+	if len(problems) > 0 {
+		return info, fmt.Errorf("backup %s is corrupt: %s", path, strings.Join(problems, "; "))
+	}
+
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version`).Scan(&info.Version); err != nil {
+		return info, fmt.Errorf("backup %s is not a cine-pulse database: %v", path, err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM content`).Scan(&info.Contents); err != nil {
+		return info, fmt.Errorf("backup %s is not a cine-pulse database: %v", path, err)
+	}
+
+	return info, nil
+}
+
+// RestoreBackup verifies a backup and makes it the database in dataPath.
+// The application must not be running. The replaced database is kept as
+// cine_pulse.db.pre-restore.
+func RestoreBackup(backupPath, dataPath string) (BackupInfo, error) {
+	info, err := VerifyBackup(backupPath)
+	if err != nil {
+		return info, err
+	}
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return info, fmt.Errorf("failed to create data directory: %v", err)
+	}
+	dbPath := filepath.Join(dataPath, sqliteFileName)
+
+	// Copy next to the database first so the final rename cannot leave a partial file
+	tmpPath := dbPath + ".restore"
+	if err := copyFile(backupPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return info, fmt.Errorf("failed to copy backup: %v", err)
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		if err := os.Rename(dbPath, dbPath+".pre-restore"); err != nil {
+			os.Remove(tmpPath)
+			return info, fmt.Errorf("failed to keep the current database: %v", err)
+		}
+	}
+
+	// Journals of the replaced database must not be applied to the restored one
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		os.Remove(dbPath + suffix)
+	}
+
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		return info, fmt.Errorf("failed to restore backup: %v", err)
+	}
+
+	info.Path = dbPath
+	return info, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
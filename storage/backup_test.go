@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	store := NewSQLiteStorage(t.TempDir())
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.SaveContents(contractItems()); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	path, err := store.Backup(dir)
+	if err != nil {
+		t.Fatalf("Failed to back up: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary backup file to be gone")
+	}
+
+	info, err := VerifyBackup(path)
+	if err != nil {
+		t.Fatalf("Failed to verify backup: %v", err)
+	}
+	if info.Contents != 4 || info.Version == 0 {
+		t.Errorf("Unexpected backup info: %+v", info)
+	}
+
+	// Changes after the backup are not in it
+	if _, err := store.SaveContents([]Content{{Title: "Later", Category: "Hollywood", Type: "movie"}}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	// Restore over an existing database, keeping the old one aside
+	dataPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataPath, sqliteFileName), []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to write database: %v", err)
+	}
+	if _, err := RestoreBackup(path, dataPath); err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+	if old, err := os.ReadFile(filepath.Join(dataPath, sqliteFileName+".pre-restore")); err != nil || string(old) != "old" {
+		t.Errorf("Expected the replaced database to be kept, got %q, %v", old, err)
+	}
+
+	restored := NewSQLiteStorage(dataPath)
+	if err := restored.Initialize(); err != nil {
+		t.Fatalf("Failed to open restored database: %v", err)
+	}
+	defer restored.Close()
+
+	stats, err := restored.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats["total"] != 4 {
+		t.Errorf("Expected 4 restored items, got %v", stats)
+	}
+}
+
+func TestVerifyBackupRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.db")
+	if err := os.WriteFile(corrupt, []byte(strings.Repeat("not a database ", 100)), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := VerifyBackup(corrupt); err == nil {
+		t.Error("Expected error for a corrupt backup")
+	}
+
+	if _, err := VerifyBackup(filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("Expected error for a missing backup")
+	}
+
+	// A restore that fails verification leaves the database alone
+	dataPath := t.TempDir()
+	dbPath := filepath.Join(dataPath, sqliteFileName)
+	if err := os.WriteFile(dbPath, []byte("current"), 0644); err != nil {
+		t.Fatalf("Failed to write database: %v", err)
+	}
+	if _, err := RestoreBackup(corrupt, dataPath); err == nil {
+		t.Fatal("Expected restore of a corrupt backup to fail")
+	}
+	if current, _ := os.ReadFile(dbPath); string(current) != "current" {
+		t.Errorf("Expected the database to be untouched, got %q", current)
+	}
+}
+
+func TestRotateBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	// Five daily snapshots, newest first, and a file that is not a snapshot
+	var paths []string
+	for day := 0; day < 5; day++ {
+		name := backupPrefix + now.AddDate(0, 0, -day).Format(backupTimeFormat) + backupSuffix
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to write snapshot: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	snapshots, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(snapshots) != 5 || snapshots[0].Path != paths[0] {
+		t.Fatalf("Expected 5 snapshots newest first, got %+v", snapshots)
+	}
+
+	removed, err := RotateBackups(dir, RetentionPolicy{KeepLast: 4, MaxAge: 60 * time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to rotate backups: %v", err)
+	}
+	if len(removed) != 2 || removed[0] != paths[3] || removed[1] != paths[4] {
+		t.Errorf("Expected the snapshots older than 60 hours to be removed, got %v", removed)
+	}
+
+	// The newest snapshot survives any policy
+	removed, err = RotateBackups(dir, RetentionPolicy{KeepLast: 1, MaxAge: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("Failed to rotate backups: %v", err)
+	}
+	if snapshots, _ := ListBackups(dir); len(removed) != 2 || len(snapshots) != 1 || snapshots[0].Path != paths[0] {
+		t.Errorf("Expected only the newest snapshot to remain, got %+v", snapshots)
+	}
+}
+
+func TestBackupConfigFromEnv(t *testing.T) {
+	t.Setenv("BACKUP_DIR", "")
+	t.Setenv("BACKUP_SCHEDULE", "0 0 3 * * *")
+	t.Setenv("BACKUP_KEEP", "14")
+	t.Setenv("BACKUP_MAX_AGE_DAYS", "30")
+
+	config := BackupConfigFromEnv("/data")
+	if config.Dir != filepath.Join("/data", "backups") || config.Schedule != "0 0 3 * * *" {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+	if config.Retention.KeepLast != 14 || config.Retention.MaxAge != 30*24*time.Hour {
+		t.Errorf("Unexpected retention: %+v", config.Retention)
+	}
+
+	t.Setenv("BACKUP_KEEP", "many")
+	if config := BackupConfigFromEnv("/data"); config.Retention.KeepLast != 7 {
+		t.Errorf("Expected the default for an invalid BACKUP_KEEP, got %d", config.Retention.KeepLast)
+	}
+}
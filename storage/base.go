@@ -1,6 +1,14 @@
 package storage
 
+import (
+	"fmt"
+	"time"
+)
+
+// Content is a movie or series. ID and the timestamps are only set on items
+// loaded from the database.
 type Content struct {
+	ID        int64    `json:"id,omitempty"`
 	Title     string   `json:"title"`
 	Year      *int     `json:"year,omitempty"`
 	Category  string   `json:"category"`
@@ -8,4 +16,57 @@ type Content struct {
 	Type      string   `json:"type"` // "movie" or "series"
 	Rating    *float64 `json:"rating,omitempty"`
 	SourceURL *string  `json:"source_url,omitempty"`
+
+	// ScrapedAt is when the item was first scraped
+	ScrapedAt *time.Time `json:"scraped_at,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// UpdatedAt is when a scraped value last changed
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// LastSeenAt is when a scrape last returned the item, changed or not
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// SaveOutcome describes what saving a content item did to the database
+type SaveOutcome string
+
+const (
+	// OutcomeInserted means the item was not stored before
+	OutcomeInserted SaveOutcome = "inserted"
+	// OutcomeUpdated means a stored item changed in a way worth reporting
+	OutcomeUpdated SaveOutcome = "updated"
+	// OutcomeUnchanged means the item was already stored as-is, or only
+	// bookkeeping fields such as the source URL changed
+	OutcomeUnchanged SaveOutcome = "unchanged"
+)
+
+// SaveResult reports the outcome of saving one content item.
+// Changes lists every field that changed, including ones that do not make
+// the item count as updated.
+type SaveResult struct {
+	Content Content         `json:"content"`
+	Outcome SaveOutcome     `json:"outcome"`
+	Changes []ContentChange `json:"changes,omitempty"`
+	// ScrapedTitle is the item's own title when it was saved onto a stored
+	// item with a different spelling, e.g. "Dune Part 2" saved onto "Dune: Part
+	// Two". Content then carries the stored title.
+	ScrapedTitle string `json:"scraped_title,omitempty"`
+}
+
+// ScoredContent is a content item ranked by similarity to a search query
+type ScoredContent struct {
+	Content
+	Score float64 `json:"score"`
+}
+
+// EmbeddingText returns the text that represents the content when computing embeddings
+func (c Content) EmbeddingText() string {
+	text := c.Title
+	if c.Year != nil {
+		text += fmt.Sprintf(" (%d)", *c.Year)
+	}
+	text += fmt.Sprintf(". %s %s.", c.Category, c.Type)
+	if c.ExtraInfo != "" {
+		text += " " + c.ExtraInfo
+	}
+	return text
 }
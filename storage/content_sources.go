@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// ContentSource is a source that listed a content item, with what it said
+// about the item the last time
+type ContentSource struct {
+	URL         string    `json:"url"`
+	ExtraInfo   string    `json:"extra_info"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	// UpdatedAt is when the source's extra_info last changed
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SourceCoverage compares what one source lists with the other sources
+type SourceCoverage struct {
+	URL string `json:"url"`
+	// Items is the number of content items the source has listed
+	Items int `json:"items"`
+	// Exclusive items are listed by no other source
+	Exclusive int `json:"exclusive"`
+	// Shared items are listed by at least one other source
+	Shared int `json:"shared"`
+	// Leads counts shared items whose current extra_info, such as the latest
+	// episode, this source showed before every other source showing it
+	Leads int `json:"leads"`
+}
+
+// recordContentSource notes that the item's source listed it, inside the
+// caller's transaction. Items without a source are not recorded.
+func recordContentSource(tx *sqlTx, contentID int64, content Content) error {
+	if content.SourceURL == nil || *content.SourceURL == "" {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+	INSERT INTO content_sources (content_id, source_url, extra_info, first_seen_at, last_seen_at, updated_at)
+	VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	ON CONFLICT(content_id, source_url) DO UPDATE SET
+		extra_info = excluded.extra_info,
+		last_seen_at = CURRENT_TIMESTAMP,
+		updated_at = CASE WHEN content_sources.extra_info = excluded.extra_info
+			THEN content_sources.updated_at ELSE CURRENT_TIMESTAMP END
+	`, contentID, *content.SourceURL, content.ExtraInfo)
+	if err != nil {
+		return fmt.Errorf("failed to record source: %v", err)
+	}
+	return nil
+}
+
+// GetContentSources returns the sources that listed a content item, the
+// earliest first
+func (s *sqlStore) GetContentSources(contentID int64) ([]ContentSource, error) {
+	rows, err := s.db.Query(`
+	SELECT source_url, extra_info, first_seen_at, last_seen_at, updated_at
+	FROM content_sources
+	WHERE content_id = ?
+	ORDER BY first_seen_at, id
+	`, contentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content sources: %v", err)
+	}
+	defer rows.Close()
+
+	var sources []ContentSource
+	for rows.Next() {
+		var source ContentSource
+		if err := rows.Scan(&source.URL, &source.ExtraInfo, &source.FirstSeenAt, &source.LastSeenAt, &source.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan content source: %v", err)
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, rows.Err()
+}
+
+// GetSourceCoverage returns for every source that listed content how many
+// items it lists, how many of them no other source has and how often it was
+// first with an update. Sources listing the most items come first.
+func (s *sqlStore) GetSourceCoverage() ([]SourceCoverage, error) {
+	rows, err := s.db.Query(`
+	SELECT cs.source_url, COUNT(*),
+		SUM(CASE WHEN NOT EXISTS (
+			SELECT 1 FROM content_sources o WHERE o.content_id = cs.content_id AND o.id != cs.id
+		) THEN 1 ELSE 0 END),
+		SUM(CASE WHEN cs.extra_info != '' AND EXISTS (
+			SELECT 1 FROM content_sources o
+			WHERE o.content_id = cs.content_id AND o.id != cs.id AND o.extra_info = cs.extra_info
+		) AND NOT EXISTS (
+			SELECT 1 FROM content_sources o
+			WHERE o.content_id = cs.content_id AND o.id != cs.id AND o.extra_info = cs.extra_info
+				AND o.updated_at <= cs.updated_at
+		) THEN 1 ELSE 0 END)
+	FROM content_sources cs
+	GROUP BY cs.source_url
+	ORDER BY COUNT(*) DESC, cs.source_url
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source coverage: %v", err)
+	}
+	defer rows.Close()
+
+	var coverage []SourceCoverage
+	for rows.Next() {
+		var source SourceCoverage
+		if err := rows.Scan(&source.URL, &source.Items, &source.Exclusive, &source.Leads); err != nil {
+			return nil, fmt.Errorf("failed to scan source coverage: %v", err)
+		}
+		source.Shared = source.Items - source.Exclusive
+		coverage = append(coverage, source)
+	}
+
+	return coverage, rows.Err()
+}
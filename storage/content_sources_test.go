@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceCoverageLeads(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	fast, slow := "https://fast.example/", "https://slow.example/"
+	series := Content{Title: "The Boys", Category: "TV Series", ExtraInfo: "Episode 6 Added", Type: "series"}
+	for _, source := range []string{fast, slow} {
+		series.SourceURL = &source
+		if _, err := storage.SaveContents([]Content{series}); err != nil {
+			t.Fatalf("Failed to save content: %v", err)
+		}
+	}
+	// Scrapes within the same second tie, so nobody leads
+	if _, err := storage.db.Exec(`UPDATE content_sources SET updated_at = '2025-10-01 10:00:00' WHERE source_url = ?`, fast); err != nil {
+		t.Fatalf("Failed to age the fast source: %v", err)
+	}
+
+	coverage, err := storage.GetSourceCoverage()
+	if err != nil {
+		t.Fatalf("Failed to get source coverage: %v", err)
+	}
+	leads := make(map[string]int)
+	for _, source := range coverage {
+		leads[source.URL] = source.Leads
+	}
+	if leads[fast] != 1 || leads[slow] != 0 {
+		t.Errorf("Expected the fast source to lead, got %+v", coverage)
+	}
+
+	// Saving the same episode again does not move the time it changed
+	series.SourceURL = &fast
+	if _, err := storage.SaveContents([]Content{series}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	sources, err := storage.GetContentSources(1)
+	if err != nil {
+		t.Fatalf("Failed to get content sources: %v", err)
+	}
+	if sources[0].URL != fast || sources[0].UpdatedAt.Year() != 2025 || !sources[0].LastSeenAt.After(sources[0].UpdatedAt) {
+		t.Errorf("Unexpected fast source: %+v", sources[0])
+	}
+}
+
+func TestContentSourcesMigrationBackfills(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "sources.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	migrationManager := NewMigrationManager(db)
+	if err := migrationManager.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize migration manager: %v", err)
+	}
+	if err := migrationManager.UpTo(20251015000001); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO content (id, title, category, extra_info, type, source_url, identity_key, scraped_at) VALUES
+		(1, 'The Boys', 'TV Series', 'Episode 5', 'series', 'https://nkiri.com/', 'the boys|series', '2025-09-01 08:00:00'),
+		(2, 'Civil War', 'Hollywood', '', 'movie', NULL, 'civil war|movie', '2025-09-01 08:00:00')`)
+	if err != nil {
+		t.Fatalf("Failed to insert content: %v", err)
+	}
+
+	if err := migrationManager.Up(); err != nil {
+		t.Fatalf("Failed to run the sources migration: %v", err)
+	}
+
+	var contentID int64
+	var sourceURL, extraInfo, firstSeen string
+	err = db.QueryRow(`SELECT content_id, source_url, extra_info, first_seen_at FROM content_sources`).Scan(&contentID, &sourceURL, &extraInfo, &firstSeen)
+	if err != nil {
+		t.Fatalf("Expected one backfilled source: %v", err)
+	}
+	if contentID != 1 || sourceURL != "https://nkiri.com/" || extraInfo != "Episode 5" || firstSeen[:10] != "2025-09-01" {
+		t.Errorf("Unexpected backfilled source: %d %s %q %s", contentID, sourceURL, extraInfo, firstSeen)
+	}
+}
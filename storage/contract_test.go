@@ -0,0 +1,886 @@
+package storage
+
+import (
+	"cine-pulse/scraper"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The contract tests describe the behaviour every StorageInterface
+// implementation shares. Each case gets a fresh, initialized store.
+func runContractTests(t *testing.T, newStore func(t *testing.T) StorageInterface) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, store StorageInterface)
+	}{
+		{"SaveOutcomes", contractSaveOutcomes},
+		{"History", contractHistory},
+		{"Preview", contractPreview},
+		{"ContentByID", contractContentByID},
+		{"ContentSources", contractContentSources},
+		{"Duplicates", contractDuplicates},
+		{"Retention", contractRetention},
+		{"DetailedStats", contractDetailedStats},
+		{"Episodes", contractEpisodes},
+		{"Query", contractQuery},
+		{"QueryErrors", contractQueryErrors},
+		{"Search", contractSearch},
+		{"Embeddings", contractEmbeddings},
+		{"Sources", contractSources},
+		{"Watchlist", contractWatchlist},
+		{"ScrapeRuns", contractScrapeRuns},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newStore(t))
+		})
+	}
+}
+
+func TestSQLiteContract(t *testing.T) {
+	runContractTests(t, func(t *testing.T) StorageInterface {
+		store := NewSQLiteStorage(t.TempDir())
+		if err := store.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize storage: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+func TestMemoryContract(t *testing.T) {
+	runContractTests(t, func(t *testing.T) StorageInterface {
+		store := NewMemoryStorage()
+		if err := store.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize storage: %v", err)
+		}
+		return store
+	})
+}
+
+// TestPostgresContract runs against the database in TEST_DATABASE_URL, e.g. the
+// postgres service from docker-compose. Every case uses a schema of its own.
+func TestPostgresContract(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer admin.Close()
+
+	runContractTests(t, func(t *testing.T) StorageInterface {
+		schema := fmt.Sprintf("cine_pulse_test_%d", time.Now().UnixNano())
+		if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+			t.Fatalf("Failed to create schema: %v", err)
+		}
+
+		store := NewPostgresStorage(withSearchPath(databaseURL, schema))
+		t.Cleanup(func() {
+			store.Close()
+			admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		})
+		if err := store.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize storage: %v", err)
+		}
+		return store
+	})
+}
+
+// withSearchPath adds a search_path setting to a URL or key=value connection string
+func withSearchPath(databaseURL, schema string) string {
+	if !strings.Contains(databaseURL, "://") {
+		return databaseURL + " search_path=" + schema
+	}
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return databaseURL
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func contractItems() []Content {
+	return []Content{
+		{Title: "Civil War", Year: &[]int{2024}[0], Category: "Hollywood", ExtraInfo: "A journey across a divided America", Type: "movie", Rating: &[]float64{7.1}[0]},
+		{Title: "Dune: Part Two", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{8.6}[0]},
+		{Title: "Old Classic", Year: &[]int{1999}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{5.2}[0]},
+		{Title: "The Boys", Category: "TV Series", ExtraInfo: "Episode 5 Added", Type: "series", Rating: &[]float64{8.7}[0]},
+	}
+}
+
+func saveContractItems(t *testing.T, store StorageInterface) []SaveResult {
+	results, err := store.SaveContents(contractItems())
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	return results
+}
+
+func contractSaveOutcomes(t *testing.T, store StorageInterface) {
+	results := saveContractItems(t, store)
+	for _, result := range results {
+		if result.Outcome != OutcomeInserted || result.Content.ID == 0 {
+			t.Fatalf("Expected %s to be inserted with an id, got %+v", result.Content.Title, result)
+		}
+	}
+
+	// The identity ignores case and surrounding spaces
+	updated := Content{Title: " the boys", Category: "TV Series", ExtraInfo: "Episode 6 Added", Type: "series", Rating: &[]float64{8.7}[0]}
+	unchanged := contractItems()[0]
+	results, err := store.SaveContents([]Content{updated, unchanged})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if results[0].Outcome != OutcomeUpdated || results[1].Outcome != OutcomeUnchanged {
+		t.Fatalf("Expected updated and unchanged, got %s and %s", results[0].Outcome, results[1].Outcome)
+	}
+
+	history, err := store.GetContentHistory("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 1 || history[0].String() != `extra_info: "Episode 5 Added" -> "Episode 6 Added"` {
+		t.Errorf("Unexpected history: %v", history)
+	}
+
+	stats, err := store.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats["total"] != 4 || stats["movies"] != 3 || stats["series"] != 1 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+}
+
+func contractHistory(t *testing.T, store StorageInterface) {
+	saveContractItems(t, store)
+
+	// A new source URL alone is bookkeeping, a new rating is an update
+	moved := contractItems()[1]
+	moved.SourceURL = &[]string{"https://example.com/movies"}[0]
+	rerated := contractItems()[2]
+	rerated.Rating = nil
+	results, err := store.SaveContents([]Content{moved, rerated})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if results[0].Outcome != OutcomeUnchanged || len(results[0].Changes) != 1 || results[1].Outcome != OutcomeUpdated {
+		t.Fatalf("Expected unchanged with a source change and updated, got %+v", results)
+	}
+
+	history, err := store.GetContentHistory("old classic", "MOVIE")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 1 || history[0].String() != `rating: "5.2" -> (none)` || history[0].ChangedAt.IsZero() {
+		t.Errorf("Unexpected history: %v", history)
+	}
+
+	content, err := store.GetContentByID(results[0].Content.ID)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	if content.SourceURL == nil || *content.SourceURL != "https://example.com/movies" {
+		t.Errorf("Expected the new source URL to be stored, got %+v", content)
+	}
+
+	if history, err := store.GetContentHistory("Unknown", "movie"); err != nil || len(history) != 0 {
+		t.Errorf("Expected no history for unknown content, got %v, %v", history, err)
+	}
+}
+
+func contractPreview(t *testing.T, store StorageInterface) {
+	saveContractItems(t, store)
+
+	changed := contractItems()[3]
+	changed.ExtraInfo = "Episode 6 Added"
+	items := []Content{changed, contractItems()[0], {Title: "New Movie", Category: "Hollywood", Type: "movie"}}
+	preview, err := store.PreviewSaveContents(items)
+	if err != nil {
+		t.Fatalf("Failed to preview content: %v", err)
+	}
+	if len(preview) != 3 || preview[0].Outcome != OutcomeUpdated || preview[1].Outcome != OutcomeUnchanged || preview[2].Outcome != OutcomeInserted {
+		t.Fatalf("Unexpected preview: %+v", preview)
+	}
+	if len(preview[0].Changes) != 1 || preview[0].Changes[0].Field != "extra_info" {
+		t.Errorf("Expected the extra info change in the preview, got %v", preview[0].Changes)
+	}
+
+	// Nothing was written
+	stats, err := store.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats["total"] != 4 {
+		t.Errorf("Expected the preview not to insert, got %v", stats)
+	}
+	history, err := store.GetContentHistory("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Expected the preview not to record history, got %v", history)
+	}
+
+	results, err := store.SaveContents(items)
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	for i := range results {
+		if results[i].Outcome != preview[i].Outcome {
+			t.Errorf("Expected %s to be %s as previewed, got %s", items[i].Title, preview[i].Outcome, results[i].Outcome)
+		}
+	}
+}
+
+func contractContentByID(t *testing.T, store StorageInterface) {
+	results := saveContractItems(t, store)
+	id := results[3].Content.ID
+
+	content, err := store.GetContentByID(id)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	if content == nil || content.Title != "The Boys" || content.CreatedAt == nil || content.ScrapedAt == nil {
+		t.Fatalf("Expected The Boys with timestamps, got %+v", content)
+	}
+
+	if _, err := store.SaveEpisodes("The Boys", "series", EpisodeRange{Season: 1, First: 1, Last: 2}); err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	if err := store.DeleteContent(id); err != nil {
+		t.Fatalf("Failed to delete content: %v", err)
+	}
+	if content, err := store.GetContentByID(id); err != nil || content != nil {
+		t.Errorf("Expected deleted content to be gone, got %+v, %v", content, err)
+	}
+	if err := store.DeleteContent(id); err == nil {
+		t.Error("Expected error deleting missing content")
+	}
+}
+
+func contractContentSources(t *testing.T, store StorageInterface) {
+	first, second := "https://nkiri.com/", "https://example.com/series"
+	boys := contractItems()[3]
+	boys.SourceURL = &first
+	movie := contractItems()[0]
+	movie.SourceURL = &first
+	results, err := store.SaveContents([]Content{boys, movie})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	id := results[0].Content.ID
+
+	// A second site lists the series with a later episode, then the first catches up
+	boys.SourceURL, boys.ExtraInfo = &second, "Episode 6 Added"
+	if _, err := store.SaveContents([]Content{boys}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	boys.SourceURL = &first
+	if _, err := store.SaveContents([]Content{boys}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	sources, err := store.GetContentSources(id)
+	if err != nil {
+		t.Fatalf("Failed to get content sources: %v", err)
+	}
+	if len(sources) != 2 || sources[0].URL != first || sources[1].URL != second {
+		t.Fatalf("Expected both sources, the first one first, got %+v", sources)
+	}
+	for _, source := range sources {
+		if source.ExtraInfo != "Episode 6 Added" || source.FirstSeenAt.IsZero() || source.LastSeenAt.Before(source.FirstSeenAt) {
+			t.Errorf("Unexpected source: %+v", source)
+		}
+	}
+
+	coverage, err := store.GetSourceCoverage()
+	if err != nil {
+		t.Fatalf("Failed to get source coverage: %v", err)
+	}
+	if len(coverage) != 2 || coverage[0].URL != first || coverage[0].Items != 2 || coverage[0].Exclusive != 1 ||
+		coverage[0].Shared != 1 || coverage[1].Items != 1 || coverage[1].Exclusive != 0 {
+		t.Errorf("Unexpected coverage: %+v", coverage)
+	}
+
+	// Previews and items without a source record nothing
+	preview := contractItems()[1]
+	preview.SourceURL = &second
+	if _, err := store.PreviewSaveContents([]Content{preview, contractItems()[2]}); err != nil {
+		t.Fatalf("Failed to preview content: %v", err)
+	}
+	if _, err := store.SaveContents([]Content{contractItems()[2]}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if coverage, _ := store.GetSourceCoverage(); len(coverage) != 2 || coverage[1].Items != 1 {
+		t.Errorf("Expected the preview to record no sources, got %+v", coverage)
+	}
+
+	if err := store.DeleteContent(id); err != nil {
+		t.Fatalf("Failed to delete content: %v", err)
+	}
+	if sources, err := store.GetContentSources(id); err != nil || len(sources) != 0 {
+		t.Errorf("Expected the sources of deleted content to be gone, got %+v, %v", sources, err)
+	}
+}
+
+func contractDuplicates(t *testing.T, store StorageInterface) {
+	first, second := "https://nkiri.com/", "https://example.com/movies"
+	dune := Content{Title: "Dune: Part Two", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie", SourceURL: &first}
+	results, err := store.SaveContents([]Content{dune})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	id := results[0].Content.ID
+
+	// Other spellings are saved onto the stored title
+	results, err = store.SaveContents([]Content{
+		{Title: "Dune Part 2", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{8.6}[0], SourceURL: &second},
+		{Title: "DUNE PART TWO", Category: "Hollywood", Type: "movie", Rating: &[]float64{8.6}[0], SourceURL: &second},
+		{Title: "Dune", Year: &[]int{2021}[0], Category: "Hollywood", Type: "movie"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if results[0].Content.ID != id || results[0].ScrapedTitle != "Dune Part 2" || results[0].Content.Title != "Dune: Part Two" || results[0].Outcome != OutcomeUpdated {
+		t.Errorf("Expected Dune Part 2 to update the stored title, got %+v", results[0])
+	}
+	// Without a year only the identity key matches. Saving onto the stored
+	// title inserts nothing, so no id is used up.
+	if results[1].Content.ID != id+1 || results[1].Outcome != OutcomeInserted || results[2].Outcome != OutcomeInserted {
+		t.Errorf("Expected the spelling without a year and Dune to be new, got %+v", results[1:])
+	}
+	stored, err := store.GetContentByID(id)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	if stored.Title != "Dune: Part Two" || stored.Year == nil || *stored.Year != 2024 || stored.Rating == nil {
+		t.Errorf("Expected the stored title with its year and the new rating, got %+v", stored)
+	}
+	if stats, _ := store.GetStats(); stats["total"] != 3 {
+		t.Errorf("Expected 3 items, got %v", stats)
+	}
+
+	// An article is not dropped to match a title without a year
+	results, err = store.SaveContents([]Content{
+		{Title: "Batman", Year: &[]int{1989}[0], Category: "Hollywood", Type: "movie"},
+		{Title: "The Batman", Category: "Hollywood", Type: "movie"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if results[0].Content.ID == results[1].Content.ID || results[1].Outcome != OutcomeInserted {
+		t.Errorf("Expected The Batman to be saved on its own, got %+v", results)
+	}
+
+	// Less similar series titles are only candidates for review
+	boys := Content{Title: "The Boys", Category: "TV Series", ExtraInfo: "Episode 5 Added", Type: "series", SourceURL: &first}
+	uncut := Content{Title: "The Boys Uncut", Category: "TV Series", ExtraInfo: "Episode 1 Added", Type: "series", SourceURL: &second}
+	results, err = store.SaveContents([]Content{boys, uncut})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	keepID, duplicateID := results[0].Content.ID, results[1].Content.ID
+	if keepID == duplicateID {
+		t.Fatalf("Expected separate rows for the series, got %+v", results)
+	}
+	uncut.ExtraInfo = "Episode 2 Added"
+	if _, err := store.SaveContents([]Content{uncut}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if _, err := store.SaveEpisodes("The Boys Uncut", "series", EpisodeRange{Season: 1, First: 1, Last: 2}); err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+
+	clusters, err := store.FindDuplicates(DuplicateOptions{Threshold: 0.6, Type: "series"})
+	if err != nil {
+		t.Fatalf("Failed to find duplicates: %v", err)
+	}
+	if len(clusters) != 1 || len(clusters[0].Items) != 2 || clusters[0].Items[0].ID != keepID || clusters[0].Items[1].ID != duplicateID {
+		t.Fatalf("Expected the two series in one cluster, got %+v", clusters)
+	}
+	if clusters, _ := store.FindDuplicates(DuplicateOptions{Type: "series"}); len(clusters) != 0 {
+		t.Errorf("Expected no clusters at the default threshold, got %+v", clusters)
+	}
+	// Spellings saving kept apart for lack of a year are listed for review
+	if clusters, _ := store.FindDuplicates(DuplicateOptions{Type: "movie"}); len(clusters) != 2 {
+		t.Errorf("Expected the year-less spellings as candidates, got %+v", clusters)
+	}
+
+	for _, ids := range [][]int64{{}, {keepID}, {duplicateID, duplicateID}, {id}, {999999}} {
+		if _, err := store.MergeContent(keepID, ids); err == nil {
+			t.Errorf("Expected error merging %v into %d", ids, keepID)
+		}
+	}
+
+	merged, err := store.MergeContent(keepID, []int64{duplicateID})
+	if err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if merged.Title != "The Boys" || merged.ExtraInfo != "Episode 5 Added" {
+		t.Errorf("Expected the kept series, got %+v", merged)
+	}
+	if gone, err := store.GetContentByID(duplicateID); err != nil || gone != nil {
+		t.Errorf("Expected the duplicate to be deleted, got %+v, %v", gone, err)
+	}
+
+	history, err := store.GetContentHistory("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 2 || history[0].Field != "extra_info" || history[1].String() != fmt.Sprintf(`merged: "The Boys Uncut (#%d)" -> "The Boys"`, duplicateID) {
+		t.Errorf("Expected the moved history and the merge, got %v", history)
+	}
+	episodes, err := store.GetEpisodes("The Boys", "series")
+	if err != nil || len(episodes) != 2 {
+		t.Errorf("Expected the duplicate's episodes to move, got %v, %v", episodes, err)
+	}
+	sources, err := store.GetContentSources(keepID)
+	if err != nil || len(sources) != 2 {
+		t.Errorf("Expected both sources on the kept series, got %+v, %v", sources, err)
+	}
+}
+
+func contractRetention(t *testing.T, store StorageInterface) {
+	results := saveContractItems(t, store)
+	if _, err := store.SaveEpisodes("The Boys", "series", EpisodeRange{Season: 1, First: 1, Last: 2}); err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+
+	content, err := store.GetContentByID(results[0].Content.ID)
+	if err != nil || content == nil || content.LastSeenAt == nil {
+		t.Fatalf("Expected a last seen time, got %+v, %v", content, err)
+	}
+
+	// Everything was seen just now
+	removed, err := store.PurgeContent(time.Now().Add(-time.Hour))
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("Expected nothing to expire, got %v, %v", removed, err)
+	}
+
+	removed, err = store.ArchiveContent(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to archive content: %v", err)
+	}
+	if len(removed) != 4 || removed[0].ID != results[0].Content.ID || removed[3].Title != "The Boys" {
+		t.Fatalf("Expected the 4 items in id order, got %+v", removed)
+	}
+	if stats, _ := store.GetStats(); stats["total"] != 0 {
+		t.Errorf("Expected archived content to leave the catalog, got %v", stats)
+	}
+
+	archived, err := store.GetArchivedContent(10)
+	if err != nil {
+		t.Fatalf("Failed to get archived content: %v", err)
+	}
+	if len(archived) != 4 || archived[0].ArchivedAt.IsZero() || archived[0].LastSeenAt == nil {
+		t.Fatalf("Expected 4 archived items with times, got %+v", archived)
+	}
+	boys := archived[0]
+	if boys.ID != results[3].Content.ID || boys.Title != "The Boys" || boys.ExtraInfo != "Episode 5 Added" {
+		t.Errorf("Expected the archive to keep the id and values, got %+v", boys)
+	}
+
+	// Scraped again, an archived item comes back as a new one without its episodes
+	again, err := store.SaveContents(contractItems()[3:])
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if again[0].Outcome != OutcomeInserted || again[0].Content.ID == boys.ID {
+		t.Errorf("Expected a new item, got %+v", again[0])
+	}
+	if episodes, err := store.GetEpisodes("The Boys", "series"); err != nil || len(episodes) != 0 {
+		t.Errorf("Expected the episodes to be gone, got %v, %v", episodes, err)
+	}
+
+	removed, err = store.PurgeContent(time.Now().Add(time.Hour))
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("Expected one purged item, got %v, %v", removed, err)
+	}
+	if archived, _ := store.GetArchivedContent(10); len(archived) != 4 {
+		t.Errorf("Expected purging to leave the archive alone, got %d items", len(archived))
+	}
+
+	if err := store.Optimize(); err != nil {
+		t.Errorf("Failed to optimize: %v", err)
+	}
+}
+
+func contractDetailedStats(t *testing.T, store StorageInterface) {
+	items := contractItems()
+	for _, i := range []int{0, 1} {
+		items[i].SourceURL = &[]string{"https://nkiri.com/"}[0]
+	}
+	if _, err := store.SaveContents(items); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if _, err := store.SaveEpisodes("The Boys", "series", EpisodeRange{Season: 4, First: 1, Last: 3}); err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+
+	runID, err := store.StartScrapeRun("manual")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, source := range []ScrapeRunSource{
+		{URL: "https://nkiri.com/", StartedAt: now, FinishedAt: now, Extracted: 10, Rejected: 2, Saved: 8},
+		{URL: "https://nkiri.com/", StartedAt: now, FinishedAt: now, Error: "timeout"},
+	} {
+		if err := store.RecordScrapeRunSource(runID, source); err != nil {
+			t.Fatalf("Failed to record source: %v", err)
+		}
+	}
+
+	stats, err := store.GetDetailedStats(StatsOptions{Days: 3, Weeks: 2, Limit: 2})
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats.Total != 4 || stats.Movies != 3 || stats.Series != 1 {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+
+	if len(stats.Categories) != 2 || stats.Categories[0].Category != "Hollywood" || stats.Categories[0].Count != 3 {
+		t.Fatalf("Unexpected categories: %+v", stats.Categories)
+	}
+	if average := stats.Categories[0].AverageRating; average == nil || *average < 6.96 || *average > 6.97 {
+		t.Errorf("Expected an average rating of 6.97, got %v", average)
+	}
+
+	if len(stats.Sources) != 2 || stats.Sources[0] != (SourceCount{SourceURL: "", Count: 2}) ||
+		stats.Sources[1] != (SourceCount{SourceURL: "https://nkiri.com/", Count: 2}) {
+		t.Errorf("Unexpected sources: %+v", stats.Sources)
+	}
+
+	daily, weekly := 0, 0
+	for _, period := range stats.Daily {
+		daily += period.Count
+	}
+	for _, period := range stats.Weekly {
+		weekly += period.Count
+	}
+	if len(stats.Daily) != 3 || len(stats.Weekly) != 2 || daily != 4 || weekly != 4 {
+		t.Errorf("Unexpected periods: %+v %+v", stats.Daily, stats.Weekly)
+	}
+	if stats.Weekly[1].Start.Weekday() != time.Monday {
+		t.Errorf("Expected weeks to start on Monday, got %v", stats.Weekly[1].Start)
+	}
+
+	if len(stats.TopNew) != 2 || stats.TopNew[0].Title != "The Boys" || stats.TopNew[1].Title != "Dune: Part Two" {
+		t.Errorf("Unexpected top new items: %+v", stats.TopNew)
+	}
+	if len(stats.MostUpdatedSeries) != 1 || stats.MostUpdatedSeries[0].Title != "The Boys" || stats.MostUpdatedSeries[0].NewEpisodes != 3 {
+		t.Errorf("Unexpected series updates: %+v", stats.MostUpdatedSeries)
+	}
+
+	if len(stats.Extraction) != 1 {
+		t.Fatalf("Expected one source in the extraction stats, got %+v", stats.Extraction)
+	}
+	e := stats.Extraction[0]
+	if e.Scrapes != 2 || e.Succeeded != 1 || e.SuccessRate != 0.5 || e.Saved != 8 || e.AcceptanceRate != 0.8 {
+		t.Errorf("Unexpected extraction stats: %+v", e)
+	}
+}
+
+func contractEpisodes(t *testing.T, store StorageInterface) {
+	saveContractItems(t, store)
+
+	added, err := store.SaveEpisodes("The Boys", "series", EpisodeRange{Season: 2, First: 15, Last: 18})
+	if err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	if len(added) != 4 {
+		t.Fatalf("Expected 4 new episodes, got %v", added)
+	}
+
+	// Known episodes are not new again; no season continues the latest one
+	added, err = store.SaveEpisodes("The Boys", "series", EpisodeRange{First: 18, Last: 19})
+	if err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	if len(added) != 1 || added[0].Season != 2 || added[0].Number != 19 {
+		t.Fatalf("Expected only S02E19 to be new, got %v", added)
+	}
+
+	latest, err := store.LatestEpisode("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get latest episode: %v", err)
+	}
+	if latest == nil || latest.Season != 2 || latest.Number != 19 {
+		t.Errorf("Expected S02E19 as latest episode, got %+v", latest)
+	}
+
+	episodes, err := store.GetEpisodes("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get episodes: %v", err)
+	}
+	if len(episodes) != 5 {
+		t.Errorf("Expected 5 episodes, got %d", len(episodes))
+	}
+}
+
+func contractQuery(t *testing.T, store StorageInterface) {
+	saveContractItems(t, store)
+
+	hourAgo := time.Now().Add(-time.Hour)
+	page, err := store.QueryContent(ContentFilter{
+		Type:         "movie",
+		Category:     "hollywood",
+		MinRating:    &[]float64{7}[0],
+		ScrapedAfter: &hourAgo,
+		SortBy:       SortRating,
+		Descending:   true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to query content: %v", err)
+	}
+	if titles := contentTitles(page.Items); page.Total != 2 || len(titles) != 2 || titles[0] != "Dune: Part Two" || titles[1] != "Civil War" {
+		t.Fatalf("Expected Dune: Part Two and Civil War, got total %d and %v", page.Total, titles)
+	}
+
+	// Walk all items one page at a time, sorted by title
+	var walked []string
+	filter := ContentFilter{SortBy: SortTitle, Limit: 3}
+	for {
+		page, err := store.QueryContent(filter)
+		if err != nil {
+			t.Fatalf("Failed to query page: %v", err)
+		}
+		walked = append(walked, contentTitles(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	if strings.Join(walked, ", ") != "Civil War, Dune: Part Two, Old Classic, The Boys" {
+		t.Errorf("Unexpected pagination order: %v", walked)
+	}
+}
+
+func contractQueryErrors(t *testing.T, store StorageInterface) {
+	saveContractItems(t, store)
+
+	if _, err := store.QueryContent(ContentFilter{SortBy: "popularity"}); err == nil {
+		t.Error("Expected error for an unknown sort field")
+	}
+	if _, err := store.QueryContent(ContentFilter{Cursor: "not a cursor"}); err == nil {
+		t.Error("Expected error for an invalid cursor")
+	}
+
+	page, err := store.QueryContent(ContentFilter{SortBy: SortTitle, Limit: 1})
+	if err != nil {
+		t.Fatalf("Failed to query content: %v", err)
+	}
+	if _, err := store.QueryContent(ContentFilter{SortBy: SortRating, Limit: 1, Cursor: page.NextCursor}); err == nil {
+		t.Error("Expected error for a cursor from another sort order")
+	}
+
+	// Ranges never match missing values
+	page, err = store.QueryContent(ContentFilter{MaxYear: &[]int{2100}[0]})
+	if err != nil {
+		t.Fatalf("Failed to query content: %v", err)
+	}
+	if page.Total != 3 {
+		t.Errorf("Expected the series without a year to be excluded, got %v", contentTitles(page.Items))
+	}
+}
+
+func contractSearch(t *testing.T, store StorageInterface) {
+	saveContractItems(t, store)
+
+	results, err := store.SearchText("journey america", 10)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Civil War" {
+		t.Errorf("Expected Civil War for a match in extra info, got %v", results)
+	}
+
+	contents, err := store.SearchContent("Boy")
+	if err != nil {
+		t.Fatalf("Failed to search titles: %v", err)
+	}
+	if len(contents) != 1 || contents[0].Title != "The Boys" {
+		t.Errorf("Expected The Boys for a title prefix, got %v", contents)
+	}
+}
+
+func contractEmbeddings(t *testing.T, store StorageInterface) {
+	results := saveContractItems(t, store)
+
+	if err := store.SaveEmbedding(results[0].Content, "test", []float32{1, 0}); err != nil {
+		t.Fatalf("Failed to save embedding: %v", err)
+	}
+	if err := store.SaveEmbedding(results[1].Content, "test", []float32{0, 1}); err != nil {
+		t.Fatalf("Failed to save embedding: %v", err)
+	}
+
+	missing, err := store.GetContentWithoutEmbedding("test", 10)
+	if err != nil {
+		t.Fatalf("Failed to get content without embeddings: %v", err)
+	}
+	if len(missing) != 2 {
+		t.Errorf("Expected 2 items without embeddings, got %v", contentTitles(missing))
+	}
+
+	scored, err := store.SemanticSearch("test", []float32{0.9, 0.1}, 10)
+	if err != nil {
+		t.Fatalf("Failed to search embeddings: %v", err)
+	}
+	if len(scored) != 2 || scored[0].Title != "Civil War" {
+		t.Errorf("Expected Civil War first, got %v", scored)
+	}
+}
+
+func contractSources(t *testing.T, store StorageInterface) {
+	// The source that used to be hardcoded is registered at startup
+	if err := RegisterDefaultSource(store); err != nil {
+		t.Fatalf("Failed to register the default source: %v", err)
+	}
+	sources, err := store.ListSources()
+	if err != nil {
+		t.Fatalf("Failed to list sources: %v", err)
+	}
+	if len(sources) != 1 || sources[0].URL != "https://nkiri.com/" || !sources[0].Enabled {
+		t.Fatalf("Expected the default source, got %+v", sources)
+	}
+	if _, err := store.AddSource(Source{URL: "https://nkiri.com/"}); err == nil {
+		t.Error("Expected error adding a URL twice")
+	}
+
+	id, err := store.AddSource(Source{URL: "https://example.com/feed.xml", Type: scraper.SourceTypeFeed, Enabled: true})
+	if err != nil {
+		t.Fatalf("Failed to add source: %v", err)
+	}
+
+	source, err := store.GetSourceByURL("https://example.com/feed.xml")
+	if err != nil {
+		t.Fatalf("Failed to get source: %v", err)
+	}
+	if source == nil || source.ID != id || source.Name != "example.com" || !source.Enabled {
+		t.Fatalf("Unexpected source: %+v", source)
+	}
+
+	source.Enabled = false
+	source.Schedule = "0 0 12 * * *"
+	if err := store.UpdateSource(*source); err != nil {
+		t.Fatalf("Failed to update source: %v", err)
+	}
+
+	if err := store.RecordSourceFailure(id, "timeout"); err != nil {
+		t.Fatalf("Failed to record failure: %v", err)
+	}
+	if err := store.RecordSourceFailure(id, "timeout"); err != nil {
+		t.Fatalf("Failed to record failure: %v", err)
+	}
+
+	source, err = store.GetSource(id)
+	if err != nil {
+		t.Fatalf("Failed to get source: %v", err)
+	}
+	if source.Enabled || source.Schedule != "0 0 12 * * *" || source.ConsecutiveFailures != 2 || source.LastFailureAt == nil {
+		t.Errorf("Unexpected source after update: %+v", source)
+	}
+
+	if err := store.RecordSourceSuccess(id); err != nil {
+		t.Fatalf("Failed to record success: %v", err)
+	}
+	if source, _ := store.GetSource(id); source.ConsecutiveFailures != 0 || source.LastSuccessAt == nil {
+		t.Errorf("Expected success to reset the failure streak, got %+v", source)
+	}
+
+	if err := store.DeleteSource(id); err != nil {
+		t.Fatalf("Failed to delete source: %v", err)
+	}
+	if source, err := store.GetSource(id); err != nil || source != nil {
+		t.Errorf("Expected deleted source to be gone, got %+v, %v", source, err)
+	}
+}
+
+func contractWatchlist(t *testing.T, store StorageInterface) {
+	id, err := store.AddWatchlistEntry(WatchlistEntry{Pattern: " The Boys ", Type: "series"})
+	if err != nil {
+		t.Fatalf("Failed to add watchlist entry: %v", err)
+	}
+	if _, err := store.AddWatchlistEntry(WatchlistEntry{Kind: WatchTitle, Pattern: "The Boys", Type: "series"}); err == nil {
+		t.Error("Expected error adding an entry twice")
+	}
+	if _, err := store.AddWatchlistEntry(WatchlistEntry{Kind: "regex", Pattern: "marvel"}); err == nil {
+		t.Error("Expected error for an unknown kind")
+	}
+	keywordID, err := store.AddWatchlistEntry(WatchlistEntry{Kind: WatchKeyword, Pattern: "marvel", Type: "movie"})
+	if err != nil {
+		t.Fatalf("Failed to add watchlist entry: %v", err)
+	}
+
+	if err := store.RecordWatchlistMatch(id); err != nil {
+		t.Fatalf("Failed to record match: %v", err)
+	}
+	entries, err := store.ListWatchlist()
+	if err != nil {
+		t.Fatalf("Failed to list watchlist: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != id || entries[0].Kind != WatchTitle || entries[0].Pattern != "The Boys" {
+		t.Fatalf("Unexpected watchlist: %+v", entries)
+	}
+	if entries[0].Matches != 1 || entries[0].LastMatchedAt == nil || entries[1].Matches != 0 || entries[1].LastMatchedAt != nil {
+		t.Errorf("Expected one match on the first entry, got %+v", entries)
+	}
+
+	if err := store.DeleteWatchlistEntry(keywordID); err != nil {
+		t.Fatalf("Failed to delete watchlist entry: %v", err)
+	}
+	if err := store.DeleteWatchlistEntry(keywordID); err == nil {
+		t.Error("Expected error deleting a missing entry")
+	}
+	if entries, _ := store.ListWatchlist(); len(entries) != 1 {
+		t.Errorf("Expected one entry left, got %+v", entries)
+	}
+}
+
+func contractScrapeRuns(t *testing.T, store StorageInterface) {
+	for i := 0; i < 3; i++ {
+		runID, err := store.StartScrapeRun("cron")
+		if err != nil {
+			t.Fatalf("Failed to start run: %v", err)
+		}
+		started := time.Now().Add(-time.Minute)
+		err = store.RecordScrapeRunSource(runID, ScrapeRunSource{
+			URL: "https://example.com", StartedAt: started, FinishedAt: time.Now(), HTTPStatus: 503, Error: "HTTP 503",
+		})
+		if err != nil {
+			t.Fatalf("Failed to record run source: %v", err)
+		}
+		if err := store.FinishScrapeRun(runID, ""); err != nil {
+			t.Fatalf("Failed to finish run: %v", err)
+		}
+	}
+
+	runs, err := store.RecentScrapeRuns(2)
+	if err != nil {
+		t.Fatalf("Failed to get runs: %v", err)
+	}
+	if len(runs) != 2 || runs[0].FinishedAt == nil || len(runs[0].Sources) != 1 || runs[0].Sources[0].HTTPStatus != 503 {
+		t.Fatalf("Unexpected runs: %+v", runs)
+	}
+
+	streaks, err := store.FailureStreaks(3)
+	if err != nil {
+		t.Fatalf("Failed to get failure streaks: %v", err)
+	}
+	if len(streaks) != 1 || streaks[0].Failures != 3 || streaks[0].LastError != "HTTP 503" || streaks[0].Since.IsZero() {
+		t.Errorf("Unexpected failure streaks: %+v", streaks)
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+// progressInterval is the number of rows between progress log lines
+const progressInterval = 1000
+
+// dataMigrationFunc is one direction of a data migration. It runs inside the
+// migration's transaction and reports every row it looks at to progress.
+type dataMigrationFunc func(ctx context.Context, tx *sql.Tx, progress *migrationProgress) error
+
+// addDataMigration registers a Go migration for backfills that plain SQL
+// can't do well. It runs in order with the embedded SQL files; goose takes
+// the version from source, e.g. "20251015000001_normalize_titles.go". Each
+// direction runs in one transaction, so a failure leaves no partial changes.
+func addDataMigration(source string, up, down dataMigrationFunc) {
+	goose.AddNamedMigrationContext(source, runDataMigration(source, "up", up), runDataMigration(source, "down", down))
+}
+
+func runDataMigration(source, direction string, fn dataMigrationFunc) goose.GoMigrationContext {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		progress := &migrationProgress{
+			name:    strings.TrimSuffix(source, ".go") + " " + direction,
+			started: time.Now(),
+		}
+		if err := fn(ctx, tx, progress); err != nil {
+			return fmt.Errorf("data migration %s failed after %d rows: %v", progress.name, progress.rows, err)
+		}
+		progress.finish()
+
+		if report, ok := ctx.Value(dryRunKey{}).(map[string]*migrationProgress); ok {
+			report[source] = progress
+		}
+		return nil
+	}
+}
+
+// dryRunKey holds the progress of each data migration during a dry run
+type dryRunKey struct{}
+
+// migrationProgress counts the rows a data migration has processed and logs
+// every progressInterval rows
+type migrationProgress struct {
+	name    string
+	total   int
+	rows    int
+	changed int
+	started time.Time
+}
+
+// expect sets the number of rows the migration is going to look at
+func (p *migrationProgress) expect(total int) {
+	p.total = total
+	log.Printf("Migration %s: %d rows to check", p.name, total)
+}
+
+// row records one processed row
+func (p *migrationProgress) row(changed bool) {
+	p.rows++
+	if changed {
+		p.changed++
+	}
+	if p.rows%progressInterval == 0 {
+		log.Printf("Migration %s: %d/%d rows, %d changed", p.name, p.rows, p.total, p.changed)
+	}
+}
+
+func (p *migrationProgress) finish() {
+	log.Printf("Migration %s: %d rows checked, %d changed in %s", p.name, p.rows, p.changed, time.Since(p.started).Round(time.Millisecond))
+}
@@ -0,0 +1,512 @@
+package storage
+
+import (
+	"cine-pulse/titles"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultDuplicateThreshold is the title similarity at which two items of the
+// same type and year are the same item. Saving uses it to match a new title
+// to a stored one.
+const DefaultDuplicateThreshold = 0.8
+
+// titleNumbers spells out numbers that titles write in words or roman numerals.
+// A lone "i" is left alone, as in "I, Robot".
+var titleNumbers = map[string]string{
+	"one": "1", "two": "2", "three": "3", "four": "4", "five": "5", "six": "6",
+	"seven": "7", "eight": "8", "nine": "9", "ten": "10", "eleven": "11", "twelve": "12",
+	"ii": "2", "iii": "3", "iv": "4", "v": "5", "vi": "6", "vii": "7", "viii": "8", "ix": "9", "x": "10",
+}
+
+// titleArticles are dropped from the start of a title
+var titleArticles = map[string]bool{"the": true, "a": true, "an": true}
+
+// titleMatchKey reduces a title to the words that identify it: lower-cased
+// without diacritics or punctuation, numbers as digits, no trailing "(2024)"
+// and no leading article. "Dune: Part Two" and "DUNE PART 2 (2024)" both
+// become "dune part 2".
+func titleMatchKey(title string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), title)
+	if err != nil {
+		folded = title
+	}
+	folded = strings.ToLower(strings.TrimSpace(folded))
+	folded = titles.StripYear(folded)
+	folded = strings.NewReplacer("&", " and ", "'", "", "’", "", "-", "").Replace(folded)
+
+	words := strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 1 && titleArticles[words[0]] {
+		words = words[1:]
+	}
+	for i, word := range words {
+		if number, ok := titleNumbers[word]; ok {
+			words[i] = number
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// titleSimilarity compares the words of two match keys: 1 when they have the
+// same words, 0 when they share none
+func titleSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	wordsA, wordsB := wordSet(a), wordSet(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(wordsA)+len(wordsB))
+}
+
+func wordSet(key string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(key) {
+		words[word] = true
+	}
+	return words
+}
+
+// identityOf normalizes a title and type like the identityKey expression
+func identityOf(title, contentType string) string {
+	return strings.ToLower(strings.Trim(title, " ")) + "|" + strings.ToLower(strings.Trim(contentType, " "))
+}
+
+// yearsAgree reports whether two release years can be the same item's
+func yearsAgree(a, b *int) bool {
+	return a == nil || b == nil || *a == *b
+}
+
+// sameRelease reports whether two items' release years let saving treat them
+// as the same item. When either year is missing only the identity key can
+// tell, as a match key without its article would merge "The Office" into
+// "Office".
+func sameRelease(a, b Content) bool {
+	if a.Year == nil || b.Year == nil {
+		return identityOf(a.Title, a.Type) == identityOf(b.Title, b.Type)
+	}
+	return *a.Year == *b.Year
+}
+
+// nearDuplicateScore reports how similar a new item is to a stored one and
+// whether saving should treat them as the same item. Titles with the same
+// match key need the same release; other similar titles need the same known
+// year, so remakes and titles without a year are never merged on a guess.
+func nearDuplicateScore(incoming, stored Content) (float64, bool) {
+	if incoming.Type != stored.Type {
+		return 0, false
+	}
+	a, b := titleMatchKey(incoming.Title), titleMatchKey(stored.Title)
+	if a == "" || b == "" || !sameRelease(incoming, stored) {
+		return 0, false
+	}
+	if a == b {
+		return 1, true
+	}
+	score := titleSimilarity(a, b)
+	return score, incoming.Year != nil && stored.Year != nil && score >= DefaultDuplicateThreshold
+}
+
+// bestNearDuplicate returns the candidate most similar to a new item, or nil
+// if none is close enough. A candidate with the item's own identity key means
+// the item is stored already and has no near-duplicate.
+func bestNearDuplicate(incoming Content, candidates []Content) *Content {
+	identity := identityOf(incoming.Title, incoming.Type)
+	var best *Content
+	bestScore := 0.0
+	for i := range candidates {
+		if identityOf(candidates[i].Title, candidates[i].Type) == identity {
+			return nil
+		}
+		score, ok := nearDuplicateScore(incoming, candidates[i])
+		if ok && (score > bestScore || (score == bestScore && candidates[i].ID < best.ID)) {
+			best, bestScore = &candidates[i], score
+		}
+	}
+	return best
+}
+
+// firstWord returns the first word of a match key. Near-duplicates are only
+// looked for among titles that start with the same word.
+func firstWord(key string) string {
+	first, _, _ := strings.Cut(key, " ")
+	return first
+}
+
+// DuplicateOptions control FindDuplicates
+type DuplicateOptions struct {
+	// Threshold is the lowest title similarity, DefaultDuplicateThreshold when 0
+	Threshold float64
+	// Type only looks at movies or series
+	Type string
+}
+
+// DuplicateCluster is a group of items that look like the same title
+type DuplicateCluster struct {
+	// Items are ordered oldest first; merging keeps the first by default
+	Items []Content `json:"items"`
+	// Similarity is the lowest similarity of the pairs linking the items
+	Similarity float64 `json:"similarity"`
+}
+
+// clusterDuplicates groups items of the same type with agreeing years whose
+// titles are at least as similar as the threshold. Only titles starting with
+// the same word are compared.
+func clusterDuplicates(contents []Content, options DuplicateOptions) []DuplicateCluster {
+	threshold := options.Threshold
+	if threshold <= 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+
+	keys := make([]string, len(contents))
+	blocks := make(map[string][]int)
+	for i, content := range contents {
+		if options.Type != "" && content.Type != options.Type {
+			continue
+		}
+		keys[i] = titleMatchKey(content.Title)
+		if keys[i] == "" {
+			continue
+		}
+		block := content.Type + "|" + firstWord(keys[i])
+		blocks[block] = append(blocks[block], i)
+	}
+
+	// Link similar pairs, remembering the weakest link of each group
+	parent := make([]int, len(contents))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	weakest := make(map[int]float64)
+	for _, members := range blocks {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				i, j := members[x], members[y]
+				if !yearsAgree(contents[i].Year, contents[j].Year) {
+					continue
+				}
+				score := titleSimilarity(keys[i], keys[j])
+				if score < threshold {
+					continue
+				}
+				ri, rj := find(i), find(j)
+				if ri == rj {
+					continue
+				}
+				low := score
+				for _, root := range []int{ri, rj} {
+					if w, ok := weakest[root]; ok && w < low {
+						low = w
+					}
+				}
+				delete(weakest, ri)
+				delete(weakest, rj)
+				parent[ri] = rj
+				weakest[rj] = low
+			}
+		}
+	}
+
+	groups := make(map[int][]Content)
+	for i := range contents {
+		if keys[i] == "" {
+			continue
+		}
+		if root := find(i); weakest[root] > 0 {
+			groups[root] = append(groups[root], contents[i])
+		}
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(groups))
+	for root, items := range groups {
+		sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+		clusters = append(clusters, DuplicateCluster{Items: items, Similarity: weakest[root]})
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Similarity != clusters[j].Similarity {
+			return clusters[i].Similarity > clusters[j].Similarity
+		}
+		return clusters[i].Items[0].ID < clusters[j].Items[0].ID
+	})
+	return clusters
+}
+
+// mergedFields fills the fields the kept item lacks from its duplicates,
+// newest duplicate first, and returns the resulting changes
+func mergedFields(keep Content, duplicates []Content) (Content, []ContentChange) {
+	merged := keep
+	sorted := append([]Content(nil), duplicates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID > sorted[j].ID })
+	for _, duplicate := range sorted {
+		if merged.Year == nil && duplicate.Year != nil {
+			merged.Year = duplicate.Year
+		}
+		if merged.Rating == nil && duplicate.Rating != nil {
+			merged.Rating = duplicate.Rating
+		}
+		if merged.ExtraInfo == "" {
+			merged.ExtraInfo = duplicate.ExtraInfo
+		}
+		// The item was first scraped when its earliest spelling was
+		if duplicate.ScrapedAt != nil && (merged.ScrapedAt == nil || duplicate.ScrapedAt.Before(*merged.ScrapedAt)) {
+			merged.ScrapedAt = duplicate.ScrapedAt
+		}
+		if duplicate.LastSeenAt != nil && (merged.LastSeenAt == nil || duplicate.LastSeenAt.After(*merged.LastSeenAt)) {
+			merged.LastSeenAt = duplicate.LastSeenAt
+		}
+	}
+	return merged, diffContent(keep, merged)
+}
+
+// mergeChange records in the kept item's history that a duplicate was merged into it
+func mergeChange(keep, duplicate Content) ContentChange {
+	old := fmt.Sprintf("%s (#%d)", duplicate.Title, duplicate.ID)
+	return ContentChange{Field: "merged", OldValue: &old, NewValue: &keep.Title}
+}
+
+// checkMerge validates the ids passed to MergeContent
+func checkMerge(keepID int64, duplicateIDs []int64) error {
+	if len(duplicateIDs) == 0 {
+		return fmt.Errorf("no duplicates to merge")
+	}
+	seen := map[int64]bool{keepID: true}
+	for _, id := range duplicateIDs {
+		if seen[id] {
+			return fmt.Errorf("content %d is listed twice", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// backfillTitleKeys computes the match key of rows stored without one, such
+// as rows saved before the column existed
+func (s *sqlStore) backfillTitleKeys() error {
+	rows, err := s.db.Query(`SELECT id, title FROM content WHERE title_key IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to query titles without a key: %v", err)
+	}
+	keys := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var title string
+		if err := rows.Scan(&id, &title); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan title: %v", err)
+		}
+		keys[id] = titleMatchKey(title)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query titles without a key: %v", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	log.Printf("Computing title keys of %d items", len(keys))
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for id, key := range keys {
+		if _, err := tx.Exec(`UPDATE content SET title_key = ? WHERE id = ?`, key, id); err != nil {
+			return fmt.Errorf("failed to set title key of %d: %v", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit title keys: %v", err)
+	}
+	return nil
+}
+
+// findNearDuplicate returns the stored item a new item is a different
+// spelling of, or nil
+func findNearDuplicate(tx *sqlTx, content Content) (*Content, error) {
+	key := titleMatchKey(content.Title)
+	if key == "" {
+		return nil, nil
+	}
+
+	// Keys only contain letters, digits and spaces, so the pattern needs no escaping
+	first := firstWord(key)
+	rows, err := tx.Query(`SELECT `+contentColumns+` FROM content c
+		WHERE c.type = ? AND (c.title_key = ? OR c.title_key LIKE ?)`,
+		content.Type, first, first+" %")
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for near-duplicates: %v", err)
+	}
+	defer rows.Close()
+
+	candidates, err := scanContents(rows)
+	if err != nil {
+		return nil, err
+	}
+	return bestNearDuplicate(content, candidates), nil
+}
+
+// FindDuplicates groups stored items that look like the same title, the
+// most similar groups first
+func (s *sqlStore) FindDuplicates(options DuplicateOptions) ([]DuplicateCluster, error) {
+	query := `SELECT ` + contentColumns + ` FROM content c`
+	var args []any
+	if options.Type != "" {
+		query += ` WHERE c.type = ?`
+		args = append(args, options.Type)
+	}
+
+	rows, err := s.db.Query(query+` ORDER BY c.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content: %v", err)
+	}
+	defer rows.Close()
+
+	contents, err := scanContents(rows)
+	if err != nil {
+		return nil, err
+	}
+	return clusterDuplicates(contents, options), nil
+}
+
+// MergeContent folds duplicates into the item to keep: their history,
+// episodes and sources move over, fields the kept item lacks are filled
+// from them, and the merge is recorded in the kept item's history. The
+// duplicates are then deleted. It returns the kept item.
+func (s *sqlStore) MergeContent(keepID int64, duplicateIDs []int64) (*Content, error) {
+	if err := checkMerge(keepID, duplicateIDs); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	load := func(id int64) (Content, error) {
+		content, err := scanContent(tx.QueryRow(`SELECT `+contentColumns+` FROM content c WHERE c.id = ?`, id))
+		if err == sql.ErrNoRows {
+			return content, fmt.Errorf("content %d not found", id)
+		}
+		if err != nil {
+			return content, fmt.Errorf("failed to get content %d: %v", id, err)
+		}
+		return content, nil
+	}
+
+	keep, err := load(keepID)
+	if err != nil {
+		return nil, err
+	}
+	var duplicates []Content
+	for _, id := range duplicateIDs {
+		duplicate, err := load(id)
+		if err != nil {
+			return nil, err
+		}
+		if duplicate.Type != keep.Type {
+			return nil, fmt.Errorf("cannot merge %s %d into %s %d", duplicate.Type, id, keep.Type, keepID)
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	for _, duplicate := range duplicates {
+		if err := moveDependents(tx, duplicate.ID, keepID); err != nil {
+			return nil, err
+		}
+		if err := deleteDependents(tx, duplicate.ID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(`DELETE FROM content WHERE id = ?`, duplicate.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete content %d: %v", duplicate.ID, err)
+		}
+	}
+
+	merged, changes := mergedFields(keep, duplicates)
+	_, err = tx.Exec(`UPDATE content SET year = ?, rating = ?, extra_info = ?, scraped_at = ?, last_seen_at = ?
+		WHERE id = ?`, merged.Year, merged.Rating, merged.ExtraInfo,
+		tx.dialect.nullTimeArg(merged.ScrapedAt), tx.dialect.nullTimeArg(merged.LastSeenAt), keepID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update content %d: %v", keepID, err)
+	}
+	if len(changes) > 0 {
+		if _, err := tx.Exec(`UPDATE content SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`, keepID); err != nil {
+			return nil, fmt.Errorf("failed to update content %d: %v", keepID, err)
+		}
+	}
+	for _, duplicate := range duplicates {
+		changes = append(changes, mergeChange(keep, duplicate))
+	}
+	if err := recordChanges(tx, keepID, changes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge: %v", err)
+	}
+	return s.GetContentByID(keepID)
+}
+
+// moveDependents moves a duplicate's history, episodes and sources to the
+// item it is merged into. Episodes and sources both items have are kept
+// once, with the earliest first sighting.
+func moveDependents(tx *sqlTx, fromID, toID int64) error {
+	if _, err := tx.Exec(`UPDATE content_history SET content_id = ? WHERE content_id = ?`, toID, fromID); err != nil {
+		return fmt.Errorf("failed to move history: %v", err)
+	}
+
+	_, err := tx.Exec(`
+	INSERT INTO episodes (content_id, season, episode, first_seen_at)
+	SELECT ?, season, episode, first_seen_at FROM episodes WHERE content_id = ?
+	ON CONFLICT(content_id, season, episode) DO UPDATE SET
+		first_seen_at = CASE WHEN excluded.first_seen_at < episodes.first_seen_at
+			THEN excluded.first_seen_at ELSE episodes.first_seen_at END
+	`, toID, fromID)
+	if err != nil {
+		return fmt.Errorf("failed to move episodes: %v", err)
+	}
+
+	_, err = tx.Exec(`
+	INSERT INTO content_sources (content_id, source_url, extra_info, first_seen_at, last_seen_at, updated_at)
+	SELECT ?, source_url, extra_info, first_seen_at, last_seen_at, updated_at FROM content_sources WHERE content_id = ?
+	ON CONFLICT(content_id, source_url) DO UPDATE SET
+		first_seen_at = CASE WHEN excluded.first_seen_at < content_sources.first_seen_at
+			THEN excluded.first_seen_at ELSE content_sources.first_seen_at END,
+		last_seen_at = CASE WHEN excluded.last_seen_at > content_sources.last_seen_at
+			THEN excluded.last_seen_at ELSE content_sources.last_seen_at END,
+		extra_info = CASE WHEN excluded.last_seen_at > content_sources.last_seen_at
+			THEN excluded.extra_info ELSE content_sources.extra_info END,
+		updated_at = CASE WHEN excluded.last_seen_at > content_sources.last_seen_at
+			THEN excluded.updated_at ELSE content_sources.updated_at END
+	`, toID, fromID)
+	if err != nil {
+		return fmt.Errorf("failed to move sources: %v", err)
+	}
+	return nil
+}
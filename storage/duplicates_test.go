@@ -0,0 +1,149 @@
+package storage
+
+import "testing"
+
+func TestTitleMatchKey(t *testing.T) {
+	for title, expected := range map[string]string{
+		"Dune: Part Two":          "dune part 2",
+		"Dune Part 2 (2024)":      "dune part 2",
+		"DUNE PART TWO":           "dune part 2",
+		"The Boys":                "boys",
+		"Spider-Man: No Way Home": "spiderman no way home",
+		"Pokémon Detective":       "pokemon detective",
+		"Rocky IV":                "rocky 4",
+		"I, Robot":                "i robot",
+		"Fast & Furious":          "fast and furious",
+		"The":                     "the",
+		"!!!":                     "",
+	} {
+		if key := titleMatchKey(title); key != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, title, key)
+		}
+	}
+}
+
+func TestNearDuplicateScore(t *testing.T) {
+	year := func(y int) *int { return &y }
+	stored := Content{Title: "Dune: Part Two", Year: year(2024), Type: "movie"}
+
+	for _, tc := range []struct {
+		incoming Content
+		match    bool
+	}{
+		{Content{Title: "Dune Part 2", Year: year(2024), Type: "movie"}, true},
+		{Content{Title: "DUNE PART TWO (2024)", Year: year(2024), Type: "movie"}, true},
+		// Without a year only the identity key matches
+		{Content{Title: " dune: part two", Type: "movie"}, true},
+		{Content{Title: "DUNE PART TWO", Type: "movie"}, false},
+		{Content{Title: "Dune Part Two IMAX", Year: year(2024), Type: "movie"}, true},
+		// Similar titles need the same known year
+		{Content{Title: "Dune Part Two IMAX", Type: "movie"}, false},
+		{Content{Title: "Dune Part Two", Year: year(2021), Type: "movie"}, false},
+		{Content{Title: "Dune Part Two", Year: year(2024), Type: "series"}, false},
+		{Content{Title: "Dune", Year: year(2024), Type: "movie"}, false},
+	} {
+		if _, match := nearDuplicateScore(tc.incoming, stored); match != tc.match {
+			t.Errorf("Expected match %t for %+v", tc.match, tc.incoming)
+		}
+	}
+
+	// Dropping the article is not enough when a year is missing
+	for _, pair := range [][2]Content{
+		{{Title: "The Batman", Type: "movie"}, {Title: "Batman", Year: year(1989), Type: "movie"}},
+		{{Title: "The Office", Type: "series"}, {Title: "Office", Type: "series"}},
+	} {
+		if _, match := nearDuplicateScore(pair[0], pair[1]); match {
+			t.Errorf("Expected %q not to match %q", pair[0].Title, pair[1].Title)
+		}
+	}
+}
+
+func TestClusterDuplicates(t *testing.T) {
+	year := func(y int) *int { return &y }
+	contents := []Content{
+		{ID: 1, Title: "Kung Fu Panda 4", Year: year(2024), Type: "movie"},
+		{ID: 2, Title: "Kung Fu Panda 4 Extended Director's Cut", Year: year(2024), Type: "movie"},
+		{ID: 3, Title: "Kung Fu Panda", Year: year(2008), Type: "movie"},
+		{ID: 4, Title: "Kung Fu Panda 4: The Series", Type: "series"},
+		{ID: 5, Title: "Civil War", Year: year(2024), Type: "movie"},
+	}
+
+	clusters := clusterDuplicates(contents, DuplicateOptions{Threshold: 0.7})
+	if len(clusters) != 1 || len(clusters[0].Items) != 2 || clusters[0].Items[0].ID != 1 || clusters[0].Items[1].ID != 2 {
+		t.Fatalf("Expected items 1 and 2 in one cluster, got %+v", clusters)
+	}
+	if clusters[0].Similarity < 0.7 || clusters[0].Similarity >= DefaultDuplicateThreshold {
+		t.Errorf("Unexpected similarity %v", clusters[0].Similarity)
+	}
+
+	if clusters := clusterDuplicates(contents, DuplicateOptions{}); len(clusters) != 0 {
+		t.Errorf("Expected no clusters at the default threshold, got %+v", clusters)
+	}
+	if clusters := clusterDuplicates(contents, DuplicateOptions{Threshold: 0.7, Type: "series"}); len(clusters) != 0 {
+		t.Errorf("Expected no series clusters, got %+v", clusters)
+	}
+}
+
+func TestBackfillTitleKeys(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSQLiteStorage(dir)
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	if _, err := store.SaveContents([]Content{{Title: "Dune: Part Two", Category: "Hollywood", Type: "movie"}}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	// As if saved before the column existed
+	if _, err := store.db.Exec(`UPDATE content SET title_key = NULL`); err != nil {
+		t.Fatalf("Failed to clear title keys: %v", err)
+	}
+	store.Close()
+
+	store = NewSQLiteStorage(dir)
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	defer store.Close()
+
+	var key string
+	if err := store.db.QueryRow(`SELECT title_key FROM content`).Scan(&key); err != nil {
+		t.Fatalf("Failed to get title key: %v", err)
+	}
+	if key != "dune part 2" {
+		t.Errorf("Expected the title key to be filled in, got %q", key)
+	}
+}
+
+func TestNearDuplicateKeepsEpisodes(t *testing.T) {
+	store := NewSQLiteStorage(t.TempDir())
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	year := 2024
+	if _, err := store.SaveContents([]Content{{Title: "Dune: Part Two", Year: &year, Category: "TV Series", Type: "series"}}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	results, err := store.SaveContents([]Content{{Title: "DUNE PART TWO", Year: &year, Category: "TV Series", ExtraInfo: "Episode 2 Added", Type: "series"}})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	saved := results[0]
+	if saved.Content.Title != "Dune: Part Two" || saved.ScrapedTitle != "DUNE PART TWO" {
+		t.Fatalf("Expected the result to carry the stored title, got %+v", saved)
+	}
+
+	// Episodes are found by the title in the result
+	added, err := store.SaveEpisodes(saved.Content.Title, saved.Content.Type, EpisodeRange{Season: 1, First: 1, Last: 2})
+	if err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("Expected 2 episodes, got %+v", added)
+	}
+	latest, err := store.LatestEpisode("Dune: Part Two", "series")
+	if err != nil || latest == nil || latest.Number != 2 {
+		t.Errorf("Expected episode 2 as latest, got %+v, %v", latest, err)
+	}
+}
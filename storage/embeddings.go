@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// SaveEmbedding stores the embedding vector for an existing content row
+func (s *sqlStore) SaveEmbedding(content Content, model string, vector []float32) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("cannot save empty embedding for %s", content.Title)
+	}
+
+	// Items that were not loaded from the database are found by identity
+	contentID := content.ID
+	if contentID == 0 {
+		err := s.db.QueryRow(`SELECT id FROM content WHERE identity_key = `+identityKey,
+			content.Title, content.Type).Scan(&contentID)
+		if err != nil {
+			return fmt.Errorf("failed to find content %s: %v", content.Title, err)
+		}
+	}
+
+	query := `
+	INSERT INTO content_embeddings (content_id, model, dimensions, vector, updated_at)
+	VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	ON CONFLICT(content_id) DO UPDATE SET
+		model = excluded.model,
+		dimensions = excluded.dimensions,
+		vector = excluded.vector,
+		updated_at = CURRENT_TIMESTAMP
+	`
+
+	if _, err := s.db.Exec(query, contentID, model, len(vector), encodeVector(vector)); err != nil {
+		return fmt.Errorf("failed to save embedding: %v", err)
+	}
+
+	return nil
+}
+
+// GetContentWithoutEmbedding returns content that has no embedding from the given model yet
+func (s *sqlStore) GetContentWithoutEmbedding(model string, limit int) ([]Content, error) {
+	query := `
+	SELECT ` + contentColumns + `
+	FROM content c
+	LEFT JOIN content_embeddings e ON e.content_id = c.id AND e.model = ?
+	WHERE e.content_id IS NULL
+	ORDER BY c.created_at DESC, c.id DESC
+	LIMIT ?
+	`
+
+	rows, err := s.db.Query(query, model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content without embeddings: %v", err)
+	}
+	defer rows.Close()
+
+	return scanContents(rows)
+}
+
+// SemanticSearch ranks stored content by cosine similarity to a query vector
+// produced by the same embedding model
+func (s *sqlStore) SemanticSearch(model string, query []float32, limit int) ([]ScoredContent, error) {
+	rows, err := s.db.Query(`
+	SELECT `+contentColumns+`, e.vector
+	FROM content_embeddings e
+	JOIN content c ON c.id = e.content_id
+	WHERE e.model = ? AND e.dimensions = ?
+	`, model, len(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query embeddings: %v", err)
+	}
+	defer rows.Close()
+
+	var results []ScoredContent
+	for rows.Next() {
+		var result ScoredContent
+		var blob []byte
+		result.Content, err = scanContent(rows, &blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan embedding: %v", err)
+		}
+
+		vector, err := decodeVector(blob)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Score = cosineSimilarity(query, vector)
+		results = append(results, result)
+	}
+
+	return topScored(results, limit), nil
+}
+
+// topScored sorts results by descending score and keeps at most limit entries
+func topScored(results []ScoredContent, limit int) []ScoredContent {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// encodeVector packs a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// decodeVector unpacks a vector stored by encodeVector
+func decodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid embedding length %d", len(buf))
+	}
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vector, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors of equal length
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
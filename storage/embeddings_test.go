@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSemanticSearch(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage := NewSQLiteStorage(tempDir)
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	dark := Content{Title: "Dark", Category: "Foreign", ExtraInfo: "Season 3", Type: "series"}
+	comedy := Content{Title: "Ted Lasso", Category: "TV Series", ExtraInfo: "Complete", Type: "series"}
+
+	if _, err := storage.SaveContents([]Content{dark, comedy}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	// Nothing is embedded yet
+	pending, err := storage.GetContentWithoutEmbedding("test:model", 10)
+	if err != nil {
+		t.Fatalf("Failed to get content without embedding: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("Expected 2 items without embeddings, got %d", len(pending))
+	}
+
+	if err := storage.SaveEmbedding(dark, "test:model", []float32{0.9, 0.1, 0}); err != nil {
+		t.Fatalf("Failed to save embedding: %v", err)
+	}
+	if err := storage.SaveEmbedding(comedy, "test:model", []float32{0, 0.2, 0.9}); err != nil {
+		t.Fatalf("Failed to save embedding: %v", err)
+	}
+
+	// Saving again replaces the previous vector
+	if err := storage.SaveEmbedding(dark, "test:model", []float32{1, 0, 0}); err != nil {
+		t.Fatalf("Failed to update embedding: %v", err)
+	}
+
+	pending, err = storage.GetContentWithoutEmbedding("test:model", 10)
+	if err != nil {
+		t.Fatalf("Failed to get content without embedding: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected no items without embeddings, got %d", len(pending))
+	}
+
+	results, err := storage.SemanticSearch("test:model", []float32{0.8, 0.1, 0.1}, 5)
+	if err != nil {
+		t.Fatalf("Failed to run semantic search: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Title != "Dark" {
+		t.Errorf("Expected Dark to rank first, got %s", results[0].Title)
+	}
+
+	if results[0].Score <= results[1].Score {
+		t.Errorf("Expected descending scores, got %.3f then %.3f", results[0].Score, results[1].Score)
+	}
+
+	// Vectors from another model are never compared
+	results, err = storage.SemanticSearch("other:model", []float32{0.8, 0.1, 0.1}, 5)
+	if err != nil {
+		t.Fatalf("Failed to run semantic search: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results for other model, got %d", len(results))
+	}
+
+	// Embedding unknown content is an error
+	if err := storage.SaveEmbedding(Content{Title: "Missing", Type: "movie"}, "test:model", []float32{1}); err == nil {
+		t.Error("Expected error when embedding unknown content")
+	}
+}
+
+func TestVectorEncoding(t *testing.T) {
+	vector := []float32{0.5, -1.25, 3, float32(math.Pi)}
+
+	decoded, err := decodeVector(encodeVector(vector))
+	if err != nil {
+		t.Fatalf("Failed to decode vector: %v", err)
+	}
+
+	for i := range vector {
+		if decoded[i] != vector[i] {
+			t.Errorf("Value %d: expected %v, got %v", i, vector[i], decoded[i])
+		}
+	}
+
+	if _, err := decodeVector([]byte{1, 2, 3}); err == nil {
+		t.Error("Expected error for truncated vector")
+	}
+
+	if sim := cosineSimilarity([]float32{1, 0}, []float32{1, 0}); math.Abs(sim-1) > 1e-9 {
+		t.Errorf("Expected similarity 1 for identical vectors, got %f", sim)
+	}
+}
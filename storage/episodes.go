@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// maxEpisodeRange guards against misparsed ranges such as "Episode 1-2024"
+const maxEpisodeRange = 500
+
+// EpisodeRange is a run of episodes within one season.
+// Season is 0 when the source did not state it; First and Last are 0 when
+// only the season is known.
+type EpisodeRange struct {
+	Season int `json:"season"`
+	First  int `json:"first"`
+	Last   int `json:"last"`
+}
+
+// String formats the range as "S02E15–E18", "S02E15" or "Season 2"
+func (r EpisodeRange) String() string {
+	season := r.Season
+	if season == 0 {
+		season = 1
+	}
+	switch {
+	case r.First == 0:
+		return fmt.Sprintf("Season %d", season)
+	case r.Last > r.First:
+		return fmt.Sprintf("S%02dE%02d–E%02d", season, r.First, r.Last)
+	default:
+		return fmt.Sprintf("S%02dE%02d", season, r.First)
+	}
+}
+
+// Episode is a single stored episode of a series
+type Episode struct {
+	Season      int       `json:"season"`
+	Number      int       `json:"episode"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+}
+
+// SaveEpisodes records every episode in the range for a stored series and
+// returns the episodes that were not known before. When the range has no
+// season, the latest stored season is assumed, or season 1 for a new series.
+// Ranges without episodes are ignored.
+func (s *sqlStore) SaveEpisodes(title, contentType string, r EpisodeRange) ([]Episode, error) {
+	if r.First == 0 {
+		return nil, nil
+	}
+	if r.Last < r.First {
+		r.Last = r.First
+	}
+	if r.Last-r.First >= maxEpisodeRange {
+		return nil, fmt.Errorf("episode range %s is too large", r)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var contentID int64
+	err = tx.QueryRow(`SELECT id FROM content WHERE identity_key = `+identityKey, title, contentType).Scan(&contentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find content %s: %v", title, err)
+	}
+
+	season := r.Season
+	if season == 0 {
+		var latest sql.NullInt64
+		if err := tx.QueryRow(`SELECT MAX(season) FROM episodes WHERE content_id = ?`, contentID).Scan(&latest); err != nil {
+			return nil, fmt.Errorf("failed to get latest season: %v", err)
+		}
+		season = 1
+		if latest.Valid {
+			season = int(latest.Int64)
+		}
+	}
+
+	var added []Episode
+	now := time.Now().UTC()
+	for number := r.First; number <= r.Last; number++ {
+		res, err := tx.Exec(`INSERT INTO episodes (content_id, season, episode, first_seen_at)
+			VALUES (?, ?, ?, ?) ON CONFLICT(content_id, season, episode) DO NOTHING`,
+			contentID, season, number, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save episode %d: %v", number, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			added = append(added, Episode{Season: season, Number: number, FirstSeenAt: now})
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit episodes: %v", err)
+	}
+
+	return added, nil
+}
+
+// GetEpisodes returns all known episodes of a series in order
+func (s *sqlStore) GetEpisodes(title, contentType string) ([]Episode, error) {
+	query := `
+	SELECT e.season, e.episode, e.first_seen_at
+	FROM episodes e
+	JOIN content c ON c.id = e.content_id
+	WHERE c.identity_key = ` + identityKey + `
+	ORDER BY e.season, e.episode
+	`
+
+	rows, err := s.db.Query(query, title, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episodes: %v", err)
+	}
+	defer rows.Close()
+
+	var episodes []Episode
+	for rows.Next() {
+		var episode Episode
+		if err := rows.Scan(&episode.Season, &episode.Number, &episode.FirstSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan episode: %v", err)
+		}
+		episodes = append(episodes, episode)
+	}
+
+	return episodes, rows.Err()
+}
+
+// LatestEpisode returns the highest known episode of a series, or nil if none is stored
+func (s *sqlStore) LatestEpisode(title, contentType string) (*Episode, error) {
+	query := `
+	SELECT e.season, e.episode, e.first_seen_at
+	FROM episodes e
+	JOIN content c ON c.id = e.content_id
+	WHERE c.identity_key = ` + identityKey + `
+	ORDER BY e.season DESC, e.episode DESC
+	LIMIT 1
+	`
+
+	var episode Episode
+	err := s.db.QueryRow(query, title, contentType).Scan(&episode.Season, &episode.Number, &episode.FirstSeenAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest episode: %v", err)
+	}
+
+	return &episode, nil
+}
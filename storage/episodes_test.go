@@ -0,0 +1,74 @@
+package storage
+
+import "testing"
+
+func TestEpisodes(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	series := Content{Title: "The Boys", Category: "TV Series", ExtraInfo: "Episode 1-3", Type: "series"}
+	if _, err := storage.SaveContents([]Content{series}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	latest, err := storage.LatestEpisode("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get latest episode: %v", err)
+	}
+	if latest != nil {
+		t.Fatalf("Expected no episodes yet, got %+v", latest)
+	}
+
+	// Without a season the first season is assumed
+	added, err := storage.SaveEpisodes("The Boys", "series", EpisodeRange{First: 1, Last: 3})
+	if err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	if len(added) != 3 || added[0].Season != 1 {
+		t.Fatalf("Expected 3 episodes of season 1, got %+v", added)
+	}
+
+	// Overlapping ranges only add the unseen episodes
+	added, err = storage.SaveEpisodes("The Boys", "series", EpisodeRange{Season: 1, First: 3, Last: 5})
+	if err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	if len(added) != 2 || added[0].Number != 4 || added[1].Number != 5 {
+		t.Fatalf("Expected episodes 4 and 5 to be added, got %+v", added)
+	}
+
+	// A later season without a season number in the next range stays on the latest season
+	if _, err := storage.SaveEpisodes("The Boys", "series", EpisodeRange{Season: 2, First: 1, Last: 1}); err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	added, err = storage.SaveEpisodes("The Boys", "series", EpisodeRange{First: 2, Last: 2})
+	if err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	if len(added) != 1 || added[0].Season != 2 {
+		t.Fatalf("Expected S02E02 to be added, got %+v", added)
+	}
+
+	latest, err = storage.LatestEpisode("the boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get latest episode: %v", err)
+	}
+	if latest == nil || latest.Season != 2 || latest.Number != 2 {
+		t.Fatalf("Expected latest episode S02E02, got %+v", latest)
+	}
+
+	all, err := storage.GetEpisodes("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get episodes: %v", err)
+	}
+	if len(all) != 7 {
+		t.Errorf("Expected 7 episodes, got %d", len(all))
+	}
+
+	if _, err := storage.SaveEpisodes("The Boys", "series", EpisodeRange{First: 1, Last: 2024}); err == nil {
+		t.Error("Expected error for implausibly large range")
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/pressly/goose/v3"
+)
+
+// The full-text index is created from Go rather than SQL because FTS5 is only
+// available when go-sqlite3 is built with the sqlite_fts5 tag. Without it the
+// migration is skipped with a warning; Initialize creates the index later if
+// the binary is rebuilt with FTS5.
+func init() {
+	goose.AddNamedMigrationContext("20250930000001_add_content_fts.go", upContentFTS, downContentFTS)
+}
+
+// contentFTSSchema creates the index over content and the triggers that keep it in sync
+var contentFTSSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS content_fts USING fts5(
+		title, extra_info, category,
+		content='content', content_rowid='id',
+		tokenize='unicode61 remove_diacritics 2',
+		prefix='2 3'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS content_fts_insert AFTER INSERT ON content BEGIN
+		INSERT INTO content_fts(rowid, title, extra_info, category) VALUES (new.id, new.title, new.extra_info, new.category);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS content_fts_delete AFTER DELETE ON content BEGIN
+		INSERT INTO content_fts(content_fts, rowid, title, extra_info, category) VALUES ('delete', old.id, old.title, old.extra_info, old.category);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS content_fts_update AFTER UPDATE ON content BEGIN
+		INSERT INTO content_fts(content_fts, rowid, title, extra_info, category) VALUES ('delete', old.id, old.title, old.extra_info, old.category);
+		INSERT INTO content_fts(rowid, title, extra_info, category) VALUES (new.id, new.title, new.extra_info, new.category);
+	END`,
+	// Index the rows that already exist
+	`INSERT INTO content_fts(content_fts) VALUES ('rebuild')`,
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func upContentFTS(ctx context.Context, tx *sql.Tx) error {
+	available, err := fts5Available(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if !available {
+		log.Println("Warning: SQLite was built without FTS5, full-text search is disabled (build with -tags sqlite_fts5)")
+		return nil
+	}
+	return createContentFTS(ctx, tx)
+}
+
+func downContentFTS(ctx context.Context, tx *sql.Tx) error {
+	for _, statement := range []string{
+		`DROP TRIGGER IF EXISTS content_fts_update`,
+		`DROP TRIGGER IF EXISTS content_fts_delete`,
+		`DROP TRIGGER IF EXISTS content_fts_insert`,
+		`DROP TABLE IF EXISTS content_fts`,
+	} {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createContentFTS(ctx context.Context, db execer) error {
+	for _, statement := range contentFTSSchema {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fts5Available reports whether the linked SQLite supports FTS5
+func fts5Available(ctx context.Context, db execer) (bool, error) {
+	var used bool
+	if err := db.QueryRowContext(ctx, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&used); err != nil {
+		return false, err
+	}
+	return used, nil
+}
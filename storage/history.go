@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ContentChange is one field change recorded when a content row was updated.
+// OldValue and NewValue are nil when the field had no value.
+type ContentChange struct {
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value,omitempty"`
+	NewValue  *string   `json:"new_value,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// String describes the change, e.g. `extra_info: "Episode 15" -> "Complete"`
+func (c ContentChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, formatHistoryValue(c.OldValue), formatHistoryValue(c.NewValue))
+}
+
+// Meaningful reports whether the change is worth telling users about.
+// Seeing a title on a different page only changes bookkeeping.
+func (c ContentChange) Meaningful() bool {
+	return c.Field != "source_url"
+}
+
+// GetContentHistory returns the change timeline of a title, oldest change first
+func (s *sqlStore) GetContentHistory(title, contentType string) ([]ContentChange, error) {
+	query := `
+	SELECT h.field, h.old_value, h.new_value, h.changed_at
+	FROM content_history h
+	JOIN content c ON c.id = h.content_id
+	WHERE c.identity_key = ` + identityKey + `
+	ORDER BY h.changed_at, h.id
+	`
+
+	rows, err := s.db.Query(query, title, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content history: %v", err)
+	}
+	defer rows.Close()
+
+	var changes []ContentChange
+	for rows.Next() {
+		var change ContentChange
+		if err := rows.Scan(&change.Field, &change.OldValue, &change.NewValue, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan content history: %v", err)
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// diffContent lists the tracked fields whose values differ between two versions of a row
+func diffContent(old, new Content) []ContentChange {
+	var changes []ContentChange
+
+	add := func(field string, oldValue, newValue *string) {
+		if equalHistoryValues(oldValue, newValue) {
+			return
+		}
+		changes = append(changes, ContentChange{Field: field, OldValue: oldValue, NewValue: newValue})
+	}
+
+	add("year", intValue(old.Year), intValue(new.Year))
+	add("category", &old.Category, &new.Category)
+	add("extra_info", &old.ExtraInfo, &new.ExtraInfo)
+	add("rating", floatValue(old.Rating), floatValue(new.Rating))
+	add("source_url", old.SourceURL, new.SourceURL)
+
+	return changes
+}
+
+// recordChanges writes changes for a content row inside the caller's transaction
+func recordChanges(tx *sqlTx, contentID int64, changes []ContentChange) error {
+	for _, change := range changes {
+		_, err := tx.Exec(`INSERT INTO content_history (content_id, field, old_value, new_value, changed_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)`, contentID, change.Field, change.OldValue, change.NewValue)
+		if err != nil {
+			return fmt.Errorf("failed to record %s change: %v", change.Field, err)
+		}
+	}
+	return nil
+}
+
+func intValue(v *int) *string {
+	if v == nil {
+		return nil
+	}
+	s := strconv.Itoa(*v)
+	return &s
+}
+
+func floatValue(v *float64) *string {
+	if v == nil {
+		return nil
+	}
+	s := strconv.FormatFloat(*v, 'f', -1, 64)
+	return &s
+}
+
+func equalHistoryValues(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatHistoryValue(v *string) string {
+	if v == nil {
+		return "(none)"
+	}
+	return strconv.Quote(*v)
+}
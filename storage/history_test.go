@@ -0,0 +1,153 @@
+package storage
+
+import "testing"
+
+func TestContentHistory(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	series := Content{
+		Title:     "The Boys",
+		Year:      &[]int{2024}[0],
+		Category:  "TV Series",
+		ExtraInfo: "Episode 15-18 Added",
+		Type:      "series",
+	}
+	if _, err := storage.SaveContents([]Content{series}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	// Saving the same values again records nothing
+	if _, err := storage.SaveContents([]Content{series}); err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	history, err := storage.GetContentHistory("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("Expected no history for unchanged content, got %v", history)
+	}
+
+	series.ExtraInfo = "Complete"
+	series.Rating = &[]float64{8.7}[0]
+	if _, err := storage.SaveContents([]Content{series}); err != nil {
+		t.Fatalf("Failed to update content: %v", err)
+	}
+
+	history, err = storage.GetContentHistory("The Boys", "series")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 changes, got %v", history)
+	}
+
+	if got := history[0].String(); got != `extra_info: "Episode 15-18 Added" -> "Complete"` {
+		t.Errorf("Unexpected extra_info change: %s", got)
+	}
+	if history[1].Field != "rating" || history[1].OldValue != nil || *history[1].NewValue != "8.7" {
+		t.Errorf("Unexpected rating change: %s", history[1])
+	}
+	if history[0].ChangedAt.IsZero() {
+		t.Error("Expected change timestamp to be set")
+	}
+}
+
+func TestSaveContentsOutcomes(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	firstURL := "https://example.com/a"
+	secondURL := "https://example.com/b"
+	contents := []Content{
+		{Title: "Civil War", Category: "Hollywood", Type: "movie", SourceURL: &firstURL},
+		{Title: "The Boys", Category: "TV Series", ExtraInfo: "Episode 5", Type: "series", SourceURL: &firstURL},
+		{Title: "Frieren", Category: "Anime", Type: "series", SourceURL: &firstURL},
+	}
+
+	results, err := storage.SaveContents(contents)
+	if err != nil {
+		t.Fatalf("Failed to save contents: %v", err)
+	}
+	for _, result := range results {
+		if result.Outcome != OutcomeInserted {
+			t.Errorf("Expected %s to be inserted, got %s", result.Content.Title, result.Outcome)
+		}
+	}
+
+	// Re-seeing items: one unchanged, one with a new episode, one only moved page
+	contents[1].ExtraInfo = "Episode 6"
+	contents[2].SourceURL = &secondURL
+
+	results, err = storage.SaveContents(contents)
+	if err != nil {
+		t.Fatalf("Failed to save contents: %v", err)
+	}
+
+	expected := []SaveOutcome{OutcomeUnchanged, OutcomeUpdated, OutcomeUnchanged}
+	for i, result := range results {
+		if result.Outcome != expected[i] {
+			t.Errorf("Expected %s to be %s, got %s", result.Content.Title, expected[i], result.Outcome)
+		}
+	}
+
+	if len(results[1].Changes) != 1 || results[1].Changes[0].Field != "extra_info" {
+		t.Errorf("Expected an extra_info change, got %v", results[1].Changes)
+	}
+
+	// Source URL changes are still kept in the history
+	history, err := storage.GetContentHistory("Frieren", "series")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(history) != 1 || history[0].Field != "source_url" {
+		t.Errorf("Expected a source_url change in history, got %v", history)
+	}
+}
+
+func TestSaveContentsIdentity(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	// Titles differing only in case or surrounding space are the same item,
+	// even within one batch
+	results, err := storage.SaveContents([]Content{
+		{Title: "The Boys", Category: "TV Series", ExtraInfo: "Episode 5", Type: "series"},
+		{Title: " the boys ", Category: "TV Series", ExtraInfo: "Episode 6", Type: "Series"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save contents: %v", err)
+	}
+	if results[0].Outcome != OutcomeInserted || results[1].Outcome != OutcomeUpdated {
+		t.Errorf("Expected inserted then updated, got %s and %s", results[0].Outcome, results[1].Outcome)
+	}
+
+	contents, err := storage.GetAllContent()
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	if len(contents) != 1 || contents[0].Title != "The Boys" || contents[0].ExtraInfo != "Episode 6" {
+		t.Fatalf("Expected a single updated row keeping the original title, got %+v", contents)
+	}
+
+	// The unique index rejects duplicates written outside SaveContents
+	db, err := storage.GetDB()
+	if err != nil {
+		t.Fatalf("Failed to get database: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO content (title, category, type, identity_key) VALUES ('THE BOYS', 'TV Series', 'series', 'the boys|series')`)
+	if err == nil {
+		t.Error("Expected unique constraint violation for duplicate identity key")
+	}
+}
@@ -0,0 +1,1290 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStorage keeps all data in process memory. It follows the same rules
+// as the SQL backends without full-text search: SearchContent and SearchText
+// match substrings like SQLite built without FTS5. It is meant for tests.
+type MemoryStorage struct {
+	mu sync.Mutex
+
+	// nextIDs holds the last id handed out per table
+	nextIDs map[string]int64
+
+	contents       map[int64]*Content
+	contentSources map[int64][]ContentSource
+	archive        []ArchivedContent
+	history        []memoryChange
+	episodes       map[int64][]Episode
+	embeddings     map[int64]memoryEmbedding
+	sources        map[int64]*Source
+	watchlist      []WatchlistEntry
+	runs           []*ScrapeRun
+	runSources     []memoryRunSource
+}
+
+// memoryChange is a content_history row
+type memoryChange struct {
+	contentID int64
+	change    ContentChange
+}
+
+// memoryEmbedding is a content_embeddings row
+type memoryEmbedding struct {
+	model  string
+	vector []float32
+}
+
+// memoryRunSource is a scrape_run_sources row
+type memoryRunSource struct {
+	id     int64
+	runID  int64
+	source ScrapeRunSource
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		nextIDs:        make(map[string]int64),
+		contents:       make(map[int64]*Content),
+		contentSources: make(map[int64][]ContentSource),
+		episodes:       make(map[int64][]Episode),
+		embeddings:     make(map[int64]memoryEmbedding),
+		sources:        make(map[int64]*Source),
+	}
+}
+
+func (s *MemoryStorage) Initialize() error {
+	log.Println("In-memory storage initialized")
+	return nil
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+func (s *MemoryStorage) nextID(table string) int64 {
+	s.nextIDs[table]++
+	return s.nextIDs[table]
+}
+
+func (s *MemoryStorage) findContent(title, contentType string) *Content {
+	key := identityOf(title, contentType)
+	for _, content := range s.contents {
+		if identityOf(content.Title, content.Type) == key {
+			return content
+		}
+	}
+	return nil
+}
+
+// SaveContents inserts or updates content items and reports for each whether
+// it was inserted, updated or unchanged
+func (s *MemoryStorage) SaveContents(contents []Content) ([]SaveResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.saveContents(contents), nil
+}
+
+// PreviewSaveContents reports what SaveContents would do without changing anything
+func (s *MemoryStorage) PreviewSaveContents(contents []Content) ([]SaveResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Save into copies of the content, sources and history, then put the originals back
+	stored, contentSources, history, nextID := s.contents, s.contentSources, s.history, s.nextIDs["content"]
+	s.contents = make(map[int64]*Content, len(stored))
+	for id, content := range stored {
+		clone := cloneContent(*content)
+		s.contents[id] = &clone
+	}
+	s.contentSources = make(map[int64][]ContentSource, len(contentSources))
+	for id, sources := range contentSources {
+		s.contentSources[id] = append([]ContentSource(nil), sources...)
+	}
+	s.history = append([]memoryChange(nil), history...)
+	defer func() {
+		s.contents, s.contentSources, s.history, s.nextIDs["content"] = stored, contentSources, history, nextID
+	}()
+
+	return s.saveContents(contents), nil
+}
+
+func (s *MemoryStorage) saveContents(contents []Content) []SaveResult {
+	results := make([]SaveResult, 0, len(contents))
+	for _, content := range contents {
+		result := SaveResult{Content: content}
+		now := time.Now().UTC()
+
+		existing := s.findContent(content.Title, content.Type)
+		if existing == nil {
+			// A new title may be another spelling of a stored one
+			if match := s.findNearDuplicate(content); match != nil {
+				existing = match
+				result.ScrapedTitle = content.Title
+				result.Content.Title, result.Content.Year = match.Title, content.Year
+			}
+		}
+		if existing == nil {
+			stored := cloneContent(content)
+			stored.ID = s.nextID("content")
+			stored.ScrapedAt, stored.CreatedAt, stored.UpdatedAt, stored.LastSeenAt = &now, &now, &now, &now
+			s.contents[stored.ID] = &stored
+			s.recordContentSource(stored.ID, content, now)
+
+			result.Content.ID = stored.ID
+			result.Outcome = OutcomeInserted
+			results = append(results, result)
+			continue
+		}
+
+		result.Content.ID = existing.ID
+		s.recordContentSource(existing.ID, content, now)
+		seen := now
+		existing.LastSeenAt = &seen
+		result.Changes = diffContent(*existing, content)
+		result.Outcome = OutcomeUnchanged
+		if len(result.Changes) > 0 {
+			updated := cloneContent(content)
+			existing.Year, existing.Category, existing.ExtraInfo = updated.Year, updated.Category, updated.ExtraInfo
+			existing.Rating, existing.SourceURL, existing.UpdatedAt = updated.Rating, updated.SourceURL, &now
+
+			for _, change := range result.Changes {
+				change.ChangedAt = now
+				s.history = append(s.history, memoryChange{contentID: existing.ID, change: change})
+				if change.Meaningful() {
+					result.Outcome = OutcomeUpdated
+				}
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func (s *MemoryStorage) GetAllContent() ([]Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.selectContent(func(Content) bool { return true }), nil
+}
+
+func (s *MemoryStorage) GetContentByType(contentType string) ([]Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.selectContent(func(c Content) bool { return c.Type == contentType }), nil
+}
+
+// selectContent returns copies of the matching content, newest first
+func (s *MemoryStorage) selectContent(match func(Content) bool) []Content {
+	var contents []Content
+	for _, content := range s.contents {
+		if match(*content) {
+			contents = append(contents, cloneContent(*content))
+		}
+	}
+
+	sort.Slice(contents, func(i, j int) bool {
+		if !contents[i].CreatedAt.Equal(*contents[j].CreatedAt) {
+			return contents[i].CreatedAt.After(*contents[j].CreatedAt)
+		}
+		return contents[i].ID > contents[j].ID
+	})
+	return contents
+}
+
+// GetContentByID returns the content with the given id, or nil if there is none
+func (s *MemoryStorage) GetContentByID(id int64) (*Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, ok := s.contents[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := cloneContent(*content)
+	return &stored, nil
+}
+
+// DeleteContent removes a content item together with its history, episodes and embeddings
+func (s *MemoryStorage) DeleteContent(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.contents[id]; !ok {
+		return fmt.Errorf("content %d not found", id)
+	}
+
+	s.deleteContent(id)
+	return nil
+}
+
+// deleteContent removes an item and its history, episodes, embeddings and sources
+func (s *MemoryStorage) deleteContent(id int64) {
+	history := s.history[:0]
+	for _, row := range s.history {
+		if row.contentID != id {
+			history = append(history, row)
+		}
+	}
+	s.history = history
+
+	delete(s.episodes, id)
+	delete(s.embeddings, id)
+	delete(s.contentSources, id)
+	delete(s.contents, id)
+}
+
+// findNearDuplicate returns the stored item a new item is a different spelling of, or nil
+func (s *MemoryStorage) findNearDuplicate(content Content) *Content {
+	var candidates []Content
+	for _, stored := range s.contents {
+		if stored.Type == content.Type {
+			candidates = append(candidates, *stored)
+		}
+	}
+	if match := bestNearDuplicate(content, candidates); match != nil {
+		return s.contents[match.ID]
+	}
+	return nil
+}
+
+// FindDuplicates groups stored items that look like the same title, the most similar groups first
+func (s *MemoryStorage) FindDuplicates(options DuplicateOptions) ([]DuplicateCluster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contents := s.selectContent(func(c Content) bool { return options.Type == "" || c.Type == options.Type })
+	return clusterDuplicates(contents, options), nil
+}
+
+// MergeContent folds duplicates into the item to keep, moving their history,
+// episodes and sources, like the SQL backends
+func (s *MemoryStorage) MergeContent(keepID int64, duplicateIDs []int64) (*Content, error) {
+	if err := checkMerge(keepID, duplicateIDs); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keep, ok := s.contents[keepID]
+	if !ok {
+		return nil, fmt.Errorf("content %d not found", keepID)
+	}
+	var duplicates []Content
+	for _, id := range duplicateIDs {
+		duplicate, ok := s.contents[id]
+		if !ok {
+			return nil, fmt.Errorf("content %d not found", id)
+		}
+		if duplicate.Type != keep.Type {
+			return nil, fmt.Errorf("cannot merge %s %d into %s %d", duplicate.Type, id, keep.Type, keepID)
+		}
+		duplicates = append(duplicates, cloneContent(*duplicate))
+	}
+
+	now := time.Now().UTC()
+	for _, duplicate := range duplicates {
+		s.moveDependents(duplicate.ID, keepID)
+		s.deleteContent(duplicate.ID)
+	}
+
+	merged, changes := mergedFields(*keep, duplicates)
+	keep.Year, keep.Rating, keep.ExtraInfo = merged.Year, merged.Rating, merged.ExtraInfo
+	keep.ScrapedAt, keep.LastSeenAt = merged.ScrapedAt, merged.LastSeenAt
+	if len(changes) > 0 {
+		keep.UpdatedAt = &now
+	}
+	for _, duplicate := range duplicates {
+		changes = append(changes, mergeChange(*keep, duplicate))
+	}
+	for _, change := range changes {
+		change.ChangedAt = now
+		s.history = append(s.history, memoryChange{contentID: keepID, change: change})
+	}
+
+	stored := cloneContent(*keep)
+	return &stored, nil
+}
+
+// moveDependents moves a duplicate's history, episodes and sources to the
+// item it is merged into, keeping the earliest sighting of shared ones
+func (s *MemoryStorage) moveDependents(fromID, toID int64) {
+	for i := range s.history {
+		if s.history[i].contentID == fromID {
+			s.history[i].contentID = toID
+		}
+	}
+
+	for _, episode := range s.episodes[fromID] {
+		found := false
+		for i, kept := range s.episodes[toID] {
+			if kept.Season == episode.Season && kept.Number == episode.Number {
+				found = true
+				if episode.FirstSeenAt.Before(kept.FirstSeenAt) {
+					s.episodes[toID][i].FirstSeenAt = episode.FirstSeenAt
+				}
+			}
+		}
+		if !found {
+			s.episodes[toID] = append(s.episodes[toID], episode)
+		}
+	}
+	sort.Slice(s.episodes[toID], func(i, j int) bool {
+		a, b := s.episodes[toID][i], s.episodes[toID][j]
+		if a.Season != b.Season {
+			return a.Season < b.Season
+		}
+		return a.Number < b.Number
+	})
+
+	for _, source := range s.contentSources[fromID] {
+		found := false
+		for i, kept := range s.contentSources[toID] {
+			if kept.URL != source.URL {
+				continue
+			}
+			found = true
+			merged := &s.contentSources[toID][i]
+			if source.FirstSeenAt.Before(merged.FirstSeenAt) {
+				merged.FirstSeenAt = source.FirstSeenAt
+			}
+			if source.LastSeenAt.After(merged.LastSeenAt) {
+				merged.LastSeenAt, merged.ExtraInfo, merged.UpdatedAt = source.LastSeenAt, source.ExtraInfo, source.UpdatedAt
+			}
+		}
+		if !found {
+			s.contentSources[toID] = append(s.contentSources[toID], source)
+		}
+	}
+}
+
+// recordContentSource notes that the item's source listed it
+func (s *MemoryStorage) recordContentSource(contentID int64, content Content, now time.Time) {
+	if content.SourceURL == nil || *content.SourceURL == "" {
+		return
+	}
+
+	sources := s.contentSources[contentID]
+	for i := range sources {
+		if sources[i].URL != *content.SourceURL {
+			continue
+		}
+		if sources[i].ExtraInfo != content.ExtraInfo {
+			sources[i].ExtraInfo, sources[i].UpdatedAt = content.ExtraInfo, now
+		}
+		sources[i].LastSeenAt = now
+		return
+	}
+	s.contentSources[contentID] = append(sources, ContentSource{URL: *content.SourceURL, ExtraInfo: content.ExtraInfo,
+		FirstSeenAt: now, LastSeenAt: now, UpdatedAt: now})
+}
+
+// GetContentSources returns the sources that listed a content item, the earliest first
+func (s *MemoryStorage) GetContentSources(contentID int64) ([]ContentSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sources := append([]ContentSource(nil), s.contentSources[contentID]...)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].FirstSeenAt.Before(sources[j].FirstSeenAt) })
+	return sources, nil
+}
+
+// GetSourceCoverage returns per source how many items it lists, how many no
+// other source has and how often it was first with an update
+func (s *MemoryStorage) GetSourceCoverage() ([]SourceCoverage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byURL := make(map[string]*SourceCoverage)
+	for _, sources := range s.contentSources {
+		for i, source := range sources {
+			coverage, ok := byURL[source.URL]
+			if !ok {
+				coverage = &SourceCoverage{URL: source.URL}
+				byURL[source.URL] = coverage
+			}
+			coverage.Items++
+			if len(sources) == 1 {
+				coverage.Exclusive++
+				continue
+			}
+			coverage.Shared++
+
+			shown, first := false, true
+			for j, other := range sources {
+				if j == i || other.ExtraInfo != source.ExtraInfo {
+					continue
+				}
+				shown = true
+				if !other.UpdatedAt.After(source.UpdatedAt) {
+					first = false
+				}
+			}
+			if source.ExtraInfo != "" && shown && first {
+				coverage.Leads++
+			}
+		}
+	}
+
+	coverage := make([]SourceCoverage, 0, len(byURL))
+	for _, source := range byURL {
+		coverage = append(coverage, *source)
+	}
+	sort.Slice(coverage, func(i, j int) bool {
+		if coverage[i].Items != coverage[j].Items {
+			return coverage[i].Items > coverage[j].Items
+		}
+		return coverage[i].URL < coverage[j].URL
+	})
+	return coverage, nil
+}
+
+// ArchiveContent moves content last seen before the given time to the
+// archive and returns the moved items
+func (s *MemoryStorage) ArchiveContent(seenBefore time.Time) ([]Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := s.expireContent(seenBefore)
+	now := time.Now().UTC()
+	for _, content := range removed {
+		s.archive = append(s.archive, ArchivedContent{Content: cloneContent(content), ArchivedAt: now})
+	}
+	return removed, nil
+}
+
+// PurgeContent deletes content last seen before the given time and returns
+// the deleted items
+func (s *MemoryStorage) PurgeContent(seenBefore time.Time) ([]Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.expireContent(seenBefore), nil
+}
+
+func (s *MemoryStorage) expireContent(seenBefore time.Time) []Content {
+	var removed []Content
+	for id, content := range s.contents {
+		if content.LastSeenAt.Before(seenBefore) {
+			removed = append(removed, cloneContent(*content))
+			s.deleteContent(id)
+		}
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i].ID < removed[j].ID })
+	return removed
+}
+
+// GetArchivedContent returns the most recently archived items, up to limit
+func (s *MemoryStorage) GetArchivedContent(limit int) ([]ArchivedContent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var archived []ArchivedContent
+	for i := len(s.archive) - 1; i >= 0 && len(archived) < limit; i-- {
+		item := s.archive[i]
+		item.Content = cloneContent(item.Content)
+		archived = append(archived, item)
+	}
+	return archived, nil
+}
+
+// Optimize has nothing to do in memory
+func (s *MemoryStorage) Optimize() error {
+	return nil
+}
+
+// SearchContent finds content whose title contains the given text, ignoring case
+func (s *MemoryStorage) SearchContent(title string) ([]Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	text := strings.ToLower(title)
+	return s.selectContent(func(c Content) bool {
+		return strings.Contains(strings.ToLower(c.Title), text)
+	}), nil
+}
+
+// SearchText finds content whose title, extra info or category contains every
+// term, ignoring case. Results have no rank or snippet.
+func (s *MemoryStorage) SearchText(query string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contents := s.selectContent(func(c Content) bool {
+		text := strings.ToLower(c.Title + "\n" + c.ExtraInfo + "\n" + c.Category)
+		for _, term := range terms {
+			if !strings.Contains(text, strings.ToLower(strings.TrimSuffix(term, "*"))) {
+				return false
+			}
+		}
+		return true
+	})
+
+	var results []SearchResult
+	for _, content := range contents {
+		if limit >= 0 && len(results) == limit {
+			break
+		}
+		results = append(results, SearchResult{Content: content})
+	}
+	return results, nil
+}
+
+// QueryContent returns a page of content matching the filter, like the SQL backends
+func (s *MemoryStorage) QueryContent(filter ContentFilter) (ContentPage, error) {
+	sortBy, limit, after, err := pageOptions(filter)
+	if err != nil {
+		return ContentPage{}, err
+	}
+
+	s.mu.Lock()
+	contents := s.selectContent(func(c Content) bool { return matchesFilter(c, filter) })
+	s.mu.Unlock()
+
+	// Order by the sort key, then id, in the requested direction
+	less := func(key any, id int64, otherKey any, otherID int64) bool {
+		order := compareSortKeys(key, otherKey)
+		if order == 0 {
+			order = compareSortKeys(float64(id), float64(otherID))
+		}
+		if filter.Descending {
+			return order > 0
+		}
+		return order < 0
+	}
+	sort.Slice(contents, func(i, j int) bool {
+		return less(memorySortKey(contents[i], sortBy), contents[i].ID, memorySortKey(contents[j], sortBy), contents[j].ID)
+	})
+
+	page := ContentPage{Total: len(contents)}
+	for _, content := range contents {
+		key := memorySortKey(content, sortBy)
+		if after != nil && !less(after.Key, after.ID, key, content.ID) {
+			continue
+		}
+		if len(page.Items) == limit {
+			last := page.Items[limit-1]
+			page.NextCursor = encodeCursor(cursor{SortBy: sortBy, Descending: filter.Descending,
+				Key: memorySortKey(last, sortBy), ID: last.ID})
+			break
+		}
+		page.Items = append(page.Items, content)
+	}
+
+	return page, nil
+}
+
+// matchesFilter applies the conditions of filterConditions to one item.
+// Like SQL comparisons, a range never matches a missing value.
+func matchesFilter(c Content, filter ContentFilter) bool {
+	switch {
+	case filter.Category != "" && !strings.EqualFold(c.Category, filter.Category):
+		return false
+	case filter.Type != "" && c.Type != filter.Type:
+		return false
+	case filter.MinYear != nil && (c.Year == nil || *c.Year < *filter.MinYear):
+		return false
+	case filter.MaxYear != nil && (c.Year == nil || *c.Year > *filter.MaxYear):
+		return false
+	case filter.MinRating != nil && (c.Rating == nil || *c.Rating < *filter.MinRating):
+		return false
+	case filter.MaxRating != nil && (c.Rating == nil || *c.Rating > *filter.MaxRating):
+		return false
+	case filter.SourceURL != "" && (c.SourceURL == nil || *c.SourceURL != filter.SourceURL):
+		return false
+	case filter.ScrapedAfter != nil && c.ScrapedAt.Before(*filter.ScrapedAfter):
+		return false
+	case filter.ScrapedBefore != nil && !c.ScrapedAt.Before(*filter.ScrapedBefore):
+		return false
+	}
+	return true
+}
+
+// memorySortKey returns the value an item sorts by, in the form it takes in a
+// decoded cursor: a float64 or a string. Missing values sort lowest, as in sortKeys.
+func memorySortKey(c Content, sortBy string) any {
+	switch sortBy {
+	case SortUpdatedAt:
+		return memoryTimeKey(c.UpdatedAt)
+	case SortRating:
+		if c.Rating == nil {
+			return float64(-1)
+		}
+		return *c.Rating
+	case SortYear:
+		if c.Year == nil {
+			return float64(0)
+		}
+		return float64(*c.Year)
+	case SortTitle:
+		return strings.ToLower(c.Title)
+	default:
+		if c.ScrapedAt == nil {
+			return memoryTimeKey(c.CreatedAt)
+		}
+		return memoryTimeKey(c.ScrapedAt)
+	}
+}
+
+// memoryTimeKey formats a time with a fixed width so that keys sort as text
+func memoryTimeKey(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000000000Z")
+}
+
+func compareSortKeys(a, b any) int {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// GetContentHistory returns the change timeline of a title, oldest change first
+func (s *MemoryStorage) GetContentHistory(title, contentType string) ([]ContentChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content := s.findContent(title, contentType)
+	if content == nil {
+		return nil, nil
+	}
+
+	var changes []ContentChange
+	for _, row := range s.history {
+		if row.contentID == content.ID {
+			changes = append(changes, row.change)
+		}
+	}
+	return changes, nil
+}
+
+// SaveEpisodes records every episode in the range for a stored series and
+// returns the episodes that were not known before, like the SQL backends
+func (s *MemoryStorage) SaveEpisodes(title, contentType string, r EpisodeRange) ([]Episode, error) {
+	if r.First == 0 {
+		return nil, nil
+	}
+	if r.Last < r.First {
+		r.Last = r.First
+	}
+	if r.Last-r.First >= maxEpisodeRange {
+		return nil, fmt.Errorf("episode range %s is too large", r)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content := s.findContent(title, contentType)
+	if content == nil {
+		return nil, fmt.Errorf("failed to find content %s: not stored", title)
+	}
+
+	known := s.episodes[content.ID]
+	season := r.Season
+	if season == 0 {
+		// Known episodes are kept in order, so the last one has the latest season
+		season = 1
+		if len(known) > 0 {
+			season = known[len(known)-1].Season
+		}
+	}
+
+	var added []Episode
+	now := time.Now().UTC()
+	for number := r.First; number <= r.Last; number++ {
+		exists := false
+		for _, episode := range known {
+			if episode.Season == season && episode.Number == number {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			episode := Episode{Season: season, Number: number, FirstSeenAt: now}
+			known = append(known, episode)
+			added = append(added, episode)
+		}
+	}
+
+	sort.Slice(known, func(i, j int) bool {
+		if known[i].Season != known[j].Season {
+			return known[i].Season < known[j].Season
+		}
+		return known[i].Number < known[j].Number
+	})
+	s.episodes[content.ID] = known
+
+	return added, nil
+}
+
+// GetEpisodes returns all known episodes of a series in order
+func (s *MemoryStorage) GetEpisodes(title, contentType string) ([]Episode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content := s.findContent(title, contentType)
+	if content == nil || len(s.episodes[content.ID]) == 0 {
+		return nil, nil
+	}
+	return append([]Episode(nil), s.episodes[content.ID]...), nil
+}
+
+// LatestEpisode returns the highest known episode of a series, or nil if none is stored
+func (s *MemoryStorage) LatestEpisode(title, contentType string) (*Episode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content := s.findContent(title, contentType)
+	if content == nil || len(s.episodes[content.ID]) == 0 {
+		return nil, nil
+	}
+	known := s.episodes[content.ID]
+	latest := known[len(known)-1]
+	return &latest, nil
+}
+
+// ListSources returns every registered source ordered by id
+func (s *MemoryStorage) ListSources() ([]Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sources []Source
+	for _, source := range s.sources {
+		sources = append(sources, cloneSource(*source))
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].ID < sources[j].ID })
+	return sources, nil
+}
+
+// GetSource returns the source with the given id, or nil if there is none
+func (s *MemoryStorage) GetSource(id int64) (*Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source, ok := s.sources[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := cloneSource(*source)
+	return &stored, nil
+}
+
+// GetSourceByURL returns the source registered for a URL, or nil if there is none
+func (s *MemoryStorage) GetSourceByURL(sourceURL string) (*Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, source := range s.sources {
+		if source.URL == sourceURL {
+			stored := cloneSource(*source)
+			return &stored, nil
+		}
+	}
+	return nil, nil
+}
+
+// AddSource registers a new source and returns its id.
+// The name defaults to the URL's host and the type to html.
+func (s *MemoryStorage) AddSource(source Source) (int64, error) {
+	if err := normalizeSource(&source); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkSourceURL(source.URL, 0); err != nil {
+		return 0, fmt.Errorf("failed to add source: %v", err)
+	}
+
+	stored := Source{ID: s.nextID("sources"), URL: source.URL, Name: source.Name, Type: source.Type,
+		Enabled: source.Enabled, Schedule: source.Schedule, ExtractionProfile: source.ExtractionProfile}
+	s.sources[stored.ID] = &stored
+	return stored.ID, nil
+}
+
+// UpdateSource saves the settings of an existing source. Health fields are left untouched.
+func (s *MemoryStorage) UpdateSource(source Source) error {
+	if err := normalizeSource(&source); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.sources[source.ID]
+	if !ok {
+		return fmt.Errorf("source %d not found", source.ID)
+	}
+	if err := s.checkSourceURL(source.URL, source.ID); err != nil {
+		return fmt.Errorf("failed to update source: %v", err)
+	}
+
+	stored.URL, stored.Name, stored.Type = source.URL, source.Name, source.Type
+	stored.Enabled, stored.Schedule, stored.ExtractionProfile = source.Enabled, source.Schedule, source.ExtractionProfile
+	return nil
+}
+
+// checkSourceURL enforces the unique URL of the sources table
+func (s *MemoryStorage) checkSourceURL(sourceURL string, id int64) error {
+	for _, source := range s.sources {
+		if source.URL == sourceURL && source.ID != id {
+			return fmt.Errorf("source URL %s is already registered", sourceURL)
+		}
+	}
+	return nil
+}
+
+// DeleteSource removes a source from the registry
+func (s *MemoryStorage) DeleteSource(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sources[id]; !ok {
+		return fmt.Errorf("source %d not found", id)
+	}
+	delete(s.sources, id)
+	return nil
+}
+
+// RecordSourceSuccess marks a successful scrape and resets the failure streak
+func (s *MemoryStorage) RecordSourceSuccess(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if source, ok := s.sources[id]; ok {
+		now := time.Now().UTC()
+		source.LastSuccessAt = &now
+		source.ConsecutiveFailures = 0
+	}
+	return nil
+}
+
+// RecordSourceFailure marks a failed scrape with its error message
+func (s *MemoryStorage) RecordSourceFailure(id int64, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if source, ok := s.sources[id]; ok {
+		now := time.Now().UTC()
+		source.LastFailureAt = &now
+		source.LastError = message
+		source.ConsecutiveFailures++
+	}
+	return nil
+}
+
+// ListWatchlist returns every watchlist entry ordered by id
+func (s *MemoryStorage) ListWatchlist() ([]WatchlistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []WatchlistEntry
+	for _, entry := range s.watchlist {
+		if entry.LastMatchedAt != nil {
+			lastMatched := *entry.LastMatchedAt
+			entry.LastMatchedAt = &lastMatched
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// AddWatchlistEntry adds a title or keyword to the watchlist and returns its id
+func (s *MemoryStorage) AddWatchlistEntry(entry WatchlistEntry) (int64, error) {
+	if err := normalizeWatchlistEntry(&entry); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.watchlist {
+		if existing.Kind == entry.Kind && existing.Pattern == entry.Pattern &&
+			existing.Type == entry.Type && existing.Category == entry.Category {
+			return 0, fmt.Errorf("failed to add watchlist entry: %s %q is already watched", entry.Kind, entry.Pattern)
+		}
+	}
+
+	stored := WatchlistEntry{ID: s.nextID("watchlist"), Kind: entry.Kind, Pattern: entry.Pattern,
+		Type: entry.Type, Category: entry.Category, CreatedAt: time.Now().UTC()}
+	s.watchlist = append(s.watchlist, stored)
+	return stored.ID, nil
+}
+
+// DeleteWatchlistEntry removes an entry from the watchlist
+func (s *MemoryStorage) DeleteWatchlistEntry(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, entry := range s.watchlist {
+		if entry.ID == id {
+			s.watchlist = append(s.watchlist[:i], s.watchlist[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("watchlist entry %d not found", id)
+}
+
+// RecordWatchlistMatch counts an item a scrape found for an entry
+func (s *MemoryStorage) RecordWatchlistMatch(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.watchlist {
+		if s.watchlist[i].ID == id {
+			now := time.Now().UTC()
+			s.watchlist[i].Matches++
+			s.watchlist[i].LastMatchedAt = &now
+		}
+	}
+	return nil
+}
+
+// StartScrapeRun records the start of a run and returns its id
+func (s *MemoryStorage) StartScrapeRun(trigger string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run := &ScrapeRun{ID: s.nextID("scrape_runs"), Trigger: trigger, StartedAt: time.Now().UTC()}
+	s.runs = append(s.runs, run)
+	return run.ID, nil
+}
+
+// RecordScrapeRunSource stores the outcome of one source of a run
+func (s *MemoryStorage) RecordScrapeRunSource(runID int64, source ScrapeRunSource) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source.StartedAt = source.StartedAt.UTC()
+	source.FinishedAt = source.FinishedAt.UTC()
+	s.runSources = append(s.runSources, memoryRunSource{id: s.nextID("scrape_run_sources"), runID: runID, source: source})
+	return nil
+}
+
+// FinishScrapeRun records the end of a run and the error that ended it, if any
+func (s *MemoryStorage) FinishScrapeRun(runID int64, runErr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, run := range s.runs {
+		if run.ID == runID {
+			now := time.Now().UTC()
+			run.FinishedAt = &now
+			run.Error = runErr
+		}
+	}
+	return nil
+}
+
+// RecentScrapeRuns returns the latest runs with their per-source outcomes, newest first
+func (s *MemoryStorage) RecentScrapeRuns(limit int) ([]ScrapeRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var runs []ScrapeRun
+	for i := len(s.runs) - 1; i >= 0 && (limit < 0 || len(runs) < limit); i-- {
+		run := *s.runs[i]
+		if run.FinishedAt != nil {
+			finishedAt := *run.FinishedAt
+			run.FinishedAt = &finishedAt
+		}
+		for _, row := range s.runSources {
+			if row.runID == run.ID {
+				run.Sources = append(run.Sources, row.source)
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// FailureStreaks returns the URLs whose latest scrapes failed at least minFailures
+// times in a row, longest streak first
+func (s *MemoryStorage) FailureStreaks(minFailures int) ([]FailureStreak, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Walk the outcomes in order; a success ends the streak of its URL
+	streaks := make(map[string]*FailureStreak)
+	for _, row := range s.runSources {
+		url := row.source.URL
+		if row.source.Error == "" {
+			delete(streaks, url)
+			continue
+		}
+		streak, ok := streaks[url]
+		if !ok {
+			streak = &FailureStreak{URL: url, Since: row.source.StartedAt}
+			streaks[url] = streak
+		}
+		streak.Failures++
+		streak.LastError = row.source.Error
+		if row.source.StartedAt.Before(streak.Since) {
+			streak.Since = row.source.StartedAt
+		}
+	}
+
+	var result []FailureStreak
+	for _, streak := range streaks {
+		if streak.Failures >= minFailures {
+			result = append(result, *streak)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Failures != result[j].Failures {
+			return result[i].Failures > result[j].Failures
+		}
+		return result[i].URL < result[j].URL
+	})
+	return result, nil
+}
+
+// SaveEmbedding stores the embedding vector for an existing content item
+func (s *MemoryStorage) SaveEmbedding(content Content, model string, vector []float32) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("cannot save empty embedding for %s", content.Title)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Items that were not loaded from storage are found by identity
+	contentID := content.ID
+	if contentID == 0 {
+		stored := s.findContent(content.Title, content.Type)
+		if stored == nil {
+			return fmt.Errorf("failed to find content %s: not stored", content.Title)
+		}
+		contentID = stored.ID
+	} else if _, ok := s.contents[contentID]; !ok {
+		return fmt.Errorf("failed to save embedding: content %d not found", contentID)
+	}
+
+	s.embeddings[contentID] = memoryEmbedding{model: model, vector: append([]float32(nil), vector...)}
+	return nil
+}
+
+// GetContentWithoutEmbedding returns content that has no embedding from the given model yet
+func (s *MemoryStorage) GetContentWithoutEmbedding(model string, limit int) ([]Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contents := s.selectContent(func(c Content) bool {
+		embedding, ok := s.embeddings[c.ID]
+		return !ok || embedding.model != model
+	})
+	if limit >= 0 && len(contents) > limit {
+		contents = contents[:limit]
+	}
+	return contents, nil
+}
+
+// SemanticSearch ranks stored content by cosine similarity to a query vector
+// produced by the same embedding model
+func (s *MemoryStorage) SemanticSearch(model string, query []float32, limit int) ([]ScoredContent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []ScoredContent
+	for id, embedding := range s.embeddings {
+		if embedding.model != model || len(embedding.vector) != len(query) {
+			continue
+		}
+		results = append(results, ScoredContent{
+			Content: cloneContent(*s.contents[id]),
+			Score:   cosineSimilarity(query, embedding.vector),
+		})
+	}
+
+	// Map order is random, so break score ties by id
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return topScored(results, limit), nil
+}
+
+func (s *MemoryStorage) GetStats() (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := map[string]int{"total": len(s.contents), "movies": 0, "series": 0}
+	for _, content := range s.contents {
+		switch content.Type {
+		case "movie":
+			stats["movies"]++
+		case "series":
+			stats["series"]++
+		}
+	}
+	return stats, nil
+}
+
+// GetDetailedStats returns counts per category, source and period, the top
+// rated new items, the most updated series and extraction rates per source
+func (s *MemoryStorage) GetDetailedStats(options StatsOptions) (DetailedStats, error) {
+	periods := newStatsPeriods(options)
+
+	// Top rated new items go through the same filter as the SQL backends
+	page, err := s.QueryContent(periods.topNewFilter())
+	if err != nil {
+		return DetailedStats{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := DetailedStats{GeneratedAt: periods.options.Now, Total: len(s.contents), TopNew: page.Items}
+
+	categories := make(map[string]*CategoryStats)
+	sources := make(map[string]*SourceCount)
+	ratingSums := make(map[string]float64)
+	var scrapedAt []time.Time
+	for _, content := range s.contents {
+		switch content.Type {
+		case "movie":
+			stats.Movies++
+		case "series":
+			stats.Series++
+		}
+
+		category := categories[content.Category]
+		if category == nil {
+			category = &CategoryStats{Category: content.Category}
+			categories[content.Category] = category
+		}
+		category.Count++
+		if content.Rating != nil {
+			category.Rated++
+			ratingSums[content.Category] += *content.Rating
+		}
+
+		sourceURL := ""
+		if content.SourceURL != nil {
+			sourceURL = *content.SourceURL
+		}
+		if sources[sourceURL] == nil {
+			sources[sourceURL] = &SourceCount{SourceURL: sourceURL}
+		}
+		sources[sourceURL].Count++
+
+		if content.ScrapedAt != nil && !content.ScrapedAt.Before(periods.since()) {
+			scrapedAt = append(scrapedAt, *content.ScrapedAt)
+		}
+	}
+	for name, category := range categories {
+		if category.Rated > 0 {
+			average := ratingSums[name] / float64(category.Rated)
+			category.AverageRating = &average
+		}
+		stats.Categories = append(stats.Categories, *category)
+	}
+	for _, source := range sources {
+		stats.Sources = append(stats.Sources, *source)
+	}
+	periods.count(&stats, scrapedAt)
+
+	for id, episodes := range s.episodes {
+		updates := SeriesUpdates{Content: cloneContent(*s.contents[id])}
+		for _, episode := range episodes {
+			if !episode.FirstSeenAt.Before(periods.firstWeek) {
+				updates.NewEpisodes++
+			}
+		}
+		if updates.NewEpisodes > 0 {
+			stats.MostUpdatedSeries = append(stats.MostUpdatedSeries, updates)
+		}
+	}
+
+	extraction := make(map[string]*SourceExtraction)
+	for _, row := range s.runSources {
+		if row.source.StartedAt.Before(periods.firstWeek) {
+			continue
+		}
+		e := extraction[row.source.URL]
+		if e == nil {
+			e = &SourceExtraction{URL: row.source.URL}
+			extraction[row.source.URL] = e
+		}
+		e.Scrapes++
+		if row.source.Error == "" {
+			e.Succeeded++
+		}
+		e.Extracted += row.source.Extracted
+		e.Rejected += row.source.Rejected
+		e.Filtered += row.source.Filtered
+		e.Saved += row.source.Saved
+	}
+	for _, e := range extraction {
+		e.computeRates()
+		stats.Extraction = append(stats.Extraction, *e)
+	}
+
+	sortStats(&stats)
+	if len(stats.MostUpdatedSeries) > periods.options.Limit {
+		stats.MostUpdatedSeries = stats.MostUpdatedSeries[:periods.options.Limit]
+	}
+	return stats, nil
+}
+
+// cloneContent copies an item so that callers cannot change stored values
+func cloneContent(c Content) Content {
+	clone := c
+	if c.Year != nil {
+		year := *c.Year
+		clone.Year = &year
+	}
+	if c.Rating != nil {
+		rating := *c.Rating
+		clone.Rating = &rating
+	}
+	if c.SourceURL != nil {
+		sourceURL := *c.SourceURL
+		clone.SourceURL = &sourceURL
+	}
+	for _, t := range []**time.Time{&clone.ScrapedAt, &clone.CreatedAt, &clone.UpdatedAt, &clone.LastSeenAt} {
+		if *t != nil {
+			value := **t
+			*t = &value
+		}
+	}
+	return clone
+}
+
+func cloneSource(source Source) Source {
+	clone := source
+	if source.LastSuccessAt != nil {
+		lastSuccess := *source.LastSuccessAt
+		clone.LastSuccessAt = &lastSuccess
+	}
+	if source.LastFailureAt != nil {
+		lastFailure := *source.LastFailureAt
+		clone.LastFailureAt = &lastFailure
+	}
+	return clone
+}
@@ -1,10 +1,15 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/pressly/goose/v3"
 )
@@ -12,6 +17,10 @@ import (
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
 
+// minSQLiteVersion is the oldest SQLite the schema supports: migrations drop
+// columns with ALTER TABLE DROP COLUMN and the storage uses RETURNING
+const minSQLiteVersion = "3.35.0"
+
 type MigrationManager struct {
 	db *sql.DB
 }
@@ -21,6 +30,10 @@ func NewMigrationManager(db *sql.DB) *MigrationManager {
 }
 
 func (m *MigrationManager) Initialize() error {
+	if err := checkSQLiteVersion(m.db); err != nil {
+		return err
+	}
+
 	// Set the base filesystem for migrations
 	goose.SetBaseFS(embedMigrations)
 
@@ -32,6 +45,35 @@ func (m *MigrationManager) Initialize() error {
 	return nil
 }
 
+// checkSQLiteVersion returns an error when the SQLite library is older than
+// minSQLiteVersion
+func checkSQLiteVersion(db *sql.DB) error {
+	var version string
+	if err := db.QueryRow(`SELECT sqlite_version()`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to get SQLite version: %v", err)
+	}
+	if !versionAtLeast(version, minSQLiteVersion) {
+		return fmt.Errorf("SQLite %s is too old, %s or newer is required", version, minSQLiteVersion)
+	}
+	return nil
+}
+
+// versionAtLeast compares dotted version numbers
+func versionAtLeast(version, min string) bool {
+	have, want := strings.Split(version, "."), strings.Split(min, ".")
+	for i, part := range want {
+		w, _ := strconv.Atoi(part)
+		h := 0
+		if i < len(have) {
+			h, _ = strconv.Atoi(have[i])
+		}
+		if h != w {
+			return h > w
+		}
+	}
+	return true
+}
+
 func (m *MigrationManager) Up() error {
 	if err := goose.Up(m.db, "migrations"); err != nil {
 		return fmt.Errorf("failed to run migrations: %v", err)
@@ -40,6 +82,22 @@ func (m *MigrationManager) Up() error {
 	return nil
 }
 
+// UpTo applies migrations up to and including the given version
+func (m *MigrationManager) UpTo(version int64) error {
+	if err := goose.UpTo(m.db, "migrations", version); err != nil {
+		return fmt.Errorf("failed to run migrations to version %d: %v", version, err)
+	}
+	return nil
+}
+
+// DownTo rolls back migrations until the given version is the latest applied
+func (m *MigrationManager) DownTo(version int64) error {
+	if err := goose.DownTo(m.db, "migrations", version); err != nil {
+		return fmt.Errorf("failed to roll back to version %d: %v", version, err)
+	}
+	return nil
+}
+
 func (m *MigrationManager) Down() error {
 	if err := goose.Down(m.db, "migrations"); err != nil {
 		return fmt.Errorf("failed to rollback migration: %v", err)
@@ -70,3 +128,58 @@ func (m *MigrationManager) Reset() error {
 	log.Println("Database reset completed successfully")
 	return nil
 }
+
+// MigrationResult is a pending migration applied by DryRun. Rows and Changed
+// are only counted by Go data migrations.
+type MigrationResult struct {
+	Version int64  `json:"version"`
+	Source  string `json:"source"`
+	Data    bool   `json:"data"`
+	Rows    int    `json:"rows"`
+	Changed int    `json:"changed"`
+}
+
+// DryRun applies the pending migrations to a scratch copy of the database
+// and reports what they did. The database itself is not changed.
+func (m *MigrationManager) DryRun() ([]MigrationResult, error) {
+	dir, err := os.MkdirTemp("", "cine-pulse-dry-run")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "dry_run.db")
+	if _, err := m.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return nil, fmt.Errorf("failed to copy database: %v", err)
+	}
+	scratch, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scratch database: %v", err)
+	}
+	defer scratch.Close()
+
+	current, err := goose.EnsureDBVersion(scratch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database version: %v", err)
+	}
+	pending, err := goose.CollectMigrations("migrations", current, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect migrations: %v", err)
+	}
+
+	progress := make(map[string]*migrationProgress)
+	ctx := context.WithValue(context.Background(), dryRunKey{}, progress)
+	if err := goose.UpContext(ctx, scratch, "migrations"); err != nil {
+		return nil, fmt.Errorf("migrations would fail: %v", err)
+	}
+
+	results := make([]MigrationResult, 0, len(pending))
+	for _, migration := range pending {
+		result := MigrationResult{Version: migration.Version, Source: filepath.Base(migration.Source)}
+		if p, ok := progress[result.Source]; ok {
+			result.Data, result.Rows, result.Changed = true, p.rows, p.changed
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -8,8 +8,31 @@ ALTER TABLE content ADD COLUMN scraped_at DATETIME DEFAULT CURRENT_TIMESTAMP;
 CREATE INDEX IF NOT EXISTS idx_content_rating ON content(rating);
 
 -- +goose Down
--- Remove added columns (SQLite doesn't support DROP COLUMN directly)
--- This would require recreating the table in a real scenario
--- For demonstration purposes, we'll just drop the index
+-- Rebuild the table with the columns of the initial schema. No triggers
+-- exist on content at this version. The Up can't add scraped_at with its
+-- CURRENT_TIMESTAMP default to a table with rows, so migrating up again from
+-- here needs an empty content table.
+CREATE TABLE content_rebuild (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    year INTEGER,
+    category TEXT NOT NULL,
+    extra_info TEXT,
+    type TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
 
-DROP INDEX IF EXISTS idx_content_rating;
+INSERT INTO content_rebuild (id, title, year, category, extra_info, type, created_at, updated_at)
+SELECT id, title, year, category, extra_info, type, created_at, updated_at FROM content;
+
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'content')
+WHERE name = 'content_rebuild';
+
+DROP TABLE content;
+ALTER TABLE content_rebuild RENAME TO content;
+
+CREATE INDEX IF NOT EXISTS idx_content_title ON content(title);
+CREATE INDEX IF NOT EXISTS idx_content_type ON content(type);
+CREATE INDEX IF NOT EXISTS idx_content_category ON content(category);
+CREATE INDEX IF NOT EXISTS idx_content_year ON content(year);
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Store one embedding vector per content row for semantic search
+CREATE TABLE IF NOT EXISTS content_embeddings (
+    content_id INTEGER PRIMARY KEY REFERENCES content(id) ON DELETE CASCADE,
+    model TEXT NOT NULL,
+    dimensions INTEGER NOT NULL,
+    vector BLOB NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_embeddings_model ON content_embeddings(model);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_embeddings_model;
+DROP TABLE IF EXISTS content_embeddings;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Record every change made to a content row so previous states are not lost
+CREATE TABLE IF NOT EXISTS content_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    content_id INTEGER NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    changed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_history_content_id ON content_history(content_id, changed_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_history_content_id;
+DROP TABLE IF EXISTS content_history;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Identify content by its normalized title and type so the same item can only be stored once
+ALTER TABLE content ADD COLUMN identity_key TEXT;
+
+UPDATE content SET identity_key = lower(trim(title)) || '|' || lower(trim(type));
+
+-- Keep the most recently updated row of each duplicate group
+CREATE TEMP TABLE content_keep AS
+SELECT identity_key, (
+    SELECT c2.id FROM content c2
+    WHERE c2.identity_key = c.identity_key
+    ORDER BY c2.updated_at DESC, c2.id DESC
+    LIMIT 1
+) AS keep_id
+FROM content c
+GROUP BY identity_key;
+
+-- Move the history of removed duplicates onto the row that is kept
+UPDATE content_history
+SET content_id = (
+    SELECT k.keep_id FROM content c
+    JOIN content_keep k ON k.identity_key = c.identity_key
+    WHERE c.id = content_history.content_id
+)
+WHERE content_id IN (SELECT id FROM content WHERE id NOT IN (SELECT keep_id FROM content_keep));
+
+DELETE FROM content_embeddings WHERE content_id NOT IN (SELECT keep_id FROM content_keep);
+DELETE FROM content WHERE id NOT IN (SELECT keep_id FROM content_keep);
+
+DROP TABLE content_keep;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_content_identity_key ON content(identity_key);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_identity_key;
+-- DROP COLUMN needs SQLite 3.35.0, the minimum the storage checks for
+ALTER TABLE content DROP COLUMN identity_key;
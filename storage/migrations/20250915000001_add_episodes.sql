@@ -0,0 +1,13 @@
+-- +goose Up
+-- Track individual episodes of series so progress survives across runs
+CREATE TABLE IF NOT EXISTS episodes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    content_id INTEGER NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    season INTEGER NOT NULL,
+    episode INTEGER NOT NULL,
+    first_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(content_id, season, episode)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS episodes;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Registry of pages to scrape with per-source settings and health
+CREATE TABLE IF NOT EXISTS sources (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'html',
+    enabled INTEGER NOT NULL DEFAULT 1,
+    schedule TEXT NOT NULL DEFAULT '',
+    extraction_profile TEXT NOT NULL DEFAULT '',
+    last_success_at DATETIME,
+    last_failure_at DATETIME,
+    last_error TEXT NOT NULL DEFAULT '',
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The source that used to be hardcoded as the default is registered at
+-- startup, when SOURCE_URLS is unset and the registry is empty
+
+-- +goose Down
+DROP TABLE IF EXISTS sources;
@@ -0,0 +1,38 @@
+-- +goose Up
+-- One row per scraper job run
+CREATE TABLE IF NOT EXISTS scrape_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    trigger TEXT NOT NULL,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+-- One row per source scraped within a run
+CREATE TABLE IF NOT EXISTS scrape_run_sources (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id INTEGER NOT NULL REFERENCES scrape_runs(id) ON DELETE CASCADE,
+    source_id INTEGER,
+    url TEXT NOT NULL,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    http_status INTEGER NOT NULL DEFAULT 0,
+    bytes INTEGER NOT NULL DEFAULT 0,
+    model TEXT NOT NULL DEFAULT '',
+    extracted INTEGER NOT NULL DEFAULT 0,
+    rejected INTEGER NOT NULL DEFAULT 0,
+    filtered INTEGER NOT NULL DEFAULT 0,
+    saved INTEGER NOT NULL DEFAULT 0,
+    inserted INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_run_sources_run_id ON scrape_run_sources(run_id);
+CREATE INDEX IF NOT EXISTS idx_scrape_run_sources_url ON scrape_run_sources(url, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_scrape_run_sources_url;
+DROP INDEX IF EXISTS idx_scrape_run_sources_run_id;
+DROP TABLE IF EXISTS scrape_run_sources;
+DROP TABLE IF EXISTS scrape_runs;
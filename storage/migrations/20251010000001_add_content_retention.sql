@@ -0,0 +1,38 @@
+-- +goose Up
+-- When a scrape last returned each item, whether or not it changed
+ALTER TABLE content ADD COLUMN last_seen_at DATETIME;
+
+UPDATE content SET last_seen_at = COALESCE(updated_at, scraped_at, created_at, CURRENT_TIMESTAMP);
+
+CREATE INDEX IF NOT EXISTS idx_content_last_seen_at ON content(last_seen_at);
+
+-- Items removed by the retention policy, with the id they had in content
+CREATE TABLE IF NOT EXISTS content_archive (
+    id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL,
+    year INTEGER,
+    category TEXT NOT NULL,
+    extra_info TEXT,
+    type TEXT NOT NULL,
+    rating REAL,
+    source_url TEXT,
+    identity_key TEXT NOT NULL,
+    scraped_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+    last_seen_at DATETIME,
+    archived_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_archive_identity_key ON content_archive(identity_key);
+CREATE INDEX IF NOT EXISTS idx_content_archive_archived_at ON content_archive(archived_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_archive_archived_at;
+DROP INDEX IF EXISTS idx_content_archive_identity_key;
+DROP TABLE IF EXISTS content_archive;
+DROP INDEX IF EXISTS idx_content_last_seen_at;
+-- Dropping the column in place keeps the full-text triggers on content, which a
+-- rebuild would drop. DROP COLUMN needs SQLite 3.35.0, the minimum the storage
+-- checks for.
+ALTER TABLE content DROP COLUMN last_seen_at;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Every source that listed an item, with its own extra_info. content.source_url
+-- keeps the source that saw the item last.
+CREATE TABLE IF NOT EXISTS content_sources (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    content_id INTEGER NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    source_url TEXT NOT NULL,
+    extra_info TEXT NOT NULL DEFAULT '',
+    first_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    -- When extra_info last changed on this source
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(content_id, source_url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_sources_source_url ON content_sources(source_url);
+
+-- Only the last source of existing items is known; it is credited from when
+-- the item was first scraped
+INSERT INTO content_sources (content_id, source_url, extra_info, first_seen_at, last_seen_at, updated_at)
+SELECT id, source_url, COALESCE(extra_info, ''), COALESCE(scraped_at, created_at), last_seen_at, updated_at
+FROM content
+WHERE source_url IS NOT NULL AND source_url != '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_sources_source_url;
+DROP TABLE IF EXISTS content_sources;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Title reduced to its identifying words, used to find near-identical titles.
+-- It is computed in Go; Initialize fills it for rows stored before.
+ALTER TABLE content ADD COLUMN title_key TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_content_type_title_key ON content(type, title_key);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_type_title_key;
+-- DROP COLUMN needs SQLite 3.35.0, the minimum the storage checks for
+ALTER TABLE content DROP COLUMN title_key;
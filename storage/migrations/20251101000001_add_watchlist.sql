@@ -0,0 +1,19 @@
+-- +goose Up
+-- Titles and keywords the team wants an alert for when a scrape finds a new
+-- item or episode matching them
+CREATE TABLE IF NOT EXISTS watchlist (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    -- 'title' matches one title, 'keyword' any item mentioning all its words
+    kind TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    -- Empty matches every type or category
+    content_type TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    matches INTEGER NOT NULL DEFAULT 0,
+    last_matched_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(kind, pattern, content_type, category)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS watchlist;
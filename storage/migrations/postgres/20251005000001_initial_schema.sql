@@ -0,0 +1,115 @@
+-- +goose Up
+-- Postgres schema, equivalent to the SQLite migrations up to 20250930000001
+CREATE TABLE IF NOT EXISTS content (
+    id BIGSERIAL PRIMARY KEY,
+    title TEXT NOT NULL,
+    year INTEGER,
+    category TEXT NOT NULL,
+    extra_info TEXT,
+    type TEXT NOT NULL,
+    rating DOUBLE PRECISION,
+    source_url TEXT,
+    identity_key TEXT NOT NULL,
+    scraped_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Full-text index: titles rank above categories, then extra info
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(category, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(extra_info, '')), 'C')
+    ) STORED
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_content_identity_key ON content(identity_key);
+CREATE INDEX IF NOT EXISTS idx_content_title ON content(title);
+CREATE INDEX IF NOT EXISTS idx_content_type ON content(type);
+CREATE INDEX IF NOT EXISTS idx_content_category ON content(category);
+CREATE INDEX IF NOT EXISTS idx_content_year ON content(year);
+CREATE INDEX IF NOT EXISTS idx_content_rating ON content(rating);
+CREATE INDEX IF NOT EXISTS idx_content_search_vector ON content USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS content_embeddings (
+    content_id BIGINT PRIMARY KEY REFERENCES content(id) ON DELETE CASCADE,
+    model TEXT NOT NULL,
+    dimensions INTEGER NOT NULL,
+    vector BYTEA NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_embeddings_model ON content_embeddings(model);
+
+CREATE TABLE IF NOT EXISTS content_history (
+    id BIGSERIAL PRIMARY KEY,
+    content_id BIGINT NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_history_content_id ON content_history(content_id, changed_at);
+
+CREATE TABLE IF NOT EXISTS episodes (
+    id BIGSERIAL PRIMARY KEY,
+    content_id BIGINT NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    season INTEGER NOT NULL,
+    episode INTEGER NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(content_id, season, episode)
+);
+
+CREATE TABLE IF NOT EXISTS sources (
+    id BIGSERIAL PRIMARY KEY,
+    url TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'html',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    schedule TEXT NOT NULL DEFAULT '',
+    extraction_profile TEXT NOT NULL DEFAULT '',
+    last_success_at TIMESTAMPTZ,
+    last_failure_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS scrape_runs (
+    id BIGSERIAL PRIMARY KEY,
+    trigger TEXT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS scrape_run_sources (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES scrape_runs(id) ON DELETE CASCADE,
+    source_id BIGINT,
+    url TEXT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    http_status INTEGER NOT NULL DEFAULT 0,
+    bytes INTEGER NOT NULL DEFAULT 0,
+    model TEXT NOT NULL DEFAULT '',
+    extracted INTEGER NOT NULL DEFAULT 0,
+    rejected INTEGER NOT NULL DEFAULT 0,
+    filtered INTEGER NOT NULL DEFAULT 0,
+    saved INTEGER NOT NULL DEFAULT 0,
+    inserted INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_run_sources_run_id ON scrape_run_sources(run_id);
+CREATE INDEX IF NOT EXISTS idx_scrape_run_sources_url ON scrape_run_sources(url, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS scrape_run_sources;
+DROP TABLE IF EXISTS scrape_runs;
+DROP TABLE IF EXISTS sources;
+DROP TABLE IF EXISTS episodes;
+DROP TABLE IF EXISTS content_history;
+DROP TABLE IF EXISTS content_embeddings;
+DROP TABLE IF EXISTS content;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- When a scrape last returned each item, whether or not it changed
+ALTER TABLE content ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE content SET last_seen_at = updated_at;
+
+CREATE INDEX IF NOT EXISTS idx_content_last_seen_at ON content(last_seen_at);
+
+-- Items removed by the retention policy, with the id they had in content
+CREATE TABLE IF NOT EXISTS content_archive (
+    id BIGINT PRIMARY KEY,
+    title TEXT NOT NULL,
+    year INTEGER,
+    category TEXT NOT NULL,
+    extra_info TEXT,
+    type TEXT NOT NULL,
+    rating DOUBLE PRECISION,
+    source_url TEXT,
+    identity_key TEXT NOT NULL,
+    scraped_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    last_seen_at TIMESTAMPTZ,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_archive_identity_key ON content_archive(identity_key);
+CREATE INDEX IF NOT EXISTS idx_content_archive_archived_at ON content_archive(archived_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS content_archive;
+DROP INDEX IF EXISTS idx_content_last_seen_at;
+ALTER TABLE content DROP COLUMN IF EXISTS last_seen_at;
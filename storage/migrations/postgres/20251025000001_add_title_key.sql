@@ -0,0 +1,10 @@
+-- +goose Up
+-- Title reduced to its identifying words, used to find near-identical titles.
+-- It is computed in Go; Initialize fills it for rows stored before.
+ALTER TABLE content ADD COLUMN IF NOT EXISTS title_key TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_content_type_title_key ON content(type, title_key);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_content_type_title_key;
+ALTER TABLE content DROP COLUMN IF EXISTS title_key;
@@ -1,7 +1,12 @@
 package storage
 
 import (
+	"cine-pulse/titles"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -118,3 +123,369 @@ func TestMigrationManager(t *testing.T) {
 		t.Errorf("Expected version to decrease after rollback: %d -> %d", version, newVersion)
 	}
 }
+
+func TestIdentityKeyMigrationDeduplicates(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "dedupe.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	migrationManager := NewMigrationManager(db)
+	if err := migrationManager.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize migration manager: %v", err)
+	}
+
+	// Stop just before the identity key is introduced
+	if err := migrationManager.UpTo(20250905000001); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	// Two copies of the same series, the second one more recent
+	_, err = db.Exec(`
+	INSERT INTO content (id, title, category, extra_info, type, updated_at) VALUES
+		(1, 'The Boys', 'TV Series', 'Episode 5', 'series', '2025-01-01 10:00:00'),
+		(2, 'the boys ', 'TV Series', 'Episode 6', 'series', '2025-01-02 10:00:00'),
+		(3, 'Civil War', 'Hollywood', '', 'movie', '2025-01-01 10:00:00');
+	INSERT INTO content_history (content_id, field, old_value, new_value) VALUES (1, 'extra_info', 'Episode 4', 'Episode 5');
+	INSERT INTO content_embeddings (content_id, model, dimensions, vector) VALUES (1, 'test', 1, x'00000000');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to insert duplicates: %v", err)
+	}
+
+	if err := migrationManager.Up(); err != nil {
+		t.Fatalf("Failed to run remaining migrations: %v", err)
+	}
+
+	var ids []int
+	rows, err := db.Query(`SELECT id FROM content ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Failed to query content: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("Failed to scan id: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Fatalf("Expected rows 2 and 3 to remain, got %v", ids)
+	}
+
+	// History follows the kept row, embeddings of removed rows are dropped
+	var historyOwner int
+	if err := db.QueryRow(`SELECT content_id FROM content_history`).Scan(&historyOwner); err != nil {
+		t.Fatalf("Failed to query history: %v", err)
+	}
+	if historyOwner != 2 {
+		t.Errorf("Expected history to move to row 2, got %d", historyOwner)
+	}
+
+	var embeddings int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM content_embeddings`).Scan(&embeddings); err != nil {
+		t.Fatalf("Failed to count embeddings: %v", err)
+	}
+	if embeddings != 0 {
+		t.Errorf("Expected embeddings of removed rows to be deleted, got %d", embeddings)
+	}
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roundtrip.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	migrationManager := NewMigrationManager(db)
+	if err := migrationManager.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize migration manager: %v", err)
+	}
+	if err := migrationManager.Up(); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO content (id, title, category, extra_info, type, rating, identity_key)
+		VALUES (7, 'Civil War', 'Hollywood', '', 'movie', 7.1, 'civil war|movie')`)
+	if err != nil {
+		t.Fatalf("Failed to insert content: %v", err)
+	}
+
+	// Back past every later migration and up again, keeping the rows
+	if err := migrationManager.DownTo(20250820000002); err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+	if _, err := db.Exec(`SELECT identity_key FROM content`); err == nil {
+		t.Error("Expected the identity_key column to be gone")
+	}
+	if err := migrationManager.Up(); err != nil {
+		t.Fatalf("Failed to migrate up again: %v", err)
+	}
+	differences, err := verifySchema(path)
+	if err != nil {
+		t.Fatalf("Failed to verify schema: %v", err)
+	}
+	if len(differences) != 0 {
+		t.Errorf("Expected the schema to match after down and up, got %v", differences)
+	}
+	var title string
+	if err := db.QueryRow(`SELECT title FROM content WHERE id = 7`).Scan(&title); err != nil || title != "Civil War" {
+		t.Fatalf("Expected the content to survive the round trip, got %q, %v", title, err)
+	}
+
+	// Back to the initial schema: the rebuilt table keeps its rows
+	if err := migrationManager.DownTo(20250820000001); err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+	if err := db.QueryRow(`SELECT title FROM content WHERE id = 7`).Scan(&title); err != nil || title != "Civil War" {
+		t.Fatalf("Expected the content to survive the rollback, got %q, %v", title, err)
+	}
+	if _, err := db.Exec(`SELECT rating FROM content`); err == nil {
+		t.Error("Expected the rating column to be gone")
+	}
+
+	// Every migration can be rolled back and applied again
+	if err := migrationManager.Reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+	if err := migrationManager.Up(); err != nil {
+		t.Fatalf("Failed to migrate up after reset: %v", err)
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"3.35.0", true},
+		{"3.50.4", true},
+		{"4.0", true},
+		{"3.34.1", false},
+		{"3.9.2", false},
+		{"3", false},
+	}
+	for _, tt := range tests {
+		if got := versionAtLeast(tt.version, minSQLiteVersion); got != tt.want {
+			t.Errorf("versionAtLeast(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestVerifySchemaReportsDrift(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSQLiteStorage(dir)
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	path := filepath.Join(dir, sqliteFileName)
+	differences, err := verifySchema(path)
+	if err != nil {
+		t.Fatalf("Failed to verify schema: %v", err)
+	}
+	if len(differences) != 0 {
+		t.Fatalf("Expected a freshly migrated database to match, got %v", differences)
+	}
+
+	for _, statement := range []string{
+		`DROP INDEX idx_content_rating`,
+		`ALTER TABLE content ADD COLUMN notes TEXT`,
+		`CREATE TABLE scratch (id INTEGER)`,
+		`DELETE FROM goose_db_version WHERE version_id = (SELECT MAX(version_id) FROM goose_db_version)`,
+	} {
+		if _, err := store.db.Exec(statement); err != nil {
+			t.Fatalf("Failed to change schema: %v", err)
+		}
+	}
+
+	differences, err = verifySchema(path)
+	if err != nil {
+		t.Fatalf("Failed to verify schema: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, difference := range differences {
+		found[difference.String()] = true
+	}
+	for _, expected := range []string{
+		"index idx_content_rating: missing",
+		"table content: unexpected column notes",
+		"table scratch: not created by the migrations",
+	} {
+		if !found[expected] {
+			t.Errorf("Expected %q in %v", expected, differences)
+		}
+	}
+	if len(differences) != 4 || differences[0].Object != "version" {
+		t.Errorf("Expected 4 differences starting with the version, got %v", differences)
+	}
+}
+
+// titleFixture migrates a database to just before the title migration and
+// stores content saved before titles were normalized
+func titleFixture(t *testing.T, path string) (*sql.DB, *MigrationManager) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrationManager := NewMigrationManager(db)
+	if err := migrationManager.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize migration manager: %v", err)
+	}
+	if err := migrationManager.UpTo(20251010000001); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	_, err = db.Exec(`
+	INSERT INTO content (id, title, year, category, extra_info, type, identity_key) VALUES
+		(1, 'The  Boys', NULL, 'TV Series', 'Episode 5', 'series', 'the  boys|series'),
+		(2, 'Civil War (2024)', NULL, 'Hollywood', '', 'movie', 'civil war (2024)|movie'),
+		(3, 'Inside Out', 2015, 'Hollywood', '', 'movie', 'inside out|movie'),
+		(4, 'Inside  Out', 2015, 'Hollywood', '', 'movie', 'inside  out|movie');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to insert content: %v", err)
+	}
+	return db, migrationManager
+}
+
+func storedTitles(t *testing.T, db *sql.DB) map[int64]string {
+	rows, err := db.Query(`SELECT id, title, COALESCE(year, 0), identity_key FROM content ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Failed to query content: %v", err)
+	}
+	defer rows.Close()
+
+	titles := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var title, key string
+		var year int
+		if err := rows.Scan(&id, &title, &year, &key); err != nil {
+			t.Fatalf("Failed to scan content: %v", err)
+		}
+		titles[id] = fmt.Sprintf("%s/%d/%s", title, year, key)
+	}
+	return titles
+}
+
+func TestNormalizeTitlesMigration(t *testing.T) {
+	db, migrationManager := titleFixture(t, filepath.Join(t.TempDir(), "titles.db"))
+	before := storedTitles(t, db)
+
+	if err := migrationManager.UpTo(20251015000001); err != nil {
+		t.Fatalf("Failed to run the title migration: %v", err)
+	}
+	after := storedTitles(t, db)
+	expected := map[int64]string{
+		1: "The Boys/0/the boys|series",
+		2: "Civil War/2024/civil war|movie",
+		3: "Inside Out/2015/inside out|movie",
+		// Would collide with row 3, so it is left for duplicate detection
+		4: "Inside  Out/2015/inside  out|movie",
+	}
+	for id, want := range expected {
+		if after[id] != want {
+			t.Errorf("Expected row %d to be %q, got %q", id, want, after[id])
+		}
+	}
+
+	if err := migrationManager.DownTo(20251010000001); err != nil {
+		t.Fatalf("Failed to roll back the title migration: %v", err)
+	}
+	restored := storedTitles(t, db)
+	for id, want := range before {
+		if restored[id] != want {
+			t.Errorf("Expected row %d to be restored to %q, got %q", id, want, restored[id])
+		}
+	}
+	if _, err := db.Exec(`SELECT * FROM content_title_originals`); err == nil {
+		t.Error("Expected content_title_originals to be dropped")
+	}
+}
+
+// TestNormalizeTitleMatchesTitles checks the migration's frozen copy of the
+// title cleanup against titles.Normalize on the extractor's golden titles
+// and messier spellings of them
+func TestNormalizeTitleMatchesTitles(t *testing.T) {
+	files, err := filepath.Glob("../extractor/testdata/*.golden.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Failed to find the golden files: %v", err)
+	}
+
+	corpus := []string{"  The   Boys ", "Dune: Part Two [2024]", "**Civil War**", "`Oppenheimer` (2023)", "1917", "(500) Days of Summer"}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		var items []Content
+		if err := json.Unmarshal(data, &items); err != nil {
+			t.Fatalf("Failed to parse %s: %v", file, err)
+		}
+		for _, item := range items {
+			corpus = append(corpus, item.Title, " "+item.Title+"  (2019)", `"`+item.Title+`"`, "_"+item.Title+"_ [2001]")
+		}
+	}
+
+	for _, title := range corpus {
+		for _, year := range []*int{nil, &[]int{2020}[0]} {
+			stored := sql.NullInt64{}
+			if year != nil {
+				stored = sql.NullInt64{Int64: int64(*year), Valid: true}
+			}
+			migrated, migratedYear := normalizeTitle(title, stored)
+			want, wantYear := titles.Normalize(title, year)
+			if migrated != want || migratedYear.Valid != (wantYear != nil) || (wantYear != nil && migratedYear.Int64 != int64(*wantYear)) {
+				t.Errorf("Copies disagree on %q: migration %q/%v, titles %q/%v", title, migrated, migratedYear, want, wantYear)
+			}
+		}
+	}
+}
+
+func TestMigrationDryRun(t *testing.T) {
+	db, migrationManager := titleFixture(t, filepath.Join(t.TempDir(), "dry_run.db"))
+	before := storedTitles(t, db)
+
+	results, err := migrationManager.DryRun()
+	if err != nil {
+		t.Fatalf("Failed to dry run migrations: %v", err)
+	}
+	var found bool
+	for _, result := range results {
+		if result.Version <= 20251010000001 {
+			t.Errorf("Expected only pending migrations, got %+v", result)
+		}
+		if result.Version == 20251015000001 {
+			found = true
+			if !result.Data || result.Rows != 4 || result.Changed != 2 {
+				t.Errorf("Unexpected title migration result: %+v", result)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("Expected the title migration in %+v", results)
+	}
+
+	// Nothing was applied to the database itself
+	version, err := migrationManager.Version()
+	if err != nil {
+		t.Fatalf("Failed to get version: %v", err)
+	}
+	if version != 20251010000001 {
+		t.Errorf("Expected the version to stay at 20251010000001, got %d", version)
+	}
+	after := storedTitles(t, db)
+	for id, want := range before {
+		if after[id] != want {
+			t.Errorf("Expected row %d to be unchanged, got %q", id, after[id])
+		}
+	}
+}
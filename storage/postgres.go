@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed migrations/postgres/*.sql
+var embedPostgresMigrations embed.FS
+
+// PostgresStorage keeps content in a PostgreSQL database, which lets several
+// instances share one database. Full-text search uses a generated tsvector
+// column instead of FTS5.
+type PostgresStorage struct {
+	sqlStore
+	databaseURL string
+}
+
+func NewPostgresStorage(databaseURL string) *PostgresStorage {
+	return &PostgresStorage{databaseURL: databaseURL}
+}
+
+func (s *PostgresStorage) Initialize() error {
+	if err := s.open("postgres", s.databaseURL, dialectPostgres); err != nil {
+		return err
+	}
+	if err := s.db.Ping(); err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+
+	if err := s.RunMigrations(); err != nil {
+		return err
+	}
+	if err := s.backfillTitleKeys(); err != nil {
+		return err
+	}
+
+	s.fullText = true
+	log.Println("PostgreSQL database initialized")
+	return nil
+}
+
+// migrationProvider runs the Postgres migrations. A session lock keeps
+// instances starting at the same time from migrating concurrently.
+func (s *PostgresStorage) migrationProvider() (*goose.Provider, error) {
+	migrations, err := fs.Sub(embedPostgresMigrations, "migrations/postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %v", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, s.db.DB, migrations,
+		goose.WithDisableGlobalRegistry(true), goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize migrations: %v", err)
+	}
+	return provider, nil
+}
+
+func (s *PostgresStorage) GetDatabaseVersion() (int64, error) {
+	provider, err := s.migrationProvider()
+	if err != nil {
+		return 0, err
+	}
+	version, err := provider.GetDBVersion(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get database version: %v", err)
+	}
+	return version, nil
+}
+
+func (s *PostgresStorage) RunMigrations() error {
+	provider, err := s.migrationProvider()
+	if err != nil {
+		return err
+	}
+	if _, err := provider.Up(context.Background()); err != nil {
+		return fmt.Errorf("failed to run migrations: %v", err)
+	}
+	log.Println("Database migrations completed successfully")
+	return nil
+}
+
+func (s *PostgresStorage) RollbackMigration() error {
+	provider, err := s.migrationProvider()
+	if err != nil {
+		return err
+	}
+	if _, err := provider.Down(context.Background()); err != nil {
+		return fmt.Errorf("failed to rollback migration: %v", err)
+	}
+	log.Println("Database migration rolled back successfully")
+	return nil
+}
+
+func (s *PostgresStorage) ResetDatabase() error {
+	provider, err := s.migrationProvider()
+	if err != nil {
+		return err
+	}
+	if _, err := provider.DownTo(context.Background(), 0); err != nil {
+		return fmt.Errorf("failed to reset database: %v", err)
+	}
+	log.Println("Database reset completed successfully")
+	return nil
+}
+
+// MigrationStatus logs every migration and when it was applied
+func (s *PostgresStorage) MigrationStatus() error {
+	provider, err := s.migrationProvider()
+	if err != nil {
+		return err
+	}
+	statuses, err := provider.Status(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %v", err)
+	}
+
+	log.Println("    Applied At                  Migration")
+	log.Println("    =======================================")
+	for _, status := range statuses {
+		applied := "Pending"
+		if status.State == goose.StateApplied {
+			applied = status.AppliedAt.Format(time.ANSIC)
+		}
+		log.Printf("    %-24s -- %s", applied, path.Base(status.Source.Path))
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sort fields for QueryContent
+const (
+	SortScrapedAt = "scraped_at"
+	SortUpdatedAt = "updated_at"
+	SortRating    = "rating"
+	SortYear      = "year"
+	SortTitle     = "title"
+)
+
+// sortKeys maps sort fields to the expressions they order by. Missing values
+// sort as the lowest value so that every row has a key for the cursor.
+var sortKeys = map[string]string{
+	SortScrapedAt: "COALESCE(c.scraped_at, c.created_at, '')",
+	SortUpdatedAt: "COALESCE(c.updated_at, '')",
+	SortRating:    "COALESCE(c.rating, -1)",
+	SortYear:      "COALESCE(c.year, 0)",
+	SortTitle:     "lower(c.title)",
+}
+
+// postgresSortKeys replaces the keys that differ on Postgres, where the
+// timestamp columns are never NULL
+var postgresSortKeys = map[string]string{
+	SortScrapedAt: "c.scraped_at",
+	SortUpdatedAt: "c.updated_at",
+}
+
+const (
+	defaultQueryLimit = 50
+	maxQueryLimit     = 500
+)
+
+// ContentFilter selects, sorts and pages content for QueryContent.
+// Zero values mean "no restriction"; ranges are inclusive.
+type ContentFilter struct {
+	Category      string
+	Type          string
+	MinYear       *int
+	MaxYear       *int
+	MinRating     *float64
+	MaxRating     *float64
+	SourceURL     string
+	ScrapedAfter  *time.Time
+	ScrapedBefore *time.Time
+
+	// SortBy is one of the Sort constants, SortScrapedAt by default
+	SortBy     string
+	Descending bool
+
+	// Limit is the page size, 50 by default and at most 500
+	Limit int
+	// Cursor continues from a previous page's NextCursor
+	Cursor string
+}
+
+// ContentPage is one page of QueryContent results.
+// Total counts every item matching the filter, across all pages.
+// NextCursor is empty on the last page.
+type ContentPage struct {
+	Items      []Content `json:"items"`
+	Total      int       `json:"total"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// cursor is the position after the last item of a page
+type cursor struct {
+	SortBy     string `json:"s"`
+	Descending bool   `json:"d"`
+	Key        any    `json:"k"`
+	ID         int64  `json:"i"`
+}
+
+// QueryContent returns a page of content matching the filter.
+// Pages are keyed on the sort value and row id, so rows added between
+// requests do not shift later pages.
+func (s *sqlStore) QueryContent(filter ContentFilter) (ContentPage, error) {
+	sortBy, limit, after, err := pageOptions(filter)
+	if err != nil {
+		return ContentPage{}, err
+	}
+	sortKey := sortKeys[sortBy]
+	if key, ok := postgresSortKeys[sortBy]; ok && s.dialect == dialectPostgres {
+		sortKey = key
+	}
+
+	where, args := filterConditions(filter, s.dialect)
+
+	var page ContentPage
+	countQuery := `SELECT COUNT(*) FROM content c` + whereClause(where)
+	if err := s.db.QueryRow(countQuery, args...).Scan(&page.Total); err != nil {
+		return ContentPage{}, fmt.Errorf("failed to count content: %v", err)
+	}
+
+	// Continue after the cursor position
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if after != nil {
+		where = append(where, fmt.Sprintf("(%s %s ? OR (%s = ? AND c.id %s ?))", sortKey, comparison, sortKey, comparison))
+		args = append(args, after.Key, after.Key, after.ID)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s, %s
+	FROM content c%s
+	ORDER BY %s %s, c.id %s
+	LIMIT ?
+	`, contentColumns, sortKey, whereClause(where), sortKey, direction, direction)
+
+	// Fetch one extra row to know whether there is a next page
+	rows, err := s.db.Query(query, append(args, limit+1)...)
+	if err != nil {
+		return ContentPage{}, fmt.Errorf("failed to query content: %v", err)
+	}
+	defer rows.Close()
+
+	var last cursor
+	for rows.Next() {
+		var key any
+		content, err := scanContent(rows, &key)
+		if err != nil {
+			return ContentPage{}, fmt.Errorf("failed to scan content: %v", err)
+		}
+
+		if len(page.Items) == limit {
+			page.NextCursor = encodeCursor(last)
+			break
+		}
+
+		// The driver returns text keys as bytes
+		if b, ok := key.([]byte); ok {
+			key = string(b)
+		}
+		last = cursor{SortBy: sortBy, Descending: filter.Descending, Key: key, ID: content.ID}
+		page.Items = append(page.Items, content)
+	}
+
+	return page, rows.Err()
+}
+
+// pageOptions checks the sort field and cursor of a filter and returns them
+// with the page size. The cursor is nil on the first page.
+func pageOptions(filter ContentFilter) (string, int, *cursor, error) {
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = SortScrapedAt
+	}
+	if _, ok := sortKeys[sortBy]; !ok {
+		return "", 0, nil, fmt.Errorf("unknown sort field %q", sortBy)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+	if limit > maxQueryLimit {
+		limit = maxQueryLimit
+	}
+
+	if filter.Cursor == "" {
+		return sortBy, limit, nil, nil
+	}
+	after, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if after.SortBy != sortBy || after.Descending != filter.Descending {
+		return "", 0, nil, fmt.Errorf("cursor does not match the requested sort order")
+	}
+	return sortBy, limit, &after, nil
+}
+
+// filterConditions builds the WHERE conditions for a filter
+func filterConditions(filter ContentFilter, d dialect) ([]string, []any) {
+	var where []string
+	var args []any
+
+	add := func(condition string, arg any) {
+		where = append(where, condition)
+		args = append(args, arg)
+	}
+
+	if filter.Category != "" {
+		add("lower(c.category) = ?", strings.ToLower(filter.Category))
+	}
+	if filter.Type != "" {
+		add("c.type = ?", filter.Type)
+	}
+	if filter.MinYear != nil {
+		add("c.year >= ?", *filter.MinYear)
+	}
+	if filter.MaxYear != nil {
+		add("c.year <= ?", *filter.MaxYear)
+	}
+	if filter.MinRating != nil {
+		add("c.rating >= ?", *filter.MinRating)
+	}
+	if filter.MaxRating != nil {
+		add("c.rating <= ?", *filter.MaxRating)
+	}
+	if filter.SourceURL != "" {
+		add("c.source_url = ?", filter.SourceURL)
+	}
+	if filter.ScrapedAfter != nil {
+		add("c.scraped_at >= ?", d.timeArg(*filter.ScrapedAfter))
+	}
+	if filter.ScrapedBefore != nil {
+		add("c.scraped_at < ?", d.timeArg(*filter.ScrapedBefore))
+	}
+
+	return where, args
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor: %v", err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("invalid cursor: %v", err)
+	}
+	return c, nil
+}
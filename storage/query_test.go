@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func queryTestStorage(t *testing.T) *SQLiteStorage {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	t.Cleanup(func() { storage.Close() })
+
+	_, err := storage.SaveContents([]Content{
+		{Title: "Civil War", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{7.1}[0], SourceURL: &[]string{"https://a.example"}[0]},
+		{Title: "Dune: Part Two", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{8.6}[0], SourceURL: &[]string{"https://a.example"}[0]},
+		{Title: "Old Classic", Year: &[]int{1999}[0], Category: "Hollywood", Type: "movie", Rating: &[]float64{5.2}[0], SourceURL: &[]string{"https://b.example"}[0]},
+		{Title: "Unrated Movie", Year: &[]int{2023}[0], Category: "Nollywood", Type: "movie", SourceURL: &[]string{"https://b.example"}[0]},
+		{Title: "The Boys", Category: "TV Series", Type: "series", Rating: &[]float64{8.7}[0], SourceURL: &[]string{"https://a.example"}[0]},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	// Spread the scrape times out over a week
+	db, _ := storage.GetDB()
+	_, err = db.Exec(`
+	UPDATE content SET scraped_at = CASE title
+		WHEN 'Civil War' THEN '2025-09-08 09:00:00'
+		WHEN 'Dune: Part Two' THEN '2025-09-10 09:00:00'
+		WHEN 'Old Classic' THEN '2025-09-03 09:00:00'
+		WHEN 'Unrated Movie' THEN '2025-09-11 09:00:00'
+		ELSE '2025-09-09 09:00:00' END
+	`)
+	if err != nil {
+		t.Fatalf("Failed to set scrape times: %v", err)
+	}
+
+	return storage
+}
+
+func TestQueryContentFilters(t *testing.T) {
+	storage := queryTestStorage(t)
+
+	// Movies since Monday rated 7+, best first
+	monday := time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC)
+	page, err := storage.QueryContent(ContentFilter{
+		Type:         "movie",
+		MinRating:    &[]float64{7}[0],
+		ScrapedAfter: &monday,
+		SortBy:       SortRating,
+		Descending:   true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to query content: %v", err)
+	}
+
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Fatalf("Expected 2 items, got total %d and %v", page.Total, page.Items)
+	}
+	if page.Items[0].Title != "Dune: Part Two" || page.Items[1].Title != "Civil War" {
+		t.Errorf("Expected Dune before Civil War, got %s, %s", page.Items[0].Title, page.Items[1].Title)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("Expected no next cursor on the last page, got %q", page.NextCursor)
+	}
+
+	tests := []struct {
+		name   string
+		filter ContentFilter
+		total  int
+	}{
+		{"category ignores case", ContentFilter{Category: "hollywood"}, 3},
+		{"year range", ContentFilter{MinYear: &[]int{2000}[0], MaxYear: &[]int{2023}[0]}, 1},
+		{"max rating", ContentFilter{MaxRating: &[]float64{8}[0]}, 2},
+		{"source", ContentFilter{SourceURL: "https://b.example"}, 2},
+		{"scraped before", ContentFilter{ScrapedBefore: &monday}, 1},
+		{"no filter", ContentFilter{}, 5},
+	}
+	for _, tt := range tests {
+		page, err := storage.QueryContent(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: failed to query content: %v", tt.name, err)
+		}
+		if page.Total != tt.total || len(page.Items) != tt.total {
+			t.Errorf("%s: expected %d items, got total %d and %d items", tt.name, tt.total, page.Total, len(page.Items))
+		}
+	}
+
+	if _, err := storage.QueryContent(ContentFilter{SortBy: "popularity"}); err == nil {
+		t.Error("Expected error for unknown sort field")
+	}
+}
+
+func TestQueryContentPagination(t *testing.T) {
+	storage := queryTestStorage(t)
+
+	for _, sortBy := range []string{SortScrapedAt, SortRating, SortYear, SortTitle} {
+		for _, descending := range []bool{false, true} {
+			all, err := storage.QueryContent(ContentFilter{SortBy: sortBy, Descending: descending})
+			if err != nil {
+				t.Fatalf("Failed to query content: %v", err)
+			}
+
+			// Walking two items at a time visits the same items in the same order
+			var walked []string
+			filter := ContentFilter{SortBy: sortBy, Descending: descending, Limit: 2}
+			for pages := 0; ; pages++ {
+				if pages > len(all.Items) {
+					t.Fatalf("%s: pagination did not terminate", sortBy)
+				}
+				page, err := storage.QueryContent(filter)
+				if err != nil {
+					t.Fatalf("%s: failed to query page: %v", sortBy, err)
+				}
+				if page.Total != 5 {
+					t.Errorf("%s: expected total 5 on every page, got %d", sortBy, page.Total)
+				}
+				walked = append(walked, contentTitles(page.Items)...)
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+
+			expected := contentTitles(all.Items)
+			if len(walked) != len(expected) {
+				t.Fatalf("%s desc=%v: expected %v, got %v", sortBy, descending, expected, walked)
+			}
+			for i := range expected {
+				if walked[i] != expected[i] {
+					t.Fatalf("%s desc=%v: expected %v, got %v", sortBy, descending, expected, walked)
+				}
+			}
+		}
+	}
+
+	// Items without a rating sort last when descending
+	page, err := storage.QueryContent(ContentFilter{SortBy: SortRating, Descending: true})
+	if err != nil {
+		t.Fatalf("Failed to query content: %v", err)
+	}
+	if last := page.Items[len(page.Items)-1]; last.Title != "Unrated Movie" {
+		t.Errorf("Expected unrated item last, got %s", last.Title)
+	}
+
+	// A cursor only continues the sort order it came from
+	first, err := storage.QueryContent(ContentFilter{SortBy: SortRating, Limit: 1})
+	if err != nil {
+		t.Fatalf("Failed to query content: %v", err)
+	}
+	if _, err := storage.QueryContent(ContentFilter{SortBy: SortTitle, Cursor: first.NextCursor}); err == nil {
+		t.Error("Expected error for cursor from another sort order")
+	}
+	if _, err := storage.QueryContent(ContentFilter{Cursor: "not a cursor"}); err == nil {
+		t.Error("Expected error for invalid cursor")
+	}
+}
+
+func contentTitles(items []Content) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.Title)
+	}
+	return result
+}
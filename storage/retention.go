@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// What the retention policy does with content that is no longer seen
+const (
+	// RetentionArchive moves the item to content_archive
+	RetentionArchive = "archive"
+	// RetentionPurge deletes the item
+	RetentionPurge = "purge"
+)
+
+// ContentRetention decides which content the maintenance job removes.
+// Either way an item's history, episodes and embeddings are deleted; the
+// archive keeps its last values.
+type ContentRetention struct {
+	// MaxUnseen removes items no scrape has returned for this long; zero keeps everything
+	MaxUnseen time.Duration
+	// Action is RetentionArchive or RetentionPurge
+	Action string
+}
+
+// MaintenanceConfig configures the scheduled maintenance job
+type MaintenanceConfig struct {
+	// Schedule is a six-field cron specification; empty disables maintenance
+	Schedule  string
+	Retention ContentRetention
+}
+
+// MaintenanceConfigFromEnv reads MAINTENANCE_SCHEDULE, RETENTION_DAYS (0, keep
+// everything, by default) and RETENTION_ACTION (archive by default)
+func MaintenanceConfigFromEnv() MaintenanceConfig {
+	config := MaintenanceConfig{
+		Schedule:  os.Getenv("MAINTENANCE_SCHEDULE"),
+		Retention: ContentRetention{Action: RetentionArchive},
+	}
+
+	if value := os.Getenv("RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			log.Printf("Invalid RETENTION_DAYS %q, keeping all content", value)
+		} else {
+			config.Retention.MaxUnseen = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	switch value := os.Getenv("RETENTION_ACTION"); value {
+	case "", RetentionArchive:
+	case RetentionPurge:
+		config.Retention.Action = RetentionPurge
+	default:
+		log.Printf("Invalid RETENTION_ACTION %q, archiving instead", value)
+	}
+
+	return config
+}
+
+// ArchivedContent is an item moved to the archive by the retention policy.
+// Its ID is the one it had before.
+type ArchivedContent struct {
+	Content
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// ArchiveContent moves content last seen before the given time to the
+// archive and returns the moved items
+func (s *sqlStore) ArchiveContent(seenBefore time.Time) ([]Content, error) {
+	return s.expireContent(seenBefore, true)
+}
+
+// PurgeContent deletes content last seen before the given time and returns
+// the deleted items
+func (s *sqlStore) PurgeContent(seenBefore time.Time) ([]Content, error) {
+	return s.expireContent(seenBefore, false)
+}
+
+func (s *sqlStore) expireContent(seenBefore time.Time, archive bool) ([]Content, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Deleting first returns exactly the rows removed, even if a scrape marks
+	// an item as seen meanwhile. SQLite's RETURNING does not accept the table
+	// alias. The full-text index is updated by its trigger.
+	returning := strings.ReplaceAll(contentColumns, "c.", "")
+	rows, err := tx.Query(`DELETE FROM content WHERE last_seen_at < ? RETURNING `+returning,
+		s.dialect.timeArg(seenBefore))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired content: %v", err)
+	}
+	removed, err := scanContents(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, content := range removed {
+		if err := deleteDependents(tx, content.ID); err != nil {
+			return nil, err
+		}
+		if !archive {
+			continue
+		}
+
+		_, err := tx.Exec(`
+		INSERT INTO content_archive (id, title, year, category, extra_info, type, rating, source_url, identity_key,
+			scraped_at, created_at, updated_at, last_seen_at, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, `+identityKey+`, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		`, content.ID, content.Title, content.Year, content.Category, content.ExtraInfo, content.Type,
+			content.Rating, content.SourceURL, content.Title, content.Type,
+			s.dialect.nullTimeArg(content.ScrapedAt), s.dialect.nullTimeArg(content.CreatedAt),
+			s.dialect.nullTimeArg(content.UpdatedAt), s.dialect.nullTimeArg(content.LastSeenAt))
+		if err != nil {
+			return nil, fmt.Errorf("failed to archive %s: %v", content.Title, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit expired content: %v", err)
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i].ID < removed[j].ID })
+	return removed, nil
+}
+
+// GetArchivedContent returns the most recently archived items, up to limit
+func (s *sqlStore) GetArchivedContent(limit int) ([]ArchivedContent, error) {
+	rows, err := s.db.Query(`
+	SELECT `+contentColumns+`, c.archived_at
+	FROM content_archive c
+	ORDER BY c.archived_at DESC, c.id DESC
+	LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archived content: %v", err)
+	}
+	defer rows.Close()
+
+	var archived []ArchivedContent
+	for rows.Next() {
+		var item ArchivedContent
+		item.Content, err = scanContent(rows, &item.ArchivedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan archived content: %v", err)
+		}
+		archived = append(archived, item)
+	}
+	return archived, rows.Err()
+}
+
+// Optimize reclaims the space of deleted rows and refreshes the statistics
+// the query planner uses
+func (s *sqlStore) Optimize() error {
+	statements := []string{"VACUUM", "ANALYZE"}
+	if s.dialect == dialectPostgres {
+		statements = []string{"VACUUM (ANALYZE)"}
+	}
+
+	for _, statement := range statements {
+		if _, err := s.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to run %s: %v", statement, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArchiveContentBySeenTime(t *testing.T) {
+	store := NewSQLiteStorage(t.TempDir())
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+
+	results, err := store.SaveContents(contractItems())
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	// Two items were last seen long ago
+	old := dialectSQLite.timeArg(time.Now().AddDate(0, 0, -200))
+	for _, result := range results[:2] {
+		if _, err := store.db.Exec(`UPDATE content SET last_seen_at = ? WHERE id = ?`, old, result.Content.ID); err != nil {
+			t.Fatalf("Failed to age content: %v", err)
+		}
+	}
+
+	// Saving an unchanged item marks it as seen again
+	saved, err := store.SaveContents(contractItems()[:1])
+	if err != nil || saved[0].Outcome != OutcomeUnchanged {
+		t.Fatalf("Expected an unchanged save, got %+v, %v", saved, err)
+	}
+
+	removed, err := store.ArchiveContent(time.Now().AddDate(0, 0, -180))
+	if err != nil {
+		t.Fatalf("Failed to archive content: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Title != "Dune: Part Two" {
+		t.Fatalf("Expected only Dune: Part Two to be archived, got %+v", removed)
+	}
+
+	var key string
+	if err := store.db.QueryRow(`SELECT identity_key FROM content_archive WHERE id = ?`, removed[0].ID).Scan(&key); err != nil {
+		t.Fatalf("Failed to read the archive: %v", err)
+	}
+	if key != "dune: part two|movie" {
+		t.Errorf("Unexpected identity key %q", key)
+	}
+
+	if err := store.Optimize(); err != nil {
+		t.Errorf("Failed to optimize: %v", err)
+	}
+}
+
+func TestMaintenanceConfigFromEnv(t *testing.T) {
+	t.Setenv("MAINTENANCE_SCHEDULE", "0 30 3 * * *")
+	t.Setenv("RETENTION_DAYS", "180")
+	t.Setenv("RETENTION_ACTION", "purge")
+
+	config := MaintenanceConfigFromEnv()
+	if config.Schedule != "0 30 3 * * *" || config.Retention.MaxUnseen != 180*24*time.Hour || config.Retention.Action != RetentionPurge {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	t.Setenv("RETENTION_DAYS", "")
+	t.Setenv("RETENTION_ACTION", "shred")
+	config = MaintenanceConfigFromEnv()
+	if config.Retention.MaxUnseen != 0 || config.Retention.Action != RetentionArchive {
+		t.Errorf("Expected to keep everything and archive by default, got %+v", config.Retention)
+	}
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ScrapeRun is one run of the scraper job
+type ScrapeRun struct {
+	ID         int64             `json:"id"`
+	Trigger    string            `json:"trigger"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Sources    []ScrapeRunSource `json:"sources"`
+}
+
+// ScrapeRunSource is the outcome of scraping one source during a run.
+// An empty Error means the source was scraped successfully.
+type ScrapeRunSource struct {
+	SourceID   int64     `json:"source_id,omitempty"`
+	URL        string    `json:"url"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	HTTPStatus int       `json:"http_status"`
+	Bytes      int       `json:"bytes"`
+	Model      string    `json:"model,omitempty"`
+	Extracted  int       `json:"extracted"`
+	Rejected   int       `json:"rejected"`
+	Filtered   int       `json:"filtered"`
+	Saved      int       `json:"saved"`
+	Inserted   int       `json:"inserted"`
+	Updated    int       `json:"updated"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// FailureStreak counts the failed scrapes of a URL since its last success
+type FailureStreak struct {
+	URL       string    `json:"url"`
+	Failures  int       `json:"failures"`
+	Since     time.Time `json:"since"`
+	LastError string    `json:"last_error"`
+}
+
+// StartScrapeRun records the start of a run and returns its id
+func (s *sqlStore) StartScrapeRun(trigger string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(`INSERT INTO scrape_runs (trigger, started_at) VALUES (?, ?) RETURNING id`,
+		trigger, time.Now().UTC()).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start scrape run: %v", err)
+	}
+	return id, nil
+}
+
+// RecordScrapeRunSource stores the outcome of one source of a run
+func (s *sqlStore) RecordScrapeRunSource(runID int64, source ScrapeRunSource) error {
+	var sourceID any
+	if source.SourceID != 0 {
+		sourceID = source.SourceID
+	}
+
+	_, err := s.db.Exec(`
+	INSERT INTO scrape_run_sources (run_id, source_id, url, started_at, finished_at, http_status, bytes, model,
+		extracted, rejected, filtered, saved, inserted, updated, error)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, runID, sourceID, source.URL, source.StartedAt.UTC(), source.FinishedAt.UTC(), source.HTTPStatus, source.Bytes,
+		source.Model, source.Extracted, source.Rejected, source.Filtered, source.Saved, source.Inserted, source.Updated,
+		source.Error)
+	if err != nil {
+		return fmt.Errorf("failed to record scrape run source: %v", err)
+	}
+	return nil
+}
+
+// FinishScrapeRun records the end of a run and the error that ended it, if any
+func (s *sqlStore) FinishScrapeRun(runID int64, runErr string) error {
+	_, err := s.db.Exec(`UPDATE scrape_runs SET finished_at = ?, error = ? WHERE id = ?`, time.Now().UTC(), runErr, runID)
+	if err != nil {
+		return fmt.Errorf("failed to finish scrape run: %v", err)
+	}
+	return nil
+}
+
+// RecentScrapeRuns returns the latest runs with their per-source outcomes, newest first
+func (s *sqlStore) RecentScrapeRuns(limit int) ([]ScrapeRun, error) {
+	rows, err := s.db.Query(`SELECT id, trigger, started_at, finished_at, error FROM scrape_runs ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape runs: %v", err)
+	}
+	defer rows.Close()
+
+	var runs []ScrapeRun
+	index := make(map[int64]int)
+	for rows.Next() {
+		var run ScrapeRun
+		var finishedAt sql.NullTime
+		if err := rows.Scan(&run.ID, &run.Trigger, &run.StartedAt, &finishedAt, &run.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan scrape run: %v", err)
+		}
+		if finishedAt.Valid {
+			run.FinishedAt = &finishedAt.Time
+		}
+		index[run.ID] = len(runs)
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	// Attach the sources of the selected runs
+	sourceRows, err := s.db.Query(`
+	SELECT run_id, COALESCE(source_id, 0), url, started_at, finished_at, http_status, bytes, model,
+		extracted, rejected, filtered, saved, inserted, updated, error
+	FROM scrape_run_sources
+	WHERE run_id >= ?
+	ORDER BY id
+	`, runs[len(runs)-1].ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape run sources: %v", err)
+	}
+	defer sourceRows.Close()
+
+	for sourceRows.Next() {
+		var runID int64
+		var source ScrapeRunSource
+		err := sourceRows.Scan(&runID, &source.SourceID, &source.URL, &source.StartedAt, &source.FinishedAt,
+			&source.HTTPStatus, &source.Bytes, &source.Model, &source.Extracted, &source.Rejected, &source.Filtered,
+			&source.Saved, &source.Inserted, &source.Updated, &source.Error)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scrape run source: %v", err)
+		}
+		if i, ok := index[runID]; ok {
+			runs[i].Sources = append(runs[i].Sources, source)
+		}
+	}
+
+	return runs, sourceRows.Err()
+}
+
+// FailureStreaks returns the URLs whose latest scrapes failed at least minFailures
+// times in a row, longest streak first
+func (s *sqlStore) FailureStreaks(minFailures int) ([]FailureStreak, error) {
+	query := `
+	SELECT f.url, COUNT(*), MIN(f.started_at),
+		(SELECT l.error FROM scrape_run_sources l WHERE l.url = f.url ORDER BY l.id DESC LIMIT 1)
+	FROM scrape_run_sources f
+	WHERE f.error != ''
+		AND f.id > COALESCE((SELECT MAX(ok.id) FROM scrape_run_sources ok WHERE ok.url = f.url AND ok.error = ''), 0)
+	GROUP BY f.url
+	HAVING COUNT(*) >= ?
+	ORDER BY COUNT(*) DESC, f.url
+	`
+
+	rows, err := s.db.Query(query, minFailures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failure streaks: %v", err)
+	}
+	defer rows.Close()
+
+	var streaks []FailureStreak
+	for rows.Next() {
+		var streak FailureStreak
+		var since string
+		if err := rows.Scan(&streak.URL, &streak.Failures, &since, &streak.LastError); err != nil {
+			return nil, fmt.Errorf("failed to scan failure streak: %v", err)
+		}
+		if streak.Since, err = parseSQLiteTime(since); err != nil {
+			return nil, err
+		}
+		streaks = append(streaks, streak)
+	}
+
+	return streaks, rows.Err()
+}
+
+// parseSQLiteTime parses a timestamp returned by an aggregate, which the SQLite
+// driver hands back as text because the result column has no declared type.
+// Postgres timestamps arrive as time.Time and are scanned as RFC 3339.
+func parseSQLiteTime(value string) (time.Time, error) {
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999-07:00",
+		"2006-01-02T15:04:05.999999999-07:00",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05",
+	} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse timestamp %q", value)
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScrapeRuns(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	// Three runs: the feed fails in the last two, the page succeeds throughout
+	outcomes := [][2]string{{"", ""}, {"", "status 503"}, {"", "timeout"}}
+	for i, outcome := range outcomes {
+		runID, err := storage.StartScrapeRun("cron")
+		if err != nil {
+			t.Fatalf("Failed to start run: %v", err)
+		}
+
+		now := time.Now()
+		page := ScrapeRunSource{URL: "https://example.com/", StartedAt: now, FinishedAt: now, HTTPStatus: 200,
+			Bytes: 5120, Model: "gemini-1.5-flash", Extracted: 10, Rejected: 1, Saved: 9, Inserted: i, Error: outcome[0]}
+		feed := ScrapeRunSource{URL: "https://example.com/feed", StartedAt: now, FinishedAt: now, HTTPStatus: 503, Error: outcome[1]}
+
+		for _, source := range []ScrapeRunSource{page, feed} {
+			if err := storage.RecordScrapeRunSource(runID, source); err != nil {
+				t.Fatalf("Failed to record run source: %v", err)
+			}
+		}
+		if err := storage.FinishScrapeRun(runID, ""); err != nil {
+			t.Fatalf("Failed to finish run: %v", err)
+		}
+	}
+
+	runs, err := storage.RecentScrapeRuns(2)
+	if err != nil {
+		t.Fatalf("Failed to get recent runs: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID <= runs[1].ID {
+		t.Fatalf("Expected the 2 latest runs newest first, got %+v", runs)
+	}
+	if runs[0].FinishedAt == nil || runs[0].Trigger != "cron" || len(runs[0].Sources) != 2 {
+		t.Fatalf("Unexpected run: %+v", runs[0])
+	}
+	if page := runs[0].Sources[0]; page.Bytes != 5120 || page.Model != "gemini-1.5-flash" || page.Inserted != 2 {
+		t.Errorf("Unexpected source outcome: %+v", page)
+	}
+
+	streaks, err := storage.FailureStreaks(2)
+	if err != nil {
+		t.Fatalf("Failed to get failure streaks: %v", err)
+	}
+	if len(streaks) != 1 {
+		t.Fatalf("Expected one failure streak, got %+v", streaks)
+	}
+	if streaks[0].URL != "https://example.com/feed" || streaks[0].Failures != 2 || streaks[0].LastError != "timeout" || streaks[0].Since.IsZero() {
+		t.Errorf("Unexpected failure streak: %+v", streaks[0])
+	}
+
+	// A success ends the streak
+	runID, _ := storage.StartScrapeRun("manual")
+	if err := storage.RecordScrapeRunSource(runID, ScrapeRunSource{URL: "https://example.com/feed", StartedAt: time.Now(), FinishedAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to record run source: %v", err)
+	}
+	streaks, err = storage.FailureStreaks(1)
+	if err != nil {
+		t.Fatalf("Failed to get failure streaks: %v", err)
+	}
+	if len(streaks) != 0 {
+		t.Errorf("Expected no failure streaks after a success, got %+v", streaks)
+	}
+}
@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+// SchemaDifference is a way the live schema differs from the one the
+// migrations create
+type SchemaDifference struct {
+	// Object is the table, index or trigger, e.g. "table content"
+	Object string `json:"object"`
+	Detail string `json:"detail"`
+}
+
+func (d SchemaDifference) String() string {
+	return d.Object + ": " + d.Detail
+}
+
+// schemaColumn is a column as reported by PRAGMA table_info
+type schemaColumn struct {
+	Type       string
+	NotNull    bool
+	Default    sql.NullString
+	PrimaryKey int
+}
+
+func (c schemaColumn) String() string {
+	s := c.Type
+	if c.NotNull {
+		s += " NOT NULL"
+	}
+	if c.Default.Valid {
+		s += " DEFAULT " + c.Default.String
+	}
+	if c.PrimaryKey > 0 {
+		s += " PRIMARY KEY"
+	}
+	return s
+}
+
+// schemaIndex is an index as reported by PRAGMA index_list and index_info
+type schemaIndex struct {
+	Table   string
+	Unique  bool
+	Columns string
+}
+
+// sqliteSchema describes the tables, indexes and triggers of a SQLite database
+type sqliteSchema struct {
+	version  int64
+	tables   map[string]map[string]schemaColumn
+	indexes  map[string]schemaIndex
+	triggers map[string]string
+}
+
+// VerifySchema compares the schema of the database with the one the
+// embedded migrations create and returns the differences. The database is
+// opened read-only and not migrated, so Initialize need not be called. The
+// full-text index is only compared when the binary was built with FTS5.
+func (s *SQLiteStorage) VerifySchema() ([]SchemaDifference, error) {
+	return verifySchema(s.dbPath)
+}
+
+func verifySchema(path string) ([]SchemaDifference, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	fullText, err := fts5Available(context.Background(), db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for FTS5: %v", err)
+	}
+
+	live, err := readSQLiteSchema(db, fullText)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := expectedSQLiteSchema(fullText)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareSchemas(expected, live), nil
+}
+
+// expectedSQLiteSchema applies every migration to an empty in-memory database
+func expectedSQLiteSchema(fullText bool) (sqliteSchema, error) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return sqliteSchema{}, fmt.Errorf("failed to open scratch database: %v", err)
+	}
+	defer db.Close()
+	// Every connection to :memory: is a database of its own
+	db.SetMaxOpenConns(1)
+
+	migrations, err := fs.Sub(embedMigrations, "migrations")
+	if err != nil {
+		return sqliteSchema{}, err
+	}
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, migrations)
+	if err != nil {
+		return sqliteSchema{}, fmt.Errorf("failed to initialize migrations: %v", err)
+	}
+	if _, err := provider.Up(context.Background()); err != nil {
+		return sqliteSchema{}, fmt.Errorf("failed to apply migrations to scratch database: %v", err)
+	}
+
+	return readSQLiteSchema(db, fullText)
+}
+
+func readSQLiteSchema(db *sql.DB, fullText bool) (sqliteSchema, error) {
+	schema := sqliteSchema{
+		tables:   make(map[string]map[string]schemaColumn),
+		indexes:  make(map[string]schemaIndex),
+		triggers: make(map[string]string),
+	}
+
+	// A database that was never migrated has no version table
+	err := db.QueryRow(`SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied`).Scan(&schema.version)
+	if err != nil && !strings.Contains(err.Error(), "no such table") {
+		return schema, fmt.Errorf("failed to read schema version: %v", err)
+	}
+
+	rows, err := db.Query(`
+	SELECT type, name, tbl_name, COALESCE(sql, '') FROM sqlite_master
+	WHERE type IN ('table', 'index', 'trigger') AND name NOT LIKE 'sqlite_%'
+	ORDER BY name
+	`)
+	if err != nil {
+		return schema, fmt.Errorf("failed to read schema: %v", err)
+	}
+	type object struct{ kind, name, table, sql string }
+	var objects []object
+	for rows.Next() {
+		var o object
+		if err := rows.Scan(&o.kind, &o.name, &o.table, &o.sql); err != nil {
+			rows.Close()
+			return schema, fmt.Errorf("failed to scan schema: %v", err)
+		}
+		// Without FTS5 the index cannot even be inspected
+		if !fullText && strings.HasPrefix(o.table, "content_fts") {
+			continue
+		}
+		objects = append(objects, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return schema, err
+	}
+
+	for _, o := range objects {
+		switch o.kind {
+		case "table":
+			if schema.tables[o.name], err = readColumns(db, o.name); err != nil {
+				return schema, err
+			}
+		case "index":
+			if schema.indexes[o.name], err = readIndex(db, o.table, o.name); err != nil {
+				return schema, err
+			}
+		case "trigger":
+			schema.triggers[o.name] = normalizeSQL(o.sql)
+		}
+	}
+	return schema, nil
+}
+
+func readColumns(db *sql.DB, table string) (map[string]schemaColumn, error) {
+	rows, err := db.Query(`SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]schemaColumn)
+	for rows.Next() {
+		var name string
+		var column schemaColumn
+		if err := rows.Scan(&name, &column.Type, &column.NotNull, &column.Default, &column.PrimaryKey); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %v", table, err)
+		}
+		column.Type = strings.ToUpper(column.Type)
+		columns[name] = column
+	}
+	return columns, rows.Err()
+}
+
+func readIndex(db *sql.DB, table, name string) (schemaIndex, error) {
+	index := schemaIndex{Table: table}
+	err := db.QueryRow(`SELECT "unique" FROM pragma_index_list(?) WHERE name = ?`, table, name).Scan(&index.Unique)
+	if err != nil {
+		return index, fmt.Errorf("failed to read index %s: %v", name, err)
+	}
+
+	rows, err := db.Query(`SELECT COALESCE(name, '<expr>') FROM pragma_index_info(?) ORDER BY seqno`, name)
+	if err != nil {
+		return index, fmt.Errorf("failed to read columns of index %s: %v", name, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return index, fmt.Errorf("failed to scan column of index %s: %v", name, err)
+		}
+		columns = append(columns, column)
+	}
+	index.Columns = strings.Join(columns, ", ")
+	return index, rows.Err()
+}
+
+var sqlSpace = regexp.MustCompile(`\s+`)
+
+// normalizeSQL ignores differences in whitespace, case and quoting
+func normalizeSQL(statement string) string {
+	statement = strings.NewReplacer(`"`, "", "`", "", "IF NOT EXISTS ", "", "if not exists ", "").Replace(statement)
+	return strings.ToLower(strings.TrimSpace(sqlSpace.ReplaceAllString(statement, " ")))
+}
+
+func compareSchemas(expected, live sqliteSchema) []SchemaDifference {
+	var differences []SchemaDifference
+	add := func(object, format string, args ...any) {
+		differences = append(differences, SchemaDifference{Object: object, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if live.version != expected.version {
+		add("version", "database is at %d, migrations go up to %d", live.version, expected.version)
+	}
+
+	for _, name := range unionKeys(expected.tables, live.tables) {
+		object := "table " + name
+		want, inExpected := expected.tables[name]
+		got, inLive := live.tables[name]
+		switch {
+		case !inLive:
+			add(object, "missing")
+		case !inExpected:
+			add(object, "not created by the migrations")
+		default:
+			for _, column := range unionKeys(want, got) {
+				wantColumn, inExpected := want[column]
+				gotColumn, inLive := got[column]
+				switch {
+				case !inLive:
+					add(object, "missing column %s", column)
+				case !inExpected:
+					add(object, "unexpected column %s", column)
+				case wantColumn != gotColumn:
+					add(object, "column %s is %s, expected %s", column, gotColumn, wantColumn)
+				}
+			}
+		}
+	}
+
+	for _, name := range unionKeys(expected.indexes, live.indexes) {
+		object := "index " + name
+		want, inExpected := expected.indexes[name]
+		got, inLive := live.indexes[name]
+		switch {
+		case !inLive:
+			add(object, "missing")
+		case !inExpected:
+			add(object, "not created by the migrations")
+		case want != got:
+			add(object, "is on %s(%s) unique=%t, expected %s(%s) unique=%t",
+				got.Table, got.Columns, got.Unique, want.Table, want.Columns, want.Unique)
+		}
+	}
+
+	for _, name := range unionKeys(expected.triggers, live.triggers) {
+		object := "trigger " + name
+		want, inExpected := expected.triggers[name]
+		got, inLive := live.triggers[name]
+		switch {
+		case !inLive:
+			add(object, "missing")
+		case !inExpected:
+			add(object, "not created by the migrations")
+		case want != got:
+			add(object, "definition differs")
+		}
+	}
+
+	return differences
+}
+
+// unionKeys returns the keys of both maps in order
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
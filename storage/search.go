@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Snippets mark matched terms with these delimiters
+const (
+	HighlightStart = "["
+	HighlightEnd   = "]"
+)
+
+// SearchResult is a content item matched by a full-text search.
+// Higher ranks are better matches; Snippet shows the matched text with
+// the matching terms highlighted.
+type SearchResult struct {
+	Content
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// ensureSearchIndex creates the full-text index if FTS5 is available but the
+// index or its triggers are missing, e.g. when the migration ran on a build
+// without FTS5 or a table rebuild dropped the triggers
+func (s *SQLiteStorage) ensureSearchIndex() error {
+	ctx := context.Background()
+
+	available, err := fts5Available(ctx, s.db)
+	if err != nil {
+		return fmt.Errorf("failed to check for FTS5: %v", err)
+	}
+	if !available {
+		s.fullText = false
+		return nil
+	}
+
+	// The table and its three triggers
+	var objects int
+	err = s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name IN
+		('content_fts', 'content_fts_insert', 'content_fts_delete', 'content_fts_update')`).Scan(&objects)
+	if err != nil {
+		return fmt.Errorf("failed to check for search index: %v", err)
+	}
+	if objects < 4 {
+		log.Println("Creating full-text search index")
+		if err := createContentFTS(ctx, s.db); err != nil {
+			return fmt.Errorf("failed to create search index: %v", err)
+		}
+	}
+
+	s.fullText = true
+	return nil
+}
+
+// SearchText searches titles, extra info and categories. Every term must
+// match; a trailing * makes a term a prefix ("spider*"). Matching ignores
+// case, and on SQLite diacritics. Without FTS5 it falls back to substring
+// matching, with no ranking or snippets.
+func (s *sqlStore) SearchText(query string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	if !s.fullText {
+		return s.searchLike(terms, limit)
+	}
+	if s.dialect == dialectPostgres {
+		return s.searchTSVector(terms, limit)
+	}
+
+	rows, err := s.db.Query(`
+	SELECT `+contentColumns+`,
+		-bm25(content_fts, 10.0, 1.0, 2.0),
+		snippet(content_fts, -1, ?, ?, '…', 12)
+	FROM content_fts
+	JOIN content c ON c.id = content_fts.rowid
+	WHERE content_fts MATCH ?
+	ORDER BY bm25(content_fts, 10.0, 1.0, 2.0)
+	LIMIT ?
+	`, HighlightStart, HighlightEnd, ftsQuery(terms), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search content: %v", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		result.Content, err = scanContent(rows, &result.Rank, &result.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// searchTSVector is the Postgres full-text search over the content's search_vector column
+func (s *sqlStore) searchTSVector(terms []string, limit int) ([]SearchResult, error) {
+	headline := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=12, MinWords=3`, HighlightStart, HighlightEnd)
+
+	rows, err := s.db.Query(`
+	SELECT `+contentColumns+`,
+		ts_rank(c.search_vector, q.query),
+		ts_headline('simple', concat_ws(' ', c.title, c.extra_info, c.category), q.query, ?)
+	FROM content c, to_tsquery('simple', ?) AS q(query)
+	WHERE c.search_vector @@ q.query
+	ORDER BY ts_rank(c.search_vector, q.query) DESC, c.id
+	LIMIT ?
+	`, headline, tsQuery(terms, ""), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search content: %v", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		result.Content, err = scanContent(rows, &result.Rank, &result.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// searchLike is the substring search used when FTS5 is not available
+func (s *sqlStore) searchLike(terms []string, limit int) ([]SearchResult, error) {
+	var conditions []string
+	var args []any
+	for _, term := range terms {
+		conditions = append(conditions, `(c.title LIKE ? OR c.extra_info LIKE ? OR c.category LIKE ?)`)
+		pattern := "%" + strings.TrimSuffix(term, "*") + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(`
+	SELECT `+contentColumns+`
+	FROM content c
+	WHERE `+strings.Join(conditions, " AND ")+`
+	ORDER BY c.created_at DESC, c.id DESC
+	LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search content: %v", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		result.Content, err = scanContent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// searchTerms splits a query into terms, dropping quotes
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		prefix := strings.HasSuffix(field, "*")
+		term := strings.Trim(field, `*"`)
+		if term == "" {
+			continue
+		}
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// ftsQuery quotes each term so user input cannot use FTS5 query syntax,
+// keeping the prefix marker outside the quotes
+func ftsQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		prefix := strings.HasSuffix(term, "*")
+		quoted[i] = `"` + strings.ReplaceAll(strings.TrimSuffix(term, "*"), `"`, `""`) + `"`
+		if prefix {
+			quoted[i] += "*"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// tsQuery is the Postgres counterpart of ftsQuery: every term is quoted and
+// must match. Weights restricts matches to the given labels, e.g. "A" for titles.
+func tsQuery(terms []string, weights string) string {
+	escape := strings.NewReplacer(`\`, `\\`, `'`, `''`)
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		prefix := strings.HasSuffix(term, "*")
+		quoted[i] = `'` + escape.Replace(strings.TrimSuffix(term, "*")) + `'`
+		if prefix || weights != "" {
+			quoted[i] += ":"
+			if prefix {
+				quoted[i] += "*"
+			}
+			quoted[i] += weights
+		}
+	}
+	return strings.Join(quoted, " & ")
+}
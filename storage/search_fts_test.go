@@ -0,0 +1,80 @@
+//go:build sqlite_fts5
+
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchTextFTS(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	if !storage.FullTextEnabled() {
+		t.Fatal("Expected full-text search to be enabled with the sqlite_fts5 tag")
+	}
+
+	_, err := storage.SaveContents([]Content{
+		{Title: "The Dark Knight", Category: "Hollywood", ExtraInfo: "Download Hollywood Movie", Type: "movie"},
+		{Title: "Dark", Category: "Foreign", ExtraInfo: "Season 3 Complete", Type: "series"},
+		{Title: "Knight and Day", Category: "Hollywood", ExtraInfo: "A dark comedy", Type: "movie"},
+		{Title: "Amélie", Category: "Foreign", ExtraInfo: "French classic", Type: "movie"},
+		{Title: "Spider-Man: No Way Home", Category: "Hollywood", ExtraInfo: "", Type: "movie"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	// Title matches rank above extra info matches
+	results, err := storage.SearchText("dark", 10)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 3 || results[2].Title != "Knight and Day" {
+		t.Fatalf("Expected the extra info match to rank last, got %+v", results)
+	}
+	if results[0].Rank < results[2].Rank {
+		t.Errorf("Expected ranks to decrease, got %f then %f", results[0].Rank, results[2].Rank)
+	}
+
+	// Snippets highlight the matched term
+	if !strings.Contains(results[2].Snippet, HighlightStart+"dark"+HighlightEnd) {
+		t.Errorf("Expected highlighted snippet, got %q", results[2].Snippet)
+	}
+
+	// Diacritics and case are folded
+	results, err = storage.SearchText("AMELIE", 10)
+	if err != nil || len(results) != 1 || results[0].Title != "Amélie" {
+		t.Errorf("Expected diacritic-insensitive match for Amélie, got %+v, %v", results, err)
+	}
+
+	// Prefix queries
+	results, err = storage.SearchText("spid*", 10)
+	if err != nil || len(results) != 1 {
+		t.Errorf("Expected prefix match for Spider-Man, got %+v, %v", results, err)
+	}
+
+	// Updates are picked up by the triggers
+	_, err = storage.SaveContents([]Content{{Title: "Dark", Category: "Foreign", ExtraInfo: "Finale special", Type: "series"}})
+	if err != nil {
+		t.Fatalf("Failed to update content: %v", err)
+	}
+	results, err = storage.SearchText("finale", 10)
+	if err != nil || len(results) != 1 {
+		t.Errorf("Expected updated extra info to be indexed, got %+v, %v", results, err)
+	}
+	results, err = storage.SearchText("complete", 10)
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected old extra info to be removed from the index, got %+v, %v", results, err)
+	}
+
+	// SearchContent matches title word prefixes
+	contents, err := storage.SearchContent("kni")
+	if err != nil || len(contents) != 2 {
+		t.Errorf("Expected 2 title matches for kni, got %+v, %v", contents, err)
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import "testing"
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"dark knight", `"dark" "knight"`},
+		{"spider*", `"spider"*`},
+		{`"quoted" say"hi`, `"quoted" "say""hi"`},
+		{"title:boys OR NOT", `"title:boys" "OR" "NOT"`},
+		{"  *  ", ``},
+	}
+
+	for _, test := range tests {
+		if got := ftsQuery(searchTerms(test.input)); got != test.expected {
+			t.Errorf("ftsQuery(%q) = %s, expected %s", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	_, err := storage.SaveContents([]Content{
+		{Title: "The Dark Knight", Category: "Hollywood", ExtraInfo: "Download Hollywood Movie", Type: "movie"},
+		{Title: "Dark", Category: "Foreign", ExtraInfo: "Season 3 Complete", Type: "series"},
+		{Title: "Amélie", Category: "Foreign", ExtraInfo: "French classic", Type: "movie"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+
+	// Both the index and the fallback match every term across fields
+	results, err := storage.SearchText("dark complete", 10)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Dark" {
+		t.Fatalf("Expected only Dark to match, got %+v", results)
+	}
+
+	results, err = storage.SearchText("", 10)
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected no results for an empty query, got %+v, %v", results, err)
+	}
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"cine-pulse/scraper"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+)
+
+// DefaultSourceURL is the source that used to be hardcoded as the default
+const DefaultSourceURL = "https://nkiri.com/"
+
+// Source is a page in the sources registry.
+// An empty Schedule means the source is scraped by the default schedule;
+// an empty ExtractionProfile means the default extraction prompt.
+type Source struct {
+	ID                  int64      `json:"id"`
+	URL                 string     `json:"url"`
+	Name                string     `json:"name"`
+	Type                string     `json:"type"`
+	Enabled             bool       `json:"enabled"`
+	Schedule            string     `json:"schedule,omitempty"`
+	ExtractionProfile   string     `json:"extraction_profile,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+const sourceColumns = `id, url, name, type, enabled, schedule, extraction_profile,
+	last_success_at, last_failure_at, last_error, consecutive_failures`
+
+// ListSources returns every registered source ordered by id
+func (s *sqlStore) ListSources() ([]Source, error) {
+	rows, err := s.db.Query(`SELECT ` + sourceColumns + ` FROM sources ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sources: %v", err)
+	}
+	defer rows.Close()
+
+	var sources []Source
+	for rows.Next() {
+		source, err := scanSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, *source)
+	}
+
+	return sources, rows.Err()
+}
+
+// GetSource returns the source with the given id, or nil if there is none
+func (s *sqlStore) GetSource(id int64) (*Source, error) {
+	return s.getSource(`id = ?`, id)
+}
+
+// GetSourceByURL returns the source registered for a URL, or nil if there is none
+func (s *sqlStore) GetSourceByURL(sourceURL string) (*Source, error) {
+	return s.getSource(`url = ?`, sourceURL)
+}
+
+func (s *sqlStore) getSource(where string, arg any) (*Source, error) {
+	source, err := scanSource(s.db.QueryRow(`SELECT `+sourceColumns+` FROM sources WHERE `+where, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return source, err
+}
+
+// AddSource registers a new source and returns its id.
+// The name defaults to the URL's host and the type to html.
+func (s *sqlStore) AddSource(source Source) (int64, error) {
+	if err := normalizeSource(&source); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := s.db.QueryRow(`INSERT INTO sources (url, name, type, enabled, schedule, extraction_profile)
+		VALUES (?, ?, ?, ?, ?, ?) RETURNING id`,
+		source.URL, source.Name, source.Type, source.Enabled, source.Schedule, source.ExtractionProfile).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add source: %v", err)
+	}
+
+	return id, nil
+}
+
+// UpdateSource saves the settings of an existing source. Health fields are left untouched.
+func (s *sqlStore) UpdateSource(source Source) error {
+	if err := normalizeSource(&source); err != nil {
+		return err
+	}
+
+	res, err := s.db.Exec(`UPDATE sources
+		SET url = ?, name = ?, type = ?, enabled = ?, schedule = ?, extraction_profile = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`,
+		source.URL, source.Name, source.Type, source.Enabled, source.Schedule, source.ExtractionProfile, source.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update source: %v", err)
+	}
+
+	return requireRow(res, "source", source.ID)
+}
+
+// DeleteSource removes a source from the registry
+func (s *sqlStore) DeleteSource(id int64) error {
+	res, err := s.db.Exec(`DELETE FROM sources WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete source: %v", err)
+	}
+
+	return requireRow(res, "source", id)
+}
+
+// RecordSourceSuccess marks a successful scrape and resets the failure streak
+func (s *sqlStore) RecordSourceSuccess(id int64) error {
+	_, err := s.db.Exec(`UPDATE sources
+		SET last_success_at = ?, consecutive_failures = 0
+		WHERE id = ?`, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to record source success: %v", err)
+	}
+	return nil
+}
+
+// RecordSourceFailure marks a failed scrape with its error message
+func (s *sqlStore) RecordSourceFailure(id int64, message string) error {
+	_, err := s.db.Exec(`UPDATE sources
+		SET last_failure_at = ?, last_error = ?, consecutive_failures = consecutive_failures + 1
+		WHERE id = ?`, time.Now().UTC(), message, id)
+	if err != nil {
+		return fmt.Errorf("failed to record source failure: %v", err)
+	}
+	return nil
+}
+
+// RegisterDefaultSource adds the default source when the registry is empty,
+// so a deployment that never set SOURCE_URLS keeps scraping it
+func RegisterDefaultSource(store StorageInterface) error {
+	sources, err := store.ListSources()
+	if err != nil {
+		return err
+	}
+	if len(sources) > 0 {
+		return nil
+	}
+
+	if _, err := store.AddSource(Source{URL: DefaultSourceURL, Name: "nkiri", Enabled: true}); err != nil {
+		return err
+	}
+	log.Printf("Registered default source %s", DefaultSourceURL)
+	return nil
+}
+
+// RegisterSourceURLs adds the URLs that are not registered yet to the sources
+// registry. Sources already registered are left as they are.
+func RegisterSourceURLs(store StorageInterface, sourceURLs []string) {
+	for _, sourceURL := range sourceURLs {
+		existing, err := store.GetSourceByURL(sourceURL)
+		if err != nil {
+			log.Printf("Error looking up source %s: %v", sourceURL, err)
+			continue
+		}
+		if existing != nil {
+			continue
+		}
+
+		if _, err := store.AddSource(Source{URL: sourceURL, Enabled: true}); err != nil {
+			log.Printf("Error registering source %s: %v", sourceURL, err)
+			continue
+		}
+		log.Printf("Registered source %s from SOURCE_URLS", sourceURL)
+	}
+}
+
+// normalizeSource checks the URL and type and fills in defaults
+func normalizeSource(source *Source) error {
+	parsed, err := url.Parse(source.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid source URL %q", source.URL)
+	}
+
+	if source.Name == "" {
+		source.Name = parsed.Host
+	}
+
+	if source.Type == "" {
+		source.Type = scraper.SourceTypeHTML
+	}
+	for _, sourceType := range scraper.SourceTypes {
+		if source.Type == sourceType {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown source type %q", source.Type)
+}
+
+// requireRow returns an error when a statement touched no rows
+func requireRow(res sql.Result, what string, id int64) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %v", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s %d not found", what, id)
+	}
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSource(row rowScanner) (*Source, error) {
+	var source Source
+	var lastSuccess, lastFailure sql.NullTime
+	err := row.Scan(&source.ID, &source.URL, &source.Name, &source.Type, &source.Enabled, &source.Schedule,
+		&source.ExtractionProfile, &lastSuccess, &lastFailure, &source.LastError, &source.ConsecutiveFailures)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan source: %v", err)
+	}
+
+	if lastSuccess.Valid {
+		source.LastSuccessAt = &lastSuccess.Time
+	}
+	if lastFailure.Valid {
+		source.LastFailureAt = &lastFailure.Time
+	}
+
+	return &source, nil
+}
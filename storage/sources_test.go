@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"cine-pulse/scraper"
+	"testing"
+)
+
+func TestSources(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	// The migration registers no source
+	sources, err := storage.ListSources()
+	if err != nil {
+		t.Fatalf("Failed to list sources: %v", err)
+	}
+	if len(sources) != 0 {
+		t.Fatalf("Expected no sources, got %+v", sources)
+	}
+
+	id, err := storage.AddSource(Source{URL: "https://example.com/feed.xml", Type: scraper.SourceTypeFeed, Enabled: true, Schedule: "0 0 12 * * *"})
+	if err != nil {
+		t.Fatalf("Failed to add source: %v", err)
+	}
+
+	source, err := storage.GetSource(id)
+	if err != nil || source == nil {
+		t.Fatalf("Failed to get source: %v", err)
+	}
+	if source.Name != "example.com" || source.Type != scraper.SourceTypeFeed || source.Schedule != "0 0 12 * * *" {
+		t.Errorf("Unexpected source: %+v", source)
+	}
+
+	// Invalid settings are rejected
+	if _, err := storage.AddSource(Source{URL: "https://example.com/", Type: "ftp"}); err == nil {
+		t.Error("Expected error for unknown source type")
+	}
+	if _, err := storage.AddSource(Source{URL: "example.com"}); err == nil {
+		t.Error("Expected error for URL without scheme")
+	}
+	if _, err := storage.AddSource(Source{URL: "https://example.com/feed.xml"}); err == nil {
+		t.Error("Expected error for duplicate URL")
+	}
+
+	// Health tracking
+	if err := storage.RecordSourceFailure(id, "timeout"); err != nil {
+		t.Fatalf("Failed to record failure: %v", err)
+	}
+	if err := storage.RecordSourceFailure(id, "status 503"); err != nil {
+		t.Fatalf("Failed to record failure: %v", err)
+	}
+	source, _ = storage.GetSourceByURL("https://example.com/feed.xml")
+	if source.ConsecutiveFailures != 2 || source.LastError != "status 503" || source.LastFailureAt == nil {
+		t.Errorf("Expected two recorded failures, got %+v", source)
+	}
+
+	if err := storage.RecordSourceSuccess(id); err != nil {
+		t.Fatalf("Failed to record success: %v", err)
+	}
+	source, _ = storage.GetSource(id)
+	if source.ConsecutiveFailures != 0 || source.LastSuccessAt == nil {
+		t.Errorf("Expected success to reset the failure streak, got %+v", source)
+	}
+
+	// Updates keep health fields
+	source.Enabled = false
+	if err := storage.UpdateSource(*source); err != nil {
+		t.Fatalf("Failed to update source: %v", err)
+	}
+	source, _ = storage.GetSource(id)
+	if source.Enabled || source.LastSuccessAt == nil {
+		t.Errorf("Expected disabled source with its health kept, got %+v", source)
+	}
+
+	if err := storage.DeleteSource(id); err != nil {
+		t.Fatalf("Failed to delete source: %v", err)
+	}
+	if source, _ := storage.GetSource(id); source != nil {
+		t.Errorf("Expected source to be deleted, got %+v", source)
+	}
+	if err := storage.DeleteSource(id); err == nil {
+		t.Error("Expected error deleting a missing source")
+	}
+}
+
+func TestRegisterSourceURLs(t *testing.T) {
+	sqlite := NewSQLiteStorage(t.TempDir())
+	defer sqlite.Close()
+
+	stores := map[string]StorageInterface{"sqlite": sqlite, "memory": NewMemoryStorage()}
+	for name, store := range stores {
+		if err := store.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize %s storage: %v", name, err)
+		}
+
+		// SOURCE_URLS replaces the default list, so the default is not added
+		RegisterSourceURLs(store, []string{"https://example.com/movies", "https://example.com/movies"})
+		sources, err := store.ListSources()
+		if err != nil {
+			t.Fatalf("Failed to list sources: %v", err)
+		}
+		if len(sources) != 1 || sources[0].URL != "https://example.com/movies" {
+			t.Fatalf("%s: expected only the listed source, got %+v", name, sources)
+		}
+
+		// Without SOURCE_URLS the default is only added to an empty registry
+		if err := RegisterDefaultSource(store); err != nil {
+			t.Fatalf("Failed to register the default source: %v", err)
+		}
+		if sources, _ := store.ListSources(); len(sources) != 1 {
+			t.Fatalf("%s: expected the default to be skipped, got %+v", name, sources)
+		}
+
+		if err := store.DeleteSource(sources[0].ID); err != nil {
+			t.Fatalf("Failed to delete source: %v", err)
+		}
+		if err := RegisterDefaultSource(store); err != nil {
+			t.Fatalf("Failed to register the default source: %v", err)
+		}
+		sources, _ = store.ListSources()
+		if len(sources) != 1 || sources[0].URL != DefaultSourceURL || sources[0].Name != "nkiri" || !sources[0].Enabled {
+			t.Errorf("%s: expected the default source, got %+v", name, sources)
+		}
+	}
+}
@@ -6,27 +6,71 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type SQLiteStorage struct {
-	db       *sql.DB
+	sqlStore
 	dbPath   string
 	dataPath string
 }
 
 type StorageInterface interface {
 	Initialize() error
-	SaveContent(content Content) error
+	SaveContents(contents []Content) ([]SaveResult, error)
+	PreviewSaveContents(contents []Content) ([]SaveResult, error)
 	GetAllContent() ([]Content, error)
 	GetContentByType(contentType string) ([]Content, error)
 	SearchContent(title string) ([]Content, error)
+	SearchText(query string, limit int) ([]SearchResult, error)
+	QueryContent(filter ContentFilter) (ContentPage, error)
+	GetContentByID(id int64) (*Content, error)
+	DeleteContent(id int64) error
+	GetContentHistory(title, contentType string) ([]ContentChange, error)
+	GetContentSources(contentID int64) ([]ContentSource, error)
+	FindDuplicates(options DuplicateOptions) ([]DuplicateCluster, error)
+	MergeContent(keepID int64, duplicateIDs []int64) (*Content, error)
+	GetSourceCoverage() ([]SourceCoverage, error)
+	SaveEpisodes(title, contentType string, r EpisodeRange) ([]Episode, error)
+	GetEpisodes(title, contentType string) ([]Episode, error)
+	LatestEpisode(title, contentType string) (*Episode, error)
+	ListSources() ([]Source, error)
+	GetSource(id int64) (*Source, error)
+	GetSourceByURL(sourceURL string) (*Source, error)
+	AddSource(source Source) (int64, error)
+	UpdateSource(source Source) error
+	DeleteSource(id int64) error
+	RecordSourceSuccess(id int64) error
+	RecordSourceFailure(id int64, message string) error
+	ListWatchlist() ([]WatchlistEntry, error)
+	AddWatchlistEntry(entry WatchlistEntry) (int64, error)
+	DeleteWatchlistEntry(id int64) error
+	RecordWatchlistMatch(id int64) error
+	StartScrapeRun(trigger string) (int64, error)
+	RecordScrapeRunSource(runID int64, source ScrapeRunSource) error
+	FinishScrapeRun(runID int64, runErr string) error
+	RecentScrapeRuns(limit int) ([]ScrapeRun, error)
+	FailureStreaks(minFailures int) ([]FailureStreak, error)
+	SaveEmbedding(content Content, model string, vector []float32) error
+	GetContentWithoutEmbedding(model string, limit int) ([]Content, error)
+	SemanticSearch(model string, query []float32, limit int) ([]ScoredContent, error)
+	ArchiveContent(seenBefore time.Time) ([]Content, error)
+	PurgeContent(seenBefore time.Time) ([]Content, error)
+	GetArchivedContent(limit int) ([]ArchivedContent, error)
+	Optimize() error
+	GetStats() (map[string]int, error)
+	GetDetailedStats(options StatsOptions) (DetailedStats, error)
 	Close() error
 }
 
+// sqliteFileName is the name of the database file in the data directory
+const sqliteFileName = "cine_pulse.db"
+
 func NewSQLiteStorage(dataPath string) *SQLiteStorage {
-	dbPath := filepath.Join(dataPath, "cine_pulse.db")
+	dbPath := filepath.Join(dataPath, sqliteFileName)
 	return &SQLiteStorage{
 		dbPath:   dbPath,
 		dataPath: dataPath,
@@ -40,15 +84,12 @@ func (s *SQLiteStorage) Initialize() error {
 	}
 
 	// Open database connection
-	db, err := sql.Open("sqlite3", s.dbPath)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %v", err)
+	if err := s.open("sqlite3", s.dbPath, dialectSQLite); err != nil {
+		return err
 	}
 
-	s.db = db
-
 	// Initialize and run migrations using Goose
-	migrationManager := NewMigrationManager(s.db)
+	migrationManager := NewMigrationManager(s.db.DB)
 	if err := migrationManager.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize migrations: %v", err)
 	}
@@ -57,56 +98,202 @@ func (s *SQLiteStorage) Initialize() error {
 		return fmt.Errorf("failed to run migrations: %v", err)
 	}
 
+	if err := s.ensureSearchIndex(); err != nil {
+		return err
+	}
+	if err := s.backfillTitleKeys(); err != nil {
+		return err
+	}
+
 	log.Printf("SQLite database initialized at: %s", s.dbPath)
 	return nil
 }
 
-func (s *SQLiteStorage) SaveContent(content Content) error {
-	// First check if this is an existing record
-	var exists bool
-	err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM content WHERE title = ? AND type = ?)`,
-		content.Title, content.Type).Scan(&exists)
+// identityKey is the SQL expression that normalizes a title and type into the
+// content identity key; it takes the title and type as parameters
+const identityKey = `lower(trim(?)) || '|' || lower(trim(?))`
+
+// contentColumns selects a content row aliased as c, in the order scanContent reads it
+const contentColumns = `c.id, c.title, c.year, c.category, c.extra_info, c.type, c.rating, c.source_url,
+	c.scraped_at, c.created_at, c.updated_at, c.last_seen_at`
+
+// scanContent reads a row selected with contentColumns followed by any extra columns
+func scanContent(row rowScanner, extra ...any) (Content, error) {
+	var content Content
+	var scrapedAt, createdAt, updatedAt, lastSeenAt sql.NullTime
+	dest := append([]any{&content.ID, &content.Title, &content.Year, &content.Category, &content.ExtraInfo,
+		&content.Type, &content.Rating, &content.SourceURL, &scrapedAt, &createdAt, &updatedAt, &lastSeenAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return content, err
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to check if content exists: %v", err)
+	if scrapedAt.Valid {
+		content.ScrapedAt = &scrapedAt.Time
+	}
+	if createdAt.Valid {
+		content.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		content.UpdatedAt = &updatedAt.Time
+	}
+	if lastSeenAt.Valid {
+		content.LastSeenAt = &lastSeenAt.Time
 	}
 
-	if exists {
-		// For existing records, only update fields but keep original scraped_at
-		query := `
-		UPDATE content
-		SET year = ?, category = ?, extra_info = ?, rating = ?, source_url = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE title = ? AND type = ?
-		`
+	return content, nil
+}
+
+// scanContents reads every row selected with contentColumns
+func scanContents(rows *sql.Rows) ([]Content, error) {
+	var contents []Content
+	for rows.Next() {
+		content, err := scanContent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan content: %v", err)
+		}
+		contents = append(contents, content)
+	}
+	return contents, rows.Err()
+}
+
+// SaveContents inserts or updates content items in a single transaction and
+// reports for each whether it was inserted, updated or unchanged
+func (s *sqlStore) SaveContents(contents []Content) ([]SaveResult, error) {
+	return s.saveContents(contents, true)
+}
+
+// PreviewSaveContents reports what SaveContents would do without changing
+// anything: the items are saved in a transaction that is rolled back
+func (s *sqlStore) PreviewSaveContents(contents []Content) ([]SaveResult, error) {
+	return s.saveContents(contents, false)
+}
+
+func (s *sqlStore) saveContents(contents []Content, commit bool) ([]SaveResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
 
-		_, err := s.db.Exec(query, content.Year, content.Category, content.ExtraInfo,
-			content.Rating, content.SourceURL, content.Title, content.Type)
+	results := make([]SaveResult, 0, len(contents))
+	for _, content := range contents {
+		result, err := saveContent(tx, content)
 		if err != nil {
-			return fmt.Errorf("failed to update content: %v", err)
+			return nil, fmt.Errorf("failed to save %s: %v", content.Title, err)
 		}
+		results = append(results, result)
+	}
+	if !commit {
+		return results, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit content: %v", err)
+	}
+
+	return results, nil
+}
+
+// saveContent upserts one item. A new title that is another spelling of a
+// stored one, such as "Dune Part 2" for "Dune: Part Two" of the same year, is
+// looked up first and saved onto the stored row. Otherwise the insert runs
+// first so the transaction holds the write lock before the existing row is
+// read and compared.
+func saveContent(tx *sqlTx, content Content) (SaveResult, error) {
+	result := SaveResult{Content: content}
+
+	match, err := findNearDuplicate(tx, content)
+	if err != nil {
+		return result, err
+	}
+
+	var existing Content
+	if match != nil {
+		existing = *match
+		// Episodes and history are looked up by the stored title
+		result.ScrapedTitle = content.Title
+		result.Content.Title = match.Title
 	} else {
-		// For new records, insert everything including scraped_at timestamp
 		query := `
-		INSERT INTO content (title, year, category, extra_info, type, rating, source_url, 
-			scraped_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO content (title, year, category, extra_info, type, rating, source_url, identity_key, title_key,
+			scraped_at, created_at, updated_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ` + identityKey + `, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(identity_key) DO NOTHING
+		RETURNING id
 		`
 
-		_, err := s.db.Exec(query, content.Title, content.Year, content.Category, content.ExtraInfo,
-			content.Type, content.Rating, content.SourceURL)
-		if err != nil {
-			return fmt.Errorf("failed to insert content: %v", err)
+		// No row is returned when the item already exists
+		err := tx.QueryRow(query, content.Title, content.Year, content.Category, content.ExtraInfo,
+			content.Type, content.Rating, content.SourceURL, content.Title, content.Type, titleMatchKey(content.Title)).Scan(&result.Content.ID)
+		switch {
+		case err == nil:
+			result.Outcome = OutcomeInserted
+			return result, recordContentSource(tx, result.Content.ID, content)
+
+		case err == sql.ErrNoRows:
+			// The item already exists, compare it with the stored row
+			query = `SELECT ` + contentColumns + ` FROM content c WHERE c.identity_key = ` + identityKey
+			if tx.dialect == dialectPostgres {
+				// Lock the row so concurrent instances compare against the latest values
+				query += ` FOR UPDATE`
+			}
+			existing, err = scanContent(tx.QueryRow(query, content.Title, content.Type))
+			if err != nil {
+				return result, fmt.Errorf("failed to load existing content: %v", err)
+			}
+
+		default:
+			return result, fmt.Errorf("failed to insert content: %v", err)
 		}
 	}
+	id := existing.ID
+	result.Content.ID = id
 
-	return nil
+	if err := recordContentSource(tx, id, content); err != nil {
+		return result, err
+	}
+
+	result.Changes = diffContent(existing, content)
+	result.Outcome = OutcomeUnchanged
+	if len(result.Changes) == 0 {
+		// Still seen, which keeps the item from expiring
+		if _, err := tx.Exec(`UPDATE content SET last_seen_at = CURRENT_TIMESTAMP WHERE id = ?`, id); err != nil {
+			return result, fmt.Errorf("failed to mark content as seen: %v", err)
+		}
+		return result, nil
+	}
+
+	// For existing records, only update fields but keep original scraped_at
+	_, err = tx.Exec(`
+	UPDATE content
+	SET year = ?, category = ?, extra_info = ?, rating = ?, source_url = ?,
+		updated_at = CURRENT_TIMESTAMP, last_seen_at = CURRENT_TIMESTAMP
+	WHERE id = ?
+	`, content.Year, content.Category, content.ExtraInfo, content.Rating, content.SourceURL, id)
+	if err != nil {
+		return result, fmt.Errorf("failed to update content: %v", err)
+	}
+
+	// Keep the previous values of every field that changed
+	if err := recordChanges(tx, id, result.Changes); err != nil {
+		return result, err
+	}
+
+	for _, change := range result.Changes {
+		if change.Meaningful() {
+			result.Outcome = OutcomeUpdated
+			break
+		}
+	}
+
+	return result, nil
 }
 
-func (s *SQLiteStorage) GetAllContent() ([]Content, error) {
+func (s *sqlStore) GetAllContent() ([]Content, error) {
 	query := `
-	SELECT title, year, category, extra_info, type, rating, source_url
-	FROM content
-	ORDER BY created_at DESC
+	SELECT ` + contentColumns + `
+	FROM content c
+	ORDER BY c.created_at DESC, c.id DESC
 	`
 
 	rows, err := s.db.Query(query)
@@ -115,25 +302,15 @@ func (s *SQLiteStorage) GetAllContent() ([]Content, error) {
 	}
 	defer rows.Close()
 
-	var contents []Content
-	for rows.Next() {
-		var content Content
-		err := rows.Scan(&content.Title, &content.Year, &content.Category, &content.ExtraInfo, &content.Type, &content.Rating, &content.SourceURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan content: %v", err)
-		}
-		contents = append(contents, content)
-	}
-
-	return contents, nil
+	return scanContents(rows)
 }
 
-func (s *SQLiteStorage) GetContentByType(contentType string) ([]Content, error) {
+func (s *sqlStore) GetContentByType(contentType string) ([]Content, error) {
 	query := `
-	SELECT title, year, category, extra_info, type, rating, source_url
-	FROM content
-	WHERE type = ?
-	ORDER BY created_at DESC
+	SELECT ` + contentColumns + `
+	FROM content c
+	WHERE c.type = ?
+	ORDER BY c.created_at DESC, c.id DESC
 	`
 
 	rows, err := s.db.Query(query, contentType)
@@ -142,98 +319,129 @@ func (s *SQLiteStorage) GetContentByType(contentType string) ([]Content, error)
 	}
 	defer rows.Close()
 
-	var contents []Content
-	for rows.Next() {
-		var content Content
-		err := rows.Scan(&content.Title, &content.Year, &content.Category, &content.ExtraInfo, &content.Type, &content.Rating, &content.SourceURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan content: %v", err)
-		}
-		contents = append(contents, content)
-	}
-
-	return contents, nil
+	return scanContents(rows)
 }
 
-func (s *SQLiteStorage) SearchContent(title string) ([]Content, error) {
-	query := `
-	SELECT title, year, category, extra_info, type, rating, source_url
-	FROM content
-	WHERE title LIKE ?
-	ORDER BY created_at DESC
-	`
+// GetContentByID returns the content with the given id, or nil if there is none
+func (s *sqlStore) GetContentByID(id int64) (*Content, error) {
+	content, err := scanContent(s.db.QueryRow(`SELECT `+contentColumns+` FROM content c WHERE c.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content: %v", err)
+	}
+	return &content, nil
+}
 
-	rows, err := s.db.Query(query, "%"+title+"%")
+// DeleteContent removes a content item together with its history, episodes,
+// embeddings and sources. The full-text index is updated by its trigger.
+func (s *sqlStore) DeleteContent(id int64) error {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to search content: %v", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var contents []Content
-	for rows.Next() {
-		var content Content
-		err := rows.Scan(&content.Title, &content.Year, &content.Category, &content.ExtraInfo, &content.Type, &content.Rating, &content.SourceURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan content: %v", err)
-		}
-		contents = append(contents, content)
+	if err := deleteDependents(tx, id); err != nil {
+		return err
 	}
 
-	return contents, nil
-}
+	res, err := tx.Exec(`DELETE FROM content WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete content: %v", err)
+	}
+	if err := requireRow(res, "content", id); err != nil {
+		return err
+	}
 
-func (s *SQLiteStorage) Close() error {
-	if s.db != nil {
-		return s.db.Close()
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit content deletion: %v", err)
 	}
 	return nil
 }
 
-func (s *SQLiteStorage) GetDB() (*sql.DB, error) {
-	if s.db == nil {
-		// Open database connection if not already open
-		db, err := sql.Open("sqlite3", s.dbPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open database: %v", err)
+// dependentTables hold rows that belong to a content item
+var dependentTables = []string{"content_history", "episodes", "content_embeddings", "content_sources"}
+
+// deleteDependents removes an item's rows from dependentTables. Foreign keys
+// are not enforced in SQLite, so they are removed explicitly.
+func deleteDependents(tx *sqlTx, id int64) error {
+	for _, table := range dependentTables {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE content_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete from %s: %v", table, err)
 		}
-		s.db = db
 	}
-	return s.db, nil
+	return nil
 }
 
-func (s *SQLiteStorage) GetStats() (map[string]int, error) {
-	stats := make(map[string]int)
+// SearchContent finds content whose title contains the given words.
+// With FTS5 every word matches as a prefix and results are ranked by relevance;
+// otherwise the title must contain the text.
+func (s *sqlStore) SearchContent(title string) ([]Content, error) {
+	query := `
+	SELECT ` + contentColumns + `
+	FROM content c
+	WHERE c.title LIKE ?
+	ORDER BY c.created_at DESC, c.id DESC
+	`
+	args := []any{"%" + title + "%"}
+
+	terms := searchTerms(title)
+	switch {
+	case !s.fullText || len(terms) == 0:
+		// Substring match
+	case s.dialect == dialectPostgres:
+		// Prefix matches restricted to the title, which is weighted A
+		query = `
+		SELECT ` + contentColumns + `
+		FROM content c, to_tsquery('simple', ?) AS q(query)
+		WHERE c.search_vector @@ q.query
+		ORDER BY ts_rank(c.search_vector, q.query) DESC, c.id
+		`
+		prefixes := make([]string, len(terms))
+		for i, term := range terms {
+			prefixes[i] = strings.TrimSuffix(term, "*") + "*"
+		}
+		args = []any{tsQuery(prefixes, "A")}
+	default:
+		var match []string
+		for _, term := range terms {
+			match = append(match, "title : "+ftsQuery([]string{strings.TrimSuffix(term, "*") + "*"}))
+		}
 
-	// Total content
-	var total int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM content").Scan(&total)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total count: %v", err)
+		query = `
+		SELECT ` + contentColumns + `
+		FROM content_fts
+		JOIN content c ON c.id = content_fts.rowid
+		WHERE content_fts MATCH ?
+		ORDER BY rank
+		`
+		args = []any{strings.Join(match, " ")}
 	}
-	stats["total"] = total
 
-	// Movies count
-	var movies int
-	err = s.db.QueryRow("SELECT COUNT(*) FROM content WHERE type = 'movie'").Scan(&movies)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get movies count: %v", err)
+		return nil, fmt.Errorf("failed to search content: %v", err)
 	}
-	stats["movies"] = movies
+	defer rows.Close()
 
-	// Series count
-	var series int
-	err = s.db.QueryRow("SELECT COUNT(*) FROM content WHERE type = 'series'").Scan(&series)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get series count: %v", err)
-	}
-	stats["series"] = series
+	return scanContents(rows)
+}
 
-	return stats, nil
+func (s *SQLiteStorage) GetDB() (*sql.DB, error) {
+	if s.db == nil {
+		// Open database connection if not already open
+		if err := s.open("sqlite3", s.dbPath, dialectSQLite); err != nil {
+			return nil, err
+		}
+	}
+	return s.db.DB, nil
 }
 
 // Migration management methods
 func (s *SQLiteStorage) GetMigrationManager() *MigrationManager {
-	return NewMigrationManager(s.db)
+	return NewMigrationManager(s.db.DB)
 }
 
 func (s *SQLiteStorage) GetDatabaseVersion() (int64, error) {
@@ -252,6 +460,15 @@ func (s *SQLiteStorage) RunMigrations() error {
 	return migrationManager.Up()
 }
 
+// MigrationStatus logs every migration and when it was applied
+func (s *SQLiteStorage) MigrationStatus() error {
+	migrationManager := s.GetMigrationManager()
+	if err := migrationManager.Initialize(); err != nil {
+		return err
+	}
+	return migrationManager.Status()
+}
+
 func (s *SQLiteStorage) RollbackMigration() error {
 	migrationManager := s.GetMigrationManager()
 	if err := migrationManager.Initialize(); err != nil {
@@ -267,3 +484,20 @@ func (s *SQLiteStorage) ResetDatabase() error {
 	}
 	return migrationManager.Reset()
 }
+
+// DryRunMigrations reports what the pending migrations would do without
+// changing the database. Like VerifySchema it opens the database read-only,
+// so Initialize, which migrates, need not be called.
+func (s *SQLiteStorage) DryRunMigrations() ([]MigrationResult, error) {
+	db, err := sql.Open("sqlite3", "file:"+s.dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	migrationManager := NewMigrationManager(db)
+	if err := migrationManager.Initialize(); err != nil {
+		return nil, err
+	}
+	return migrationManager.DryRun()
+}
@@ -27,7 +27,7 @@ func TestSQLiteStorage(t *testing.T) {
 		Type:      "movie",
 	}
 
-	err = storage.SaveContent(testContent)
+	_, err = storage.SaveContents([]Content{testContent})
 	if err != nil {
 		t.Fatalf("Failed to save content: %v", err)
 	}
@@ -101,3 +101,92 @@ func TestSQLiteStorageInit(t *testing.T) {
 		t.Fatalf("Database file was not created")
 	}
 }
+
+func TestContentIDsAndTimestamps(t *testing.T) {
+	storage := NewSQLiteStorage(t.TempDir())
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer storage.Close()
+
+	results, err := storage.SaveContents([]Content{
+		{Title: "The Boys", Category: "TV Series", ExtraInfo: "Episode 5", Type: "series"},
+		{Title: "Civil War", Category: "Hollywood", Type: "movie"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	if results[0].Content.ID == 0 || results[1].Content.ID == 0 {
+		t.Fatalf("Expected saved items to carry their ids, got %+v", results)
+	}
+
+	// Unchanged items report the id of the stored row
+	again, err := storage.SaveContents([]Content{{Title: "the boys", Category: "TV Series", ExtraInfo: "Episode 5", Type: "series"}})
+	if err != nil {
+		t.Fatalf("Failed to save content: %v", err)
+	}
+	id := results[0].Content.ID
+	if again[0].Content.ID != id {
+		t.Errorf("Expected id %d for existing item, got %d", id, again[0].Content.ID)
+	}
+
+	content, err := storage.GetContentByID(id)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	if content == nil || content.Title != "The Boys" {
+		t.Fatalf("Expected The Boys, got %+v", content)
+	}
+	if content.ScrapedAt == nil || content.CreatedAt == nil || content.UpdatedAt == nil {
+		t.Errorf("Expected timestamps to be loaded, got %+v", content)
+	}
+
+	all, err := storage.GetAllContent()
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	for _, item := range all {
+		if item.ID == 0 || item.CreatedAt == nil {
+			t.Errorf("Expected id and timestamps on %s", item.Title)
+		}
+	}
+
+	// Deleting removes the item and everything attached to it
+	if _, err := storage.SaveEpisodes("The Boys", "series", EpisodeRange{Season: 1, First: 1, Last: 3}); err != nil {
+		t.Fatalf("Failed to save episodes: %v", err)
+	}
+	if err := storage.SaveEmbedding(*content, "test", []float32{1, 0}); err != nil {
+		t.Fatalf("Failed to save embedding: %v", err)
+	}
+	content.ExtraInfo = "Episode 6"
+	if _, err := storage.SaveContents([]Content{*content}); err != nil {
+		t.Fatalf("Failed to update content: %v", err)
+	}
+
+	if err := storage.DeleteContent(id); err != nil {
+		t.Fatalf("Failed to delete content: %v", err)
+	}
+
+	deleted, err := storage.GetContentByID(id)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	if deleted != nil {
+		t.Errorf("Expected deleted content to be gone, got %+v", deleted)
+	}
+
+	db, _ := storage.GetDB()
+	for _, table := range []string{"content_history", "episodes", "content_embeddings"} {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE content_id = ?`, id).Scan(&count); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("Expected %s rows to be deleted, got %d", table, count)
+		}
+	}
+
+	if err := storage.DeleteContent(id); err == nil {
+		t.Error("Expected error deleting missing content")
+	}
+}
@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Defaults for StatsOptions
+const (
+	defaultStatsDays  = 14
+	defaultStatsWeeks = 8
+	defaultStatsLimit = 10
+)
+
+// StatsOptions selects the periods of DetailedStats; zero values use the defaults
+type StatsOptions struct {
+	// Days is the number of daily counts, 14 by default
+	Days int
+	// Weeks is the number of weekly counts, 8 by default. Episode updates and
+	// source success rates cover the same weeks.
+	Weeks int
+	// Limit caps the top-rated and most-updated lists, 10 by default
+	Limit int
+	// Now ends the periods, the current time by default
+	Now time.Time
+}
+
+// DetailedStats breaks the catalog down by category, source and time.
+// Days and weeks are UTC; weeks start on Monday.
+type DetailedStats struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Total       int       `json:"total"`
+	Movies      int       `json:"movies"`
+	Series      int       `json:"series"`
+
+	// Categories are ordered by count, largest first
+	Categories []CategoryStats `json:"categories"`
+	// Sources are ordered by count, largest first
+	Sources []SourceCount `json:"sources"`
+	// Daily and Weekly count content by when it was first scraped, oldest first
+	Daily  []PeriodCount `json:"daily"`
+	Weekly []PeriodCount `json:"weekly"`
+
+	// TopNew are the highest rated items first scraped this week
+	TopNew []Content `json:"top_new"`
+	// MostUpdatedSeries are the series with the most new episodes in the weekly period
+	MostUpdatedSeries []SeriesUpdates `json:"most_updated_series"`
+	// Extraction reports the scrapes of each source in the weekly period, by URL
+	Extraction []SourceExtraction `json:"extraction"`
+}
+
+// CategoryStats counts the content of a category. AverageRating is nil when
+// none of it is rated.
+type CategoryStats struct {
+	Category      string   `json:"category"`
+	Count         int      `json:"count"`
+	Rated         int      `json:"rated"`
+	AverageRating *float64 `json:"average_rating,omitempty"`
+}
+
+// SourceCount is the amount of content last scraped from a source URL.
+// An empty URL counts content without a recorded source.
+type SourceCount struct {
+	SourceURL string `json:"source_url"`
+	Count     int    `json:"count"`
+}
+
+// PeriodCount is the number of items first scraped in the period starting at Start
+type PeriodCount struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// SeriesUpdates is a series and the number of its episodes first seen in a period
+type SeriesUpdates struct {
+	Content
+	NewEpisodes int `json:"new_episodes"`
+}
+
+// SourceExtraction sums the scrapes of a source. SuccessRate is the share of
+// scrapes without an error, AcceptanceRate the share of extracted items that
+// passed validation.
+type SourceExtraction struct {
+	URL            string  `json:"url"`
+	Scrapes        int     `json:"scrapes"`
+	Succeeded      int     `json:"succeeded"`
+	Extracted      int     `json:"extracted"`
+	Rejected       int     `json:"rejected"`
+	Filtered       int     `json:"filtered"`
+	Saved          int     `json:"saved"`
+	SuccessRate    float64 `json:"success_rate"`
+	AcceptanceRate float64 `json:"acceptance_rate"`
+}
+
+func (e *SourceExtraction) computeRates() {
+	if e.Scrapes > 0 {
+		e.SuccessRate = float64(e.Succeeded) / float64(e.Scrapes)
+	}
+	if e.Extracted > 0 {
+		e.AcceptanceRate = float64(e.Extracted-e.Rejected) / float64(e.Extracted)
+	}
+}
+
+// statsPeriods are the boundaries derived from StatsOptions
+type statsPeriods struct {
+	options StatsOptions
+	// firstDay and firstWeek start the oldest daily and weekly counts
+	firstDay  time.Time
+	firstWeek time.Time
+	// thisWeek starts the current week
+	thisWeek time.Time
+}
+
+func newStatsPeriods(options StatsOptions) statsPeriods {
+	if options.Days <= 0 {
+		options.Days = defaultStatsDays
+	}
+	if options.Weeks <= 0 {
+		options.Weeks = defaultStatsWeeks
+	}
+	if options.Limit <= 0 {
+		options.Limit = defaultStatsLimit
+	}
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+	options.Now = options.Now.UTC()
+
+	today := time.Date(options.Now.Year(), options.Now.Month(), options.Now.Day(), 0, 0, 0, 0, time.UTC)
+	// Monday is 0 days into the week, Sunday 6
+	thisWeek := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
+	return statsPeriods{
+		options:   options,
+		firstDay:  today.AddDate(0, 0, -(options.Days - 1)),
+		firstWeek: thisWeek.AddDate(0, 0, -7*(options.Weeks-1)),
+		thisWeek:  thisWeek,
+	}
+}
+
+// since is the start of the oldest period
+func (p statsPeriods) since() time.Time {
+	if p.firstDay.Before(p.firstWeek) {
+		return p.firstDay
+	}
+	return p.firstWeek
+}
+
+// count fills the daily and weekly counts of the stats from scrape times
+func (p statsPeriods) count(stats *DetailedStats, scrapedAt []time.Time) {
+	stats.Daily = periodCounts(p.firstDay, p.options.Days, 24*time.Hour, scrapedAt)
+	stats.Weekly = periodCounts(p.firstWeek, p.options.Weeks, 7*24*time.Hour, scrapedAt)
+}
+
+func periodCounts(first time.Time, n int, length time.Duration, times []time.Time) []PeriodCount {
+	counts := make([]PeriodCount, n)
+	for i := range counts {
+		counts[i].Start = first.Add(time.Duration(i) * length)
+	}
+	for _, t := range times {
+		if t.Before(first) {
+			continue
+		}
+		if i := int(t.Sub(first) / length); i < n {
+			counts[i].Count++
+		}
+	}
+	return counts
+}
+
+// topNewFilter selects the highest rated items first scraped this week
+func (p statsPeriods) topNewFilter() ContentFilter {
+	minRating := 0.0
+	return ContentFilter{
+		ScrapedAfter: &p.thisWeek,
+		MinRating:    &minRating,
+		SortBy:       SortRating,
+		Descending:   true,
+		Limit:        p.options.Limit,
+	}
+}
+
+// GetDetailedStats returns counts per category, source and period, the top
+// rated new items, the most updated series and extraction rates per source
+func (s *sqlStore) GetDetailedStats(options StatsOptions) (DetailedStats, error) {
+	periods := newStatsPeriods(options)
+	stats := DetailedStats{GeneratedAt: periods.options.Now}
+
+	counts, err := s.GetStats()
+	if err != nil {
+		return stats, err
+	}
+	stats.Total, stats.Movies, stats.Series = counts["total"], counts["movies"], counts["series"]
+
+	if stats.Categories, err = s.categoryStats(); err != nil {
+		return stats, err
+	}
+	if stats.Sources, err = s.sourceCounts(); err != nil {
+		return stats, err
+	}
+
+	scrapedAt, err := s.scrapeTimes(periods.since())
+	if err != nil {
+		return stats, err
+	}
+	periods.count(&stats, scrapedAt)
+
+	page, err := s.QueryContent(periods.topNewFilter())
+	if err != nil {
+		return stats, err
+	}
+	stats.TopNew = page.Items
+
+	if stats.MostUpdatedSeries, err = s.mostUpdatedSeries(periods.firstWeek, periods.options.Limit); err != nil {
+		return stats, err
+	}
+	if stats.Extraction, err = s.extractionStats(periods.firstWeek); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+func (s *sqlStore) categoryStats() ([]CategoryStats, error) {
+	rows, err := s.db.Query(`
+	SELECT category, COUNT(*), COUNT(rating), AVG(rating)
+	FROM content
+	GROUP BY category
+	ORDER BY COUNT(*) DESC, category
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count categories: %v", err)
+	}
+	defer rows.Close()
+
+	var categories []CategoryStats
+	for rows.Next() {
+		var category CategoryStats
+		var average sql.NullFloat64
+		if err := rows.Scan(&category.Category, &category.Count, &category.Rated, &average); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+		if average.Valid {
+			category.AverageRating = &average.Float64
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+func (s *sqlStore) sourceCounts() ([]SourceCount, error) {
+	rows, err := s.db.Query(`
+	SELECT COALESCE(source_url, ''), COUNT(*)
+	FROM content
+	GROUP BY COALESCE(source_url, '')
+	ORDER BY COUNT(*) DESC, COALESCE(source_url, '')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sources: %v", err)
+	}
+	defer rows.Close()
+
+	var sources []SourceCount
+	for rows.Next() {
+		var source SourceCount
+		if err := rows.Scan(&source.SourceURL, &source.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan source count: %v", err)
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
+
+// scrapeTimes returns when the items first scraped since the given time were scraped
+func (s *sqlStore) scrapeTimes(since time.Time) ([]time.Time, error) {
+	rows, err := s.db.Query(`SELECT c.scraped_at FROM content c WHERE c.scraped_at >= ?`, s.dialect.timeArg(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape times: %v", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var scrapedAt sql.NullTime
+		if err := rows.Scan(&scrapedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scrape time: %v", err)
+		}
+		if scrapedAt.Valid {
+			times = append(times, scrapedAt.Time)
+		}
+	}
+	return times, rows.Err()
+}
+
+func (s *sqlStore) mostUpdatedSeries(since time.Time, limit int) ([]SeriesUpdates, error) {
+	rows, err := s.db.Query(`
+	SELECT `+contentColumns+`, COUNT(*) AS new_episodes
+	FROM episodes e
+	JOIN content c ON c.id = e.content_id
+	WHERE e.first_seen_at >= ?
+	GROUP BY c.id
+	ORDER BY new_episodes DESC, lower(c.title), c.id
+	LIMIT ?
+	`, s.dialect.timeArg(since), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episode updates: %v", err)
+	}
+	defer rows.Close()
+
+	var series []SeriesUpdates
+	for rows.Next() {
+		var updates SeriesUpdates
+		if updates.Content, err = scanContent(rows, &updates.NewEpisodes); err != nil {
+			return nil, fmt.Errorf("failed to scan episode updates: %v", err)
+		}
+		series = append(series, updates)
+	}
+	return series, rows.Err()
+}
+
+func (s *sqlStore) extractionStats(since time.Time) ([]SourceExtraction, error) {
+	rows, err := s.db.Query(`
+	SELECT url, COUNT(*), SUM(CASE WHEN error = '' THEN 1 ELSE 0 END),
+		SUM(extracted), SUM(rejected), SUM(filtered), SUM(saved)
+	FROM scrape_run_sources
+	WHERE started_at >= ?
+	GROUP BY url
+	ORDER BY url
+	`, s.dialect.timeArg(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extraction stats: %v", err)
+	}
+	defer rows.Close()
+
+	var sources []SourceExtraction
+	for rows.Next() {
+		var e SourceExtraction
+		if err := rows.Scan(&e.URL, &e.Scrapes, &e.Succeeded, &e.Extracted, &e.Rejected, &e.Filtered, &e.Saved); err != nil {
+			return nil, fmt.Errorf("failed to scan extraction stats: %v", err)
+		}
+		e.computeRates()
+		sources = append(sources, e)
+	}
+	return sources, rows.Err()
+}
+
+// sortStats orders the lists of stats computed in Go like the SQL queries do
+func sortStats(stats *DetailedStats) {
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		a, b := stats.Categories[i], stats.Categories[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Category < b.Category
+	})
+	sort.Slice(stats.Sources, func(i, j int) bool {
+		a, b := stats.Sources[i], stats.Sources[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.SourceURL < b.SourceURL
+	})
+	sort.Slice(stats.MostUpdatedSeries, func(i, j int) bool {
+		a, b := stats.MostUpdatedSeries[i], stats.MostUpdatedSeries[j]
+		if a.NewEpisodes != b.NewEpisodes {
+			return a.NewEpisodes > b.NewEpisodes
+		}
+		if strings.ToLower(a.Title) != strings.ToLower(b.Title) {
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		}
+		return a.ID < b.ID
+	})
+	sort.Slice(stats.Extraction, func(i, j int) bool {
+		return stats.Extraction[i].URL < stats.Extraction[j].URL
+	})
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsPeriods(t *testing.T) {
+	// A Sunday evening; the week started on Monday the 13th
+	now := time.Date(2025, 10, 19, 22, 30, 0, 0, time.UTC)
+	periods := newStatsPeriods(StatsOptions{Days: 7, Weeks: 3, Now: now})
+
+	if !periods.thisWeek.Equal(time.Date(2025, 10, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected start of week %v", periods.thisWeek)
+	}
+	if !periods.firstDay.Equal(time.Date(2025, 10, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first day %v", periods.firstDay)
+	}
+	if !periods.since().Equal(time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected start of the weekly period %v", periods.since())
+	}
+	if periods.options.Limit != defaultStatsLimit {
+		t.Errorf("Expected the default limit, got %d", periods.options.Limit)
+	}
+
+	var stats DetailedStats
+	periods.count(&stats, []time.Time{
+		time.Date(2025, 9, 28, 23, 59, 59, 0, time.UTC), // before every period
+		time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 10, 13, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 10, 19, 23, 0, 0, 0, time.UTC),
+		time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC), // after every period
+	})
+
+	if len(stats.Daily) != 7 || stats.Daily[0].Count != 1 || stats.Daily[6].Count != 1 {
+		t.Errorf("Unexpected daily counts: %+v", stats.Daily)
+	}
+	if len(stats.Weekly) != 3 || stats.Weekly[0].Count != 1 || stats.Weekly[1].Count != 0 || stats.Weekly[2].Count != 2 {
+		t.Errorf("Unexpected weekly counts: %+v", stats.Weekly)
+	}
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialect identifies the SQL flavour of a backend. Queries are written for
+// SQLite with ? placeholders and rebound for other backends.
+type dialect string
+
+const (
+	dialectSQLite   dialect = "sqlite3"
+	dialectPostgres dialect = "postgres"
+)
+
+// rebind rewrites ? placeholders to $1, $2, ... for Postgres.
+// Question marks inside quoted literals are left alone.
+func (d dialect) rebind(query string) string {
+	if d != dialectPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// timeArg converts a time to a query argument that compares correctly with
+// stored timestamps. SQLite stores CURRENT_TIMESTAMP as UTC text.
+func (d dialect) timeArg(t time.Time) any {
+	if d == dialectSQLite {
+		return t.UTC().Format("2006-01-02 15:04:05")
+	}
+	return t
+}
+
+// nullTimeArg is timeArg for optional times
+func (d dialect) nullTimeArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return d.timeArg(*t)
+}
+
+// sqlDB runs queries written with ? placeholders against the backend's database
+type sqlDB struct {
+	*sql.DB
+	dialect dialect
+}
+
+func (db *sqlDB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.Exec(db.dialect.rebind(query), args...)
+}
+
+func (db *sqlDB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.DB.Query(db.dialect.rebind(query), args...)
+}
+
+func (db *sqlDB) QueryRow(query string, args ...any) *sql.Row {
+	return db.DB.QueryRow(db.dialect.rebind(query), args...)
+}
+
+func (db *sqlDB) Begin() (*sqlTx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{Tx: tx, dialect: db.dialect}, nil
+}
+
+// sqlTx is a transaction that rebinds placeholders like sqlDB
+type sqlTx struct {
+	*sql.Tx
+	dialect dialect
+}
+
+func (tx *sqlTx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.Exec(tx.dialect.rebind(query), args...)
+}
+
+func (tx *sqlTx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.dialect.rebind(query), args...)
+}
+
+func (tx *sqlTx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRow(tx.dialect.rebind(query), args...)
+}
+
+// sqlStore implements the storage operations shared by the SQL backends.
+// Backends embed it and add connection setup and migrations.
+type sqlStore struct {
+	db       *sqlDB
+	dialect  dialect
+	fullText bool
+}
+
+func (s *sqlStore) open(driver, dsn string, d dialect) error {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	s.db = &sqlDB{DB: db, dialect: d}
+	s.dialect = d
+	return nil
+}
+
+func (s *sqlStore) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// FullTextEnabled reports whether searches use a full-text index
+func (s *sqlStore) FullTextEnabled() bool {
+	return s.fullText
+}
+
+func (s *sqlStore) GetStats() (map[string]int, error) {
+	stats := make(map[string]int)
+
+	// Total content
+	var total int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM content").Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total count: %v", err)
+	}
+	stats["total"] = total
+
+	// Movies count
+	var movies int
+	err = s.db.QueryRow("SELECT COUNT(*) FROM content WHERE type = 'movie'").Scan(&movies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movies count: %v", err)
+	}
+	stats["movies"] = movies
+
+	// Series count
+	var series int
+	err = s.db.QueryRow("SELECT COUNT(*) FROM content WHERE type = 'series'").Scan(&series)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series count: %v", err)
+	}
+	stats["series"] = series
+
+	return stats, nil
+}
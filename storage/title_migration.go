@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Content stored before scraped items were validated can have titles with
+// runs of whitespace or a trailing "(2024)", which also gives them identity
+// keys that new scrapes never match. This migration cleans those titles the
+// way validation.Normalize does and keeps the originals so it can be rolled
+// back. Rows whose cleaned title would collide with another item are left
+// alone; they are real duplicates.
+func init() {
+	addDataMigration("20251015000001_normalize_titles.go", upNormalizeTitles, downNormalizeTitles)
+}
+
+// titleBatchSize is the number of rows read at a time
+const titleBatchSize = 500
+
+// The migration keeps a frozen copy of titles.Normalize as it was when the
+// migration was written, so applying it later cleans titles the same way
+// whatever the validation does by then. Don't change it to follow
+// titles.Normalize; TestNormalizeTitleMatchesTitles reports when they drift.
+var (
+	titleSpacePattern = regexp.MustCompile(`\s+`)
+	titleYearSuffix   = regexp.MustCompile(`\s*[(\[](\d{4})[)\]]$`)
+)
+
+// storedTitle is the part of a content row the migration changes
+type storedTitle struct {
+	id          int64
+	title       string
+	year        sql.NullInt64
+	contentType string
+}
+
+// normalizeTitle collapses whitespace, moves a trailing year into year when
+// it is unset and strips wrapping quotes or markdown
+func normalizeTitle(title string, year sql.NullInt64) (string, sql.NullInt64) {
+	title = strings.TrimSpace(titleSpacePattern.ReplaceAllString(title, " "))
+	if m := titleYearSuffix.FindStringSubmatch(title); m != nil {
+		title = strings.TrimSuffix(title, m[0])
+		if !year.Valid {
+			value, _ := strconv.ParseInt(m[1], 10, 64)
+			year = sql.NullInt64{Int64: value, Valid: true}
+		}
+	}
+	return strings.TrimSpace(strings.Trim(title, "\"'*_`")), year
+}
+
+func upNormalizeTitles(ctx context.Context, tx *sql.Tx, progress *migrationProgress) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE content_title_originals (
+		content_id INTEGER PRIMARY KEY,
+		title TEXT NOT NULL,
+		year INTEGER
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create content_title_originals: %v", err)
+	}
+
+	var total int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM content`).Scan(&total); err != nil {
+		return fmt.Errorf("failed to count content: %v", err)
+	}
+	progress.expect(total)
+
+	var lastID int64
+	for {
+		batch, err := readTitles(ctx, tx, lastID)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		lastID = batch[len(batch)-1].id
+
+		for _, row := range batch {
+			changed, err := normalizeStoredTitle(ctx, tx, row)
+			if err != nil {
+				return err
+			}
+			progress.row(changed)
+		}
+	}
+}
+
+// readTitles reads the next batch of rows after lastID
+func readTitles(ctx context.Context, tx *sql.Tx, lastID int64) ([]storedTitle, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, title, year, type FROM content WHERE id > ? ORDER BY id LIMIT ?`, lastID, titleBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %v", err)
+	}
+	defer rows.Close()
+
+	var batch []storedTitle
+	for rows.Next() {
+		var row storedTitle
+		if err := rows.Scan(&row.id, &row.title, &row.year, &row.contentType); err != nil {
+			return nil, fmt.Errorf("failed to scan content: %v", err)
+		}
+		batch = append(batch, row)
+	}
+	return batch, rows.Err()
+}
+
+// normalizeStoredTitle cleans one row and reports whether it changed
+func normalizeStoredTitle(ctx context.Context, tx *sql.Tx, row storedTitle) (bool, error) {
+	title, year := normalizeTitle(row.title, row.year)
+	if title == "" || (title == row.title && year == row.year) {
+		return false, nil
+	}
+
+	var collisions int
+	err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM content WHERE identity_key = `+identityKey+` AND id != ?`,
+		title, row.contentType, row.id).Scan(&collisions)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for duplicates of %d: %v", row.id, err)
+	}
+	if collisions > 0 {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO content_title_originals (content_id, title, year) VALUES (?, ?, ?)`,
+		row.id, row.title, row.year); err != nil {
+		return false, fmt.Errorf("failed to keep the title of %d: %v", row.id, err)
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE content SET title = ?, year = ?, identity_key = `+identityKey+` WHERE id = ?`,
+		title, year, title, row.contentType, row.id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update %d: %v", row.id, err)
+	}
+	return true, nil
+}
+
+func downNormalizeTitles(ctx context.Context, tx *sql.Tx, progress *migrationProgress) error {
+	var total int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM content_title_originals`).Scan(&total); err != nil {
+		return fmt.Errorf("failed to count original titles: %v", err)
+	}
+	progress.expect(total)
+
+	// Items deleted since the migration have nothing to restore
+	res, err := tx.ExecContext(ctx, `UPDATE content SET
+		title = o.title,
+		year = o.year,
+		identity_key = lower(trim(o.title)) || '|' || lower(trim(content.type))
+	FROM content_title_originals o
+	WHERE o.content_id = content.id`)
+	if err != nil {
+		return fmt.Errorf("failed to restore titles: %v", err)
+	}
+	restored, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < total; i++ {
+		progress.row(int64(i) < restored)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP TABLE content_title_originals`); err != nil {
+		return fmt.Errorf("failed to drop content_title_originals: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Watchlist entry kinds
+const (
+	// WatchTitle matches items with the same title match key as the pattern,
+	// so "The Boys" also matches "Boys" and "THE BOYS (2019)"
+	WatchTitle = "title"
+	// WatchKeyword matches items whose title, category or extra info contain
+	// every word of the pattern, e.g. "marvel" for any Marvel release
+	WatchKeyword = "keyword"
+)
+
+// WatchKinds lists the supported watchlist entry kinds
+var WatchKinds = []string{WatchTitle, WatchKeyword}
+
+// WatchlistEntry is a title or keyword pattern to alert on. An empty Type or
+// Category matches any.
+type WatchlistEntry struct {
+	ID            int64      `json:"id"`
+	Kind          string     `json:"kind"`
+	Pattern       string     `json:"pattern"`
+	Type          string     `json:"type,omitempty"`
+	Category      string     `json:"category,omitempty"`
+	Matches       int        `json:"matches"`
+	LastMatchedAt *time.Time `json:"last_matched_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Match reports whether a content item is one the entry watches
+func (w WatchlistEntry) Match(content Content) bool {
+	if w.Type != "" && w.Type != content.Type {
+		return false
+	}
+	if w.Category != "" && !strings.EqualFold(w.Category, content.Category) {
+		return false
+	}
+
+	pattern := titleMatchKey(w.Pattern)
+	if pattern == "" {
+		return false
+	}
+	if w.Kind == WatchTitle {
+		return pattern == titleMatchKey(content.Title)
+	}
+
+	words := strings.Fields(titleMatchKey(content.Title + " " + content.Category + " " + content.ExtraInfo))
+	for _, want := range strings.Fields(pattern) {
+		if !containsWord(words, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsWord reports whether one of words is want or, unless want is a
+// number, starts with it, so "marvel" finds "Marvel's" but "2" not "2024"
+func containsWord(words []string, want string) bool {
+	number := strings.IndexFunc(want, func(r rune) bool { return !unicode.IsDigit(r) }) == -1
+	for _, word := range words {
+		if word == want || (!number && strings.HasPrefix(word, want)) {
+			return true
+		}
+	}
+	return false
+}
+
+const watchlistColumns = `id, kind, pattern, content_type, category, matches, last_matched_at, created_at`
+
+// ListWatchlist returns every watchlist entry ordered by id
+func (s *sqlStore) ListWatchlist() ([]WatchlistEntry, error) {
+	rows, err := s.db.Query(`SELECT ` + watchlistColumns + ` FROM watchlist ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []WatchlistEntry
+	for rows.Next() {
+		var entry WatchlistEntry
+		var lastMatched sql.NullTime
+		if err := rows.Scan(&entry.ID, &entry.Kind, &entry.Pattern, &entry.Type, &entry.Category,
+			&entry.Matches, &lastMatched, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist entry: %v", err)
+		}
+		if lastMatched.Valid {
+			entry.LastMatchedAt = &lastMatched.Time
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// AddWatchlistEntry adds a title or keyword to the watchlist and returns its id
+func (s *sqlStore) AddWatchlistEntry(entry WatchlistEntry) (int64, error) {
+	if err := normalizeWatchlistEntry(&entry); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := s.db.QueryRow(`INSERT INTO watchlist (kind, pattern, content_type, category)
+		VALUES (?, ?, ?, ?) RETURNING id`,
+		entry.Kind, entry.Pattern, entry.Type, entry.Category).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add watchlist entry: %v", err)
+	}
+
+	return id, nil
+}
+
+// DeleteWatchlistEntry removes an entry from the watchlist
+func (s *sqlStore) DeleteWatchlistEntry(id int64) error {
+	res, err := s.db.Exec(`DELETE FROM watchlist WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete watchlist entry: %v", err)
+	}
+
+	return requireRow(res, "watchlist entry", id)
+}
+
+// RecordWatchlistMatch counts an item a scrape found for an entry
+func (s *sqlStore) RecordWatchlistMatch(id int64) error {
+	_, err := s.db.Exec(`UPDATE watchlist SET matches = matches + 1, last_matched_at = ? WHERE id = ?`,
+		time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to record watchlist match: %v", err)
+	}
+	return nil
+}
+
+// normalizeWatchlistEntry trims the pattern and checks the kind and type.
+// The kind defaults to title.
+func normalizeWatchlistEntry(entry *WatchlistEntry) error {
+	entry.Pattern = strings.TrimSpace(entry.Pattern)
+	entry.Category = strings.TrimSpace(entry.Category)
+	if titleMatchKey(entry.Pattern) == "" {
+		return fmt.Errorf("watchlist pattern %q has no words", entry.Pattern)
+	}
+
+	if entry.Type != "" && entry.Type != "movie" && entry.Type != "series" {
+		return fmt.Errorf("unknown content type %q", entry.Type)
+	}
+
+	if entry.Kind == "" {
+		entry.Kind = WatchTitle
+	}
+	for _, kind := range WatchKinds {
+		if entry.Kind == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown watchlist kind %q", entry.Kind)
+}
@@ -0,0 +1,35 @@
+package storage
+
+import "testing"
+
+func TestWatchlistEntryMatch(t *testing.T) {
+	boys := WatchlistEntry{Kind: WatchTitle, Pattern: "The Boys", Type: "series"}
+	marvel := WatchlistEntry{Kind: WatchKeyword, Pattern: "Marvel", Type: "movie"}
+	part2 := WatchlistEntry{Kind: WatchKeyword, Pattern: "dune part two"}
+
+	tests := []struct {
+		entry   WatchlistEntry
+		content Content
+		want    bool
+	}{
+		{boys, Content{Title: "THE BOYS (2019)", Type: "series"}, true},
+		{boys, Content{Title: "Boys", Type: "series"}, true},
+		{boys, Content{Title: "The Boys Presents: Diabolical", Type: "series"}, false},
+		{boys, Content{Title: "The Boys", Type: "movie"}, false},
+		{marvel, Content{Title: "Thunderbolts*", Category: "Marvel", Type: "movie"}, true},
+		{marvel, Content{Title: "Marvel's The Avengers", Type: "movie"}, true},
+		{marvel, Content{Title: "Captain America", ExtraInfo: "Marvel Studios", Type: "movie"}, true},
+		{marvel, Content{Title: "Daredevil", Category: "Marvel", Type: "series"}, false},
+		{marvel, Content{Title: "Superman", Category: "Hollywood", Type: "movie"}, false},
+		{part2, Content{Title: "Dune: Part 2", Type: "movie"}, true},
+		{part2, Content{Title: "Dune", ExtraInfo: "Part One 2021", Type: "movie"}, false},
+		{WatchlistEntry{Kind: WatchKeyword, Pattern: "2"}, Content{Title: "Thunderbolts", ExtraInfo: "2025", Type: "movie"}, false},
+		{WatchlistEntry{Kind: WatchKeyword, Pattern: "korean", Category: "korean"}, Content{Title: "Squid Game", Category: "Korean", Type: "series"}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.entry.Match(tt.content); got != tt.want {
+			t.Errorf("%s %q matching %+v = %t, want %t", tt.entry.Kind, tt.entry.Pattern, tt.content, got, tt.want)
+		}
+	}
+}
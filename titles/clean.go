@@ -0,0 +1,37 @@
+package titles
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	spacePattern = regexp.MustCompile(`\s+`)
+	yearSuffix   = regexp.MustCompile(`\s*[(\[](\d{4})[)\]]$`)
+)
+
+// CleanText trims and collapses runs of whitespace
+func CleanText(s string) string {
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+// StripYear removes a trailing "(2024)" or "[2024]"
+func StripYear(title string) string {
+	return yearSuffix.ReplaceAllString(title, "")
+}
+
+// Normalize collapses whitespace, moves a trailing "(2024)" into the year
+// when it is unset and strips wrapping quotes or markdown. The year passed in
+// is returned as it is when it is set.
+func Normalize(title string, year *int) (string, *int) {
+	title = CleanText(title)
+	if m := yearSuffix.FindStringSubmatch(title); m != nil {
+		title = strings.TrimSuffix(title, m[0])
+		if year == nil {
+			value, _ := strconv.Atoi(m[1])
+			year = &value
+		}
+	}
+	return strings.TrimSpace(strings.Trim(title, "\"'*_`")), year
+}
@@ -0,0 +1,31 @@
+package titles
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	year := func(y int) *int { return &y }
+	tests := []struct {
+		title    string
+		year     *int
+		expected string
+		wantYear *int
+	}{
+		{"  The   Boys ", nil, "The Boys", nil},
+		{"Dune: Part Two (2024)", nil, "Dune: Part Two", year(2024)},
+		{"Dune: Part Two [2024]", year(2023), "Dune: Part Two", year(2023)},
+		{"**Civil War**", nil, "Civil War", nil},
+		{`"Oppenheimer" (2023)`, nil, "Oppenheimer", year(2023)},
+		{"1917", nil, "1917", nil},
+		{"Blade Runner 2049", nil, "Blade Runner 2049", nil},
+	}
+
+	for _, tt := range tests {
+		title, got := Normalize(tt.title, tt.year)
+		if title != tt.expected {
+			t.Errorf("Normalize(%q): expected title %q, got %q", tt.title, tt.expected, title)
+		}
+		if (got == nil) != (tt.wantYear == nil) || (got != nil && *got != *tt.wantYear) {
+			t.Errorf("Normalize(%q): expected year %v, got %v", tt.title, tt.wantYear, got)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"cine-pulse/storage"
+	"cine-pulse/titles"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Canonical content types
+const (
+	TypeMovie  = "movie"
+	TypeSeries = "series"
+)
+
+// Categories is the category taxonomy every stored item must belong to
+var Categories = []string{
+	"Hollywood",
+	"Bollywood",
+	"Nollywood",
+	"Korean",
+	"Anime",
+	"Foreign",
+	"TV Series",
+}
+
+// typeSynonyms maps lower-cased type labels models produce to canonical types
+var typeSynonyms = map[string]string{
+	"movie":          TypeMovie,
+	"movies":         TypeMovie,
+	"film":           TypeMovie,
+	"feature":        TypeMovie,
+	"feature film":   TypeMovie,
+	"series":         TypeSeries,
+	"tv series":      TypeSeries,
+	"tv show":        TypeSeries,
+	"tv shows":       TypeSeries,
+	"show":           TypeSeries,
+	"tv":             TypeSeries,
+	"web series":     TypeSeries,
+	"miniseries":     TypeSeries,
+	"mini-series":    TypeSeries,
+	"limited series": TypeSeries,
+}
+
+// categorySynonyms maps lower-cased category labels to the taxonomy
+var categorySynonyms = map[string]string{
+	"hollywood movie": "Hollywood",
+	"american":        "Hollywood",
+	"indian":          "Bollywood",
+	"nigerian":        "Nollywood",
+	"k-drama":         "Korean",
+	"kdrama":          "Korean",
+	"korean drama":    "Korean",
+	"animation":       "Anime",
+	"japanese anime":  "Anime",
+	"international":   "Foreign",
+	"world cinema":    "Foreign",
+	"foreign movie":   "Foreign",
+	"tv":              "TV Series",
+	"tv show":         "TV Series",
+	"tv shows":        "TV Series",
+	"series":          "TV Series",
+}
+
+// firstFilmYear is the year of the earliest surviving motion picture
+const firstFilmYear = 1888
+
+// Rejection records an item that failed validation and why
+type Rejection struct {
+	Content storage.Content
+	Reason  string
+}
+
+// Validator checks and normalizes extracted content before it is stored
+type Validator struct {
+	categories map[string]string
+	minYear    int
+	maxYear    int
+}
+
+// NewValidator creates a validator using the standard taxonomy.
+// Years up to two years ahead are accepted to allow for announced releases.
+func NewValidator() *Validator {
+	categories := make(map[string]string)
+	for _, category := range Categories {
+		categories[strings.ToLower(category)] = category
+	}
+	for synonym, category := range categorySynonyms {
+		categories[synonym] = category
+	}
+
+	return &Validator{
+		categories: categories,
+		minYear:    firstFilmYear,
+		maxYear:    time.Now().Year() + 2,
+	}
+}
+
+// Validate normalizes every item and splits them into accepted and rejected items
+func (v *Validator) Validate(items []storage.Content) ([]storage.Content, []Rejection) {
+	var valid []storage.Content
+	var rejected []Rejection
+
+	for _, item := range items {
+		normalized, err := v.Normalize(item)
+		if err != nil {
+			rejected = append(rejected, Rejection{Content: item, Reason: err.Error()})
+			continue
+		}
+		valid = append(valid, normalized)
+	}
+
+	return valid, rejected
+}
+
+// Normalize returns a canonical copy of the item, or an error explaining why it is invalid
+func (v *Validator) Normalize(item storage.Content) (storage.Content, error) {
+	content := item
+
+	// Title: collapse whitespace, move a trailing "(2024)" into the year field
+	// and strip wrapping quotes or markdown
+	content.Title, content.Year = titles.Normalize(content.Title, content.Year)
+
+	if content.Title == "" {
+		return item, fmt.Errorf("missing title")
+	}
+
+	// Type: map synonyms such as "TV Series" or "film"
+	contentType, ok := typeSynonyms[strings.ToLower(titles.CleanText(content.Type))]
+	if !ok {
+		if content.Type == "" {
+			return item, fmt.Errorf("missing type")
+		}
+		return item, fmt.Errorf("unknown type %q", content.Type)
+	}
+	content.Type = contentType
+
+	// Category: must be part of the taxonomy
+	category, ok := v.categories[strings.ToLower(titles.CleanText(content.Category))]
+	if !ok {
+		if content.Category == "" {
+			return item, fmt.Errorf("missing category")
+		}
+		return item, fmt.Errorf("unknown category %q", content.Category)
+	}
+	content.Category = category
+
+	// Year: must fall within the range of plausible release years
+	if content.Year != nil {
+		if *content.Year < v.minYear || *content.Year > v.maxYear {
+			return item, fmt.Errorf("year %d outside %d-%d", *content.Year, v.minYear, v.maxYear)
+		}
+		year := *content.Year
+		content.Year = &year
+	}
+
+	// Rating: clamp to the 0-10 scale, dropping values that are not numbers
+	if content.Rating != nil {
+		rating := *content.Rating
+		if math.IsNaN(rating) {
+			content.Rating = nil
+		} else {
+			rating = math.Max(0, math.Min(10, rating))
+			content.Rating = &rating
+		}
+	}
+
+	content.ExtraInfo = titles.CleanText(content.ExtraInfo)
+
+	return content, nil
+}
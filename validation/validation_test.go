@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"cine-pulse/storage"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	v := NewValidator()
+
+	content, err := v.Normalize(storage.Content{
+		Title:     "  \"Dune:   Part Two\" (2024) ",
+		Category:  "american",
+		ExtraInfo: " Download  Hollywood Movie ",
+		Type:      "Film",
+		Rating:    &[]float64{12}[0],
+	})
+	if err != nil {
+		t.Fatalf("Failed to normalize content: %v", err)
+	}
+
+	if content.Title != "Dune: Part Two" {
+		t.Errorf("Expected canonical title, got %q", content.Title)
+	}
+	if content.Year == nil || *content.Year != 2024 {
+		t.Errorf("Expected year 2024 taken from title, got %v", content.Year)
+	}
+	if content.Category != "Hollywood" {
+		t.Errorf("Expected category Hollywood, got %q", content.Category)
+	}
+	if content.Type != TypeMovie {
+		t.Errorf("Expected type movie, got %q", content.Type)
+	}
+	if content.ExtraInfo != "Download Hollywood Movie" {
+		t.Errorf("Expected collapsed extra info, got %q", content.ExtraInfo)
+	}
+	if content.Rating == nil || *content.Rating != 10 {
+		t.Errorf("Expected rating clamped to 10, got %v", content.Rating)
+	}
+
+	series, err := v.Normalize(storage.Content{Title: "The Boys", Category: "TV Series", Type: "TV Series"})
+	if err != nil {
+		t.Fatalf("Failed to normalize series: %v", err)
+	}
+	if series.Type != TypeSeries {
+		t.Errorf("Expected \"TV Series\" to map to series, got %q", series.Type)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v := NewValidator()
+
+	items := []storage.Content{
+		{Title: "Civil War", Year: &[]int{2024}[0], Category: "Hollywood", Type: "movie"},
+		{Title: "", Category: "Hollywood", Type: "movie"},
+		{Title: "Planet Earth", Category: "Hollywood", Type: "documentary"},
+		{Title: "Mystery", Category: "Martian", Type: "movie"},
+		{Title: "Time Traveller", Year: &[]int{3024}[0], Category: "Hollywood", Type: "movie"},
+		{Title: "Silent Era", Year: &[]int{1700}[0], Category: "Foreign", Type: "movie"},
+	}
+
+	valid, rejected := v.Validate(items)
+
+	if len(valid) != 1 || valid[0].Title != "Civil War" {
+		t.Fatalf("Expected only Civil War to pass, got %+v", valid)
+	}
+
+	expectedReasons := []string{"missing title", "unknown type", "unknown category", "year 3024", "year 1700"}
+	if len(rejected) != len(expectedReasons) {
+		t.Fatalf("Expected %d rejections, got %d", len(expectedReasons), len(rejected))
+	}
+	for i, reason := range expectedReasons {
+		if !strings.Contains(rejected[i].Reason, reason) {
+			t.Errorf("Rejection %d: expected reason containing %q, got %q", i, reason, rejected[i].Reason)
+		}
+	}
+
+	// Rejections keep the original item for reporting
+	if rejected[2].Content.Category != "Martian" {
+		t.Errorf("Expected rejected item to keep its original values, got %+v", rejected[2].Content)
+	}
+}